@@ -0,0 +1,23 @@
+package accounts
+
+import (
+	"testing"
+
+	"github.com/vmorsell/avanza-sdk-go/internal/drifttest"
+)
+
+// driftFixtures maps each golden capture in testdata/ to the type it must
+// decode into. Refresh them with `go run ./scripts/capture-fixtures`, which
+// logs in with BankID, records the authenticated endpoints and writes
+// redacted copies here, with account numbers replaced.
+var driftFixtures = map[string]func() any{
+	"overview.json":          func() any { return &AccountOverview{} },
+	"trading_accounts.json":  func() any { return &[]TradingAccount{} },
+	"positions.json":         func() any { return &AccountPositions{} },
+	"transactions.json":      func() any { return &TransactionsResponse{} },
+	"aggregated_values.json": func() any { return &AggregatedValuesResponse{} },
+}
+
+func TestSchemaDrift(t *testing.T) {
+	drifttest.Run(t, "testdata", driftFixtures)
+}
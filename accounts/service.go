@@ -3,7 +3,6 @@ package accounts
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -39,7 +38,7 @@ func (s *Service) GetOverview(ctx context.Context) (*AccountOverview, error) {
 	}
 
 	var overview AccountOverview
-	if err := json.NewDecoder(resp.Body).Decode(&overview); err != nil {
+	if err := s.client.DecodeJSON(resp, &overview); err != nil {
 		return nil, fmt.Errorf("get account overview: failed to decode response: %w", err)
 	}
 
@@ -59,7 +58,7 @@ func (s *Service) GetTradingAccounts(ctx context.Context) ([]TradingAccount, err
 	}
 
 	var accounts []TradingAccount
-	if err := json.NewDecoder(resp.Body).Decode(&accounts); err != nil {
+	if err := s.client.DecodeJSON(resp, &accounts); err != nil {
 		return nil, fmt.Errorf("get trading accounts: failed to decode response: %w", err)
 	}
 
@@ -85,7 +84,7 @@ func (s *Service) GetPositions(ctx context.Context, urlParameterID string) (*Acc
 	}
 
 	var positions AccountPositions
-	if err := json.NewDecoder(resp.Body).Decode(&positions); err != nil {
+	if err := s.client.DecodeJSON(resp, &positions); err != nil {
 		return nil, fmt.Errorf("get account positions: failed to decode response: %w", err)
 	}
 
@@ -143,7 +142,7 @@ func (s *Service) GetTransactions(ctx context.Context, req *TransactionsRequest)
 	}
 
 	var transactions TransactionsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&transactions); err != nil {
+	if err := s.client.DecodeJSON(resp, &transactions); err != nil {
 		return nil, fmt.Errorf("get transactions: failed to decode response: %w", err)
 	}
 
@@ -182,7 +181,7 @@ func (s *Service) GetAggregatedValues(ctx context.Context, req *AggregatedValues
 	}
 
 	var values AggregatedValuesResponse
-	if err := json.NewDecoder(resp.Body).Decode(&values); err != nil {
+	if err := s.client.DecodeJSON(resp, &values); err != nil {
 		return nil, fmt.Errorf("get aggregated values: failed to decode response: %w", err)
 	}
 
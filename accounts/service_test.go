@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 )
 
 func newTestClient(baseURL string) *client.Client {
@@ -32,7 +33,7 @@ func TestGetOverview_Success(t *testing.T) {
 					ID:   "cat-1",
 					Name: "Sparande",
 					TotalValue: Money{
-						Value:            100000.50,
+						Value:            decimal.MustParse("100000.50"),
 						Unit:             "SEK",
 						UnitType:         "CURRENCY",
 						DecimalPrecision: 2,
@@ -124,7 +125,7 @@ func TestGetTradingAccounts_Success(t *testing.T) {
 				AccountID:            "acc-1",
 				AccountTypeName:      "Investeringssparkonto",
 				AccountType:          "ISK",
-				AvailableForPurchase: 50000.00,
+				AvailableForPurchase: decimal.MustParse("50000.00"),
 				IsTradable:           true,
 				URLParameterID:       "abc123",
 			},
@@ -133,7 +134,7 @@ func TestGetTradingAccounts_Success(t *testing.T) {
 				AccountID:            "acc-2",
 				AccountTypeName:      "Kapitalförsäkring",
 				AccountType:          "KF",
-				AvailableForPurchase: 25000.00,
+				AvailableForPurchase: decimal.MustParse("25000.00"),
 				IsTradable:           true,
 				URLParameterID:       "def456",
 			},
@@ -219,7 +220,7 @@ func TestGetPositions_Success(t *testing.T) {
 						ISIN:     "SE0000115446",
 					},
 					Value: Money{
-						Value:            5000.00,
+						Value:            decimal.MustParse("5000.00"),
 						Unit:             "SEK",
 						DecimalPrecision: 2,
 					},
@@ -227,7 +228,7 @@ func TestGetPositions_Success(t *testing.T) {
 			},
 			CashPositions: []CashPosition{
 				{
-					TotalBalance: Money{Value: 10000.00, Unit: "SEK"},
+					TotalBalance: Money{Value: decimal.MustParse("10000.00"), Unit: "SEK"},
 					ID:           "cash-1",
 				},
 			},
@@ -252,7 +253,7 @@ func TestGetPositions_Success(t *testing.T) {
 	if len(positions.CashPositions) != 1 {
 		t.Fatalf("expected 1 cash position, got %d", len(positions.CashPositions))
 	}
-	if got, want := positions.CashPositions[0].TotalBalance.Value, 10000.00; got != want {
+	if got, want := positions.CashPositions[0].TotalBalance.Value, decimal.MustParse("10000.00"); !got.Equal(want) {
 		t.Errorf("cash balance = %v, want %v", got, want)
 	}
 }
@@ -362,7 +363,7 @@ func TestGetTransactions_Success(t *testing.T) {
 					BackofficeType:     "SELL",
 					BackofficeTypeText: "Sälj",
 					Amount: &Money{
-						Value:            1234.56,
+						Value:            decimal.MustParse("1234.56"),
 						Unit:             "SEK",
 						UnitType:         "MONETARY",
 						DecimalPrecision: 2,
@@ -399,7 +400,7 @@ func TestGetTransactions_Success(t *testing.T) {
 	if resp.Transactions[0].Amount == nil {
 		t.Fatal("expected amount to be set")
 	}
-	if got, want := resp.Transactions[0].Amount.Value, 1234.56; got != want {
+	if got, want := resp.Transactions[0].Amount.Value, decimal.MustParse("1234.56"); !got.Equal(want) {
 		t.Errorf("amount = %v, want %v", got, want)
 	}
 	if got, want := resp.FirstTransactionDate, "2020-01-01"; got != want {
@@ -778,7 +779,7 @@ func TestGetAggregatedValues_Success(t *testing.T) {
 			{
 				Date: "2026-01-25",
 				Value: Money{
-					Value:            2963043.66,
+					Value:            decimal.MustParse("2963043.66"),
 					Unit:             "SEK",
 					UnitType:         "MONETARY",
 					DecimalPrecision: 2,
@@ -787,7 +788,7 @@ func TestGetAggregatedValues_Success(t *testing.T) {
 			{
 				Date: "2026-01-28",
 				Value: Money{
-					Value:            2984827.19,
+					Value:            decimal.MustParse("2984827.19"),
 					Unit:             "SEK",
 					UnitType:         "MONETARY",
 					DecimalPrecision: 2,
@@ -814,7 +815,7 @@ func TestGetAggregatedValues_Success(t *testing.T) {
 	if got, want := resp[0].Date, "2026-01-25"; got != want {
 		t.Errorf("date = %q, want %q", got, want)
 	}
-	if got, want := resp[0].Value.Value, 2963043.66; got != want {
+	if got, want := resp[0].Value.Value, decimal.MustParse("2963043.66"); !got.Equal(want) {
 		t.Errorf("value = %v, want %v", got, want)
 	}
 	if got, want := resp[1].Date, "2026-01-28"; got != want {
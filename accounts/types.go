@@ -1,6 +1,8 @@
 // Package accounts provides account management functionality for the Avanza API.
 package accounts
 
+import "github.com/vmorsell/avanza-sdk-go/decimal"
+
 // AccountOverview contains all accounts, categorized and with loans.
 type AccountOverview struct {
 	Categories []Category `json:"categories"`
@@ -60,10 +62,10 @@ type AccountSettings struct {
 // Unit is typically a currency code (e.g., "SEK", "USD").
 // DecimalPrecision indicates the number of decimal places for display.
 type Money struct {
-	Value            float64 `json:"value"`
-	Unit             string  `json:"unit"`
-	UnitType         string  `json:"unitType"`
-	DecimalPrecision int     `json:"decimalPrecision"`
+	Value            decimal.Decimal `json:"value"`
+	Unit             string          `json:"unit"`
+	UnitType         string          `json:"unitType"`
+	DecimalPrecision int             `json:"decimalPrecision"`
 }
 
 // Profit contains both absolute and relative profit values.
@@ -99,9 +101,9 @@ type TradingAccount struct {
 	AccountID                         string            `json:"accountId"`
 	AccountTypeName                   string            `json:"accountTypeName"`
 	AccountType                       string            `json:"accountType"`
-	AvailableForPurchase              float64           `json:"availableForPurchase"`
-	AvailableForPurchaseWithoutCredit float64           `json:"availableForPurchaseWithoutCredit"`
-	AvailableCredit                   float64           `json:"availableCredit"`
+	AvailableForPurchase              decimal.Decimal   `json:"availableForPurchase"`
+	AvailableForPurchaseWithoutCredit decimal.Decimal   `json:"availableForPurchaseWithoutCredit"`
+	AvailableCredit                   decimal.Decimal   `json:"availableCredit"`
 	HasCredit                         bool              `json:"hasCredit"`
 	IsTradable                        bool              `json:"isTradable"`
 	IsShortSellable                   bool              `json:"isShortSellable"`
@@ -115,9 +117,9 @@ type TradingAccount struct {
 
 // CurrencyBalance contains the balance for a specific currency.
 type CurrencyBalance struct {
-	Currency    string  `json:"currency"`
-	CountryCode string  `json:"countryCode"`
-	Balance     float64 `json:"balance"`
+	Currency    string          `json:"currency"`
+	CountryCode string          `json:"countryCode"`
+	Balance     decimal.Decimal `json:"balance"`
 }
 
 // AccountPosition represents a holding (stock, fund, etc.) in an account.
@@ -3,28 +3,106 @@ package auth
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"os"
+	"net/url"
+	"sync"
 	"time"
 
-	"github.com/mdp/qrterminal/v3"
 	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/internal/redact"
 )
 
 // AuthService handles BankID authentication.
 type AuthService struct {
-	client *client.Client
+	client    *client.Client
+	store     SessionStore
+	presenter QRPresenter
+
+	maxBankIDRestarts int
+	onBankIDProgress  func(BankIDProgress)
+	logger            *slog.Logger
+
+	mu     sync.Mutex
+	logins []Login           // logins from the last completed BankID transaction
+	tx     bankIDTransaction // the BankID transaction in progress
+}
+
+// Option is a functional option for configuring the AuthService.
+type Option func(*AuthService)
+
+// WithSessionStore persists the session after EstablishSession so that
+// RestoreSession can resume it after a restart without a new BankID scan.
+func WithSessionStore(store SessionStore) Option {
+	return func(a *AuthService) {
+		a.store = store
+	}
+}
+
+// WithQRPresenter sets how BankID QR codes are shown. Defaults to a
+// TerminalQRPresenter writing to stdout.
+func WithQRPresenter(presenter QRPresenter) Option {
+	return func(a *AuthService) {
+		a.presenter = presenter
+	}
+}
+
+// WithMaxBankIDRestarts sets how many times polling starts a new BankID
+// transaction after the current one expires. Defaults to
+// DefaultMaxBankIDRestarts; 0 turns automatic restarts off.
+func WithMaxBankIDRestarts(n int) Option {
+	return func(a *AuthService) {
+		a.maxBankIDRestarts = n
+	}
+}
+
+// WithBankIDProgress calls fn from PollBankID and PollBankIDWithQRUpdates each
+// time the BankID state or hint code changes. fn runs on the polling goroutine
+// and should return quickly.
+func WithBankIDProgress(fn func(BankIDProgress)) Option {
+	return func(a *AuthService) {
+		a.onBankIDProgress = fn
+	}
+}
+
+// WithLogger logs BankID state changes and session lifecycle events to
+// logger. QR and autostart tokens and personal identity numbers are never logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(a *AuthService) {
+		a.logger = logger
+	}
 }
 
 // NewAuthService creates a new authentication service.
-func NewAuthService(client *client.Client) *AuthService {
-	return &AuthService{
-		client: client,
+func NewAuthService(client *client.Client, opts ...Option) *AuthService {
+	a := &AuthService{
+		client:            client,
+		presenter:         &TerminalQRPresenter{},
+		maxBankIDRestarts: DefaultMaxBankIDRestarts,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// log writes to the configured logger, if any.
+func (a *AuthService) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if a.logger != nil {
+		a.logger.Log(ctx, level, msg, args...)
 	}
 }
 
+// BankID start methods accepted by the authentication endpoint.
+const (
+	BankIDMethodQR         = "QR_START"    // Scan a QR code with BankID on another device
+	BankIDMethodSameDevice = "SAME_DEVICE" // Open BankID on this device with an autostart token
+)
+
 // BankIDStartRequest initiates a BankID authentication session.
 type BankIDStartRequest struct {
 	Method       string `json:"method"`
@@ -32,26 +110,31 @@ type BankIDStartRequest struct {
 }
 
 // BankIDStartResponse contains the QR token and transaction details.
+// AutoStartToken is set for same-device logins, and AutoStartURL is the
+// bankid:/// link built from it by StartBankIDSameDevice.
 type BankIDStartResponse struct {
-	TransactionID string `json:"transactionId"`
-	Expires       string `json:"expires"`
-	QRToken       string `json:"qrToken"`
+	TransactionID  string `json:"transactionId"`
+	Expires        string `json:"expires"`
+	QRToken        string `json:"qrToken"`
+	AutoStartToken string `json:"autostartToken"`
+	AutoStartURL   string `json:"-"`
 }
 
 // BankIDCollectResponse contains authentication status.
-// State is "OUTSTANDING_TRANSACTION" while pending, "COMPLETE" on success, or "FAILED" on error.
-// Logins is populated when State is "COMPLETE".
+// State is BankIDStateComplete on success and BankIDStateFailed on error; any
+// other state means the transaction is still pending. Logins is populated when
+// State is BankIDStateComplete.
 type BankIDCollectResponse struct {
-	Name                       string  `json:"name"`
-	TransactionID              string  `json:"transactionId"`
-	State                      string  `json:"state"`
-	HintCode                   string  `json:"hintCode"`
-	Hint                       string  `json:"hint"`
-	RFA                        string  `json:"rfa"`
-	IdentificationNumber       string  `json:"identificationNumber"`
-	Logins                     []Login `json:"logins"`
-	RecommendedTargetCustomers []any   `json:"recommendedTargetCustomers"`
-	Poa                        Poa     `json:"poa"`
+	Name                       string      `json:"name"`
+	TransactionID              string      `json:"transactionId"`
+	State                      BankIDState `json:"state"`
+	HintCode                   HintCode    `json:"hintCode"`
+	Hint                       string      `json:"hint"`
+	RFA                        string      `json:"rfa"`
+	IdentificationNumber       string      `json:"identificationNumber"`
+	Logins                     []Login     `json:"logins"`
+	RecommendedTargetCustomers []any       `json:"recommendedTargetCustomers"`
+	Poa                        Poa         `json:"poa"`
 }
 
 // Poa contains power of attorney information.
@@ -83,11 +166,65 @@ func (a *AuthService) StartBankID(ctx context.Context) (*BankIDStartResponse, er
 	}
 	_ = initResp.Body.Close()
 
-	reqBody := BankIDStartRequest{
-		Method:       "QR_START",
+	return a.startBankID(ctx, BankIDStartRequest{
+		Method:       BankIDMethodQR,
 		ReturnScheme: "NULL",
+	})
+}
+
+// StartBankIDSameDevice initiates a BankID login on the same device: instead
+// of a QR code, the response carries an AutoStartToken and an AutoStartURL that
+// opens the BankID app directly. After BankID finishes, the app opens redirect;
+// pass "" to stay in BankID. Poll with PollBankID as in the QR flow.
+//
+//	start, err := client.Auth.StartBankIDSameDevice(ctx, "myapp://login-done")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	openURL(start.AutoStartURL)
+//	collectResp, err := client.Auth.PollBankID(ctx)
+func (a *AuthService) StartBankIDSameDevice(ctx context.Context, redirect string) (*BankIDStartResponse, error) {
+	// Get initial cookies (AZAPERSISTENCE, etc.)
+	initResp, err := a.client.Get(ctx, "/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get initial cookies: %w", err)
 	}
+	_ = initResp.Body.Close()
 
+	response, err := a.startBankID(ctx, BankIDStartRequest{
+		Method:       BankIDMethodSameDevice,
+		ReturnScheme: "NULL",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.AutoStartToken == "" {
+		return nil, fmt.Errorf("no autostart token in response")
+	}
+
+	response.AutoStartURL = AutoStartURL(response.AutoStartToken, redirect)
+
+	a.mu.Lock()
+	a.tx.redirect = redirect
+	a.mu.Unlock()
+
+	return response, nil
+}
+
+// AutoStartURL builds the link that launches the BankID app for a same-device
+// login. An empty redirect becomes "null", which tells BankID not to return
+// to another app when done.
+func AutoStartURL(autoStartToken, redirect string) string {
+	if redirect == "" {
+		redirect = "null"
+	}
+	params := url.Values{}
+	params.Set("autostarttoken", autoStartToken)
+	params.Set("redirect", redirect)
+	return "bankid:///?" + params.Encode()
+}
+
+func (a *AuthService) startBankID(ctx context.Context, reqBody BankIDStartRequest) (*BankIDStartResponse, error) {
 	resp, err := a.client.Post(ctx, "/_api/authentication/v2/sessions/bankid", reqBody)
 	if err != nil {
 		return nil, err
@@ -99,10 +236,16 @@ func (a *AuthService) StartBankID(ctx context.Context) (*BankIDStartResponse, er
 	}
 
 	var response BankIDStartResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := a.client.DecodeJSON(resp, &response); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	a.mu.Lock()
+	a.tx = bankIDTransaction{method: reqBody.Method, expires: response.ExpiresAt()}
+	a.mu.Unlock()
+
+	a.log(ctx, slog.LevelInfo, "bankid transaction started", "method", reqBody.Method, "expires", response.Expires)
+
 	return &response, nil
 }
 
@@ -119,10 +262,16 @@ func (a *AuthService) RestartBankID(ctx context.Context) (*BankIDStartResponse,
 	}
 
 	var response BankIDStartResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := a.client.DecodeJSON(resp, &response); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	if expires := response.ExpiresAt(); !expires.IsZero() {
+		a.mu.Lock()
+		a.tx.expires = expires
+		a.mu.Unlock()
+	}
+
 	return &response, nil
 }
 
@@ -139,7 +288,7 @@ func (a *AuthService) CollectBankID(ctx context.Context) (*BankIDCollectResponse
 	}
 
 	var response BankIDCollectResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := a.client.DecodeJSON(resp, &response); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -147,90 +296,75 @@ func (a *AuthService) CollectBankID(ctx context.Context) (*BankIDCollectResponse
 }
 
 // PollBankID polls authentication status every second until completion or failure.
+// An expired transaction is restarted (see WithMaxBankIDRestarts); a failed one
+// returns a *BankIDError.
 func (a *AuthService) PollBankID(ctx context.Context) (*BankIDCollectResponse, error) {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
-			collectResp, err := a.CollectBankID(ctx)
-			if err != nil {
-				return nil, err
-			}
-
-			if collectResp.State == "COMPLETE" {
-				return collectResp, nil
-			}
-
-			if collectResp.State == "FAILED" {
-				return nil, fmt.Errorf("bankid authentication failed: %s", collectResp.HintCode)
-			}
-		}
-	}
+	return a.pollBankID(ctx, false)
 }
 
-// PollBankIDWithQRUpdates polls authentication and refreshes the QR code every second.
-// Recommended for QR-based authentication.
+// PollBankIDWithQRUpdates polls authentication and refreshes the QR code every second,
+// showing each new code with the configured QRPresenter. Recommended for QR-based authentication.
 func (a *AuthService) PollBankIDWithQRUpdates(ctx context.Context) (*BankIDCollectResponse, error) {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
-			collectResp, err := a.CollectBankID(ctx)
-			if err != nil {
-				return nil, err
-			}
-
-			if collectResp.State == "COMPLETE" {
-				return collectResp, nil
-			}
-
-			if collectResp.State == "FAILED" {
-				return nil, fmt.Errorf("bankid authentication failed: %s", collectResp.HintCode)
-			}
-
-			// Still pending — refresh QR code for next scan attempt.
-			restartResp, err := a.RestartBankID(ctx)
-			if err != nil {
-				continue
-			}
-			_ = a.DisplayQRCode(restartResp.QRToken)
-		}
-	}
+	return a.pollBankID(ctx, true)
 }
 
 // ClearScreen clears the terminal using ANSI escape codes.
+// It always writes to stdout, regardless of the configured QRPresenter.
 func (a *AuthService) ClearScreen() {
 	fmt.Print("\033[H\033[2J")
 }
 
-// DisplayQRCode renders a QR code in the terminal. Clears the screen first.
+// DisplayQRCode shows a QR code with the configured QRPresenter. By default it
+// renders to the terminal, clearing the screen first.
 func (a *AuthService) DisplayQRCode(qrCodeData string) error {
 	if qrCodeData == "" {
 		return fmt.Errorf("empty qr code data")
 	}
 
-	a.ClearScreen()
-	fmt.Println("Scan QR code with BankID app to authenticate to Avanza...")
-	qrterminal.GenerateHalfBlock(qrCodeData, qrterminal.L, os.Stdout)
-	return nil
+	return a.presenter.PresentQR(context.Background(), qrCodeData)
 }
 
-// EstablishSession establishes a session after BankID authentication.
-// Required before making other API calls.
+// EstablishSession establishes a session for the first login after BankID
+// authentication. Required before making other API calls.
+// Use EstablishSessionFor to pick another login.
 func (a *AuthService) EstablishSession(ctx context.Context, collectResp *BankIDCollectResponse) error {
+	return a.EstablishSessionFor(ctx, collectResp, nil)
+}
+
+// EstablishSessionFor establishes a session for the first login matching
+// selector, e.g. a company or power-of-attorney customer when the person has
+// several. A nil selector picks the first login. The other logins are kept so
+// SwitchLogin can move to them later without a new BankID scan.
+//
+//	err := client.Auth.EstablishSessionFor(ctx, collectResp, auth.ByCustomerID("1234567"))
+func (a *AuthService) EstablishSessionFor(ctx context.Context, collectResp *BankIDCollectResponse, selector LoginSelector) error {
 	if collectResp == nil || len(collectResp.Logins) == 0 {
 		return fmt.Errorf("no logins available in authentication response")
 	}
 
-	login := collectResp.Logins[0]
+	login, err := selectLogin(collectResp.Logins, selector)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.logins = append([]Login(nil), collectResp.Logins...)
+	a.mu.Unlock()
+
+	return a.establish(ctx, login)
+}
+
+// establish selects login and verifies the resulting session.
+func (a *AuthService) establish(ctx context.Context, login Login) error {
+	if err := a.establishLogin(ctx, login); err != nil {
+		a.log(ctx, slog.LevelWarn, "establish session failed", "error", redact.String(err.Error()))
+		return err
+	}
+	a.log(ctx, slog.LevelInfo, "session established", "logins", len(a.Logins()))
+	return nil
+}
+
+func (a *AuthService) establishLogin(ctx context.Context, login Login) error {
 	if login.LoginPath == "" {
 		return fmt.Errorf("login path is empty")
 	}
@@ -267,9 +401,87 @@ func (a *AuthService) EstablishSession(ctx context.Context, collectResp *BankIDC
 		return fmt.Errorf("verify session: %w", client.NewHTTPError(sessionResp))
 	}
 
+	// The session is live at this point; a failed save only costs a BankID
+	// scan on the next restart, but the caller should still hear about it.
+	if a.store != nil {
+		session := &Session{Cookies: a.client.Cookies(), SavedAt: time.Now(), Logins: a.Logins()}
+		if err := a.store.Save(ctx, session); err != nil {
+			return fmt.Errorf("save session: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// RestoreSession loads the saved session from the configured SessionStore and
+// verifies it with GetSessionInfo. It returns ErrNoSession if nothing is saved,
+// and ErrSessionExpired (after discarding the saved session) if Avanza no longer
+// accepts it. Either way the caller should fall back to the BankID flow.
+//
+//	info, err := client.Auth.RestoreSession(ctx)
+//	if errors.Is(err, auth.ErrNoSession) || errors.Is(err, auth.ErrSessionExpired) {
+//		// run StartBankID / PollBankIDWithQRUpdates / EstablishSession
+//	}
+func (a *AuthService) RestoreSession(ctx context.Context) (*SessionInfo, error) {
+	if a.store == nil {
+		return nil, fmt.Errorf("restore session: no session store configured")
+	}
+
+	session, err := a.store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(session.Cookies) == 0 {
+		return nil, a.discardSession(ctx)
+	}
+
+	a.client.SetCookies(session.Cookies)
+	a.mu.Lock()
+	a.logins = session.Logins
+	a.mu.Unlock()
+
+	info, err := a.GetSessionInfo(ctx)
+	if err != nil {
+		if isSessionRejected(err) {
+			return nil, a.discardSession(ctx)
+		}
+		// Transient failure: keep the saved session so a later attempt can use it.
+		a.client.SetCookies(nil)
+		a.log(ctx, slog.LevelWarn, "verify restored session failed", "error", redact.String(err.Error()))
+		return nil, fmt.Errorf("verify restored session: %w", err)
+	}
+	if !info.User.LoggedIn {
+		return nil, a.discardSession(ctx)
+	}
+
+	a.log(ctx, slog.LevelInfo, "session restored", "saved_at", session.SavedAt)
+	return info, nil
+}
+
+// ClearSession drops the session cookies from the client and removes the saved
+// session from the configured SessionStore, if any.
+func (a *AuthService) ClearSession(ctx context.Context) error {
+	a.client.SetCookies(nil)
+	a.mu.Lock()
+	a.logins = nil
+	a.mu.Unlock()
+	a.log(ctx, slog.LevelInfo, "session cleared")
+	if a.store == nil {
+		return nil
+	}
+	return a.store.Clear(ctx)
+}
+
+// discardSession clears an expired session and returns ErrSessionExpired,
+// joined with the store error if the saved session could not be removed.
+func (a *AuthService) discardSession(ctx context.Context) error {
+	a.log(ctx, slog.LevelInfo, "saved session expired")
+	if err := a.ClearSession(ctx); err != nil {
+		return errors.Join(ErrSessionExpired, err)
+	}
+	return ErrSessionExpired
+}
+
 // SessionInfo contains the current session state and user details.
 type SessionInfo struct {
 	InvalidSessionID string `json:"invalidSessionId"`
@@ -303,9 +515,14 @@ func (a *AuthService) GetSessionInfo(ctx context.Context) (*SessionInfo, error)
 	}
 
 	var sessionInfo SessionInfo
-	if err := json.NewDecoder(resp.Body).Decode(&sessionInfo); err != nil {
+	if err := a.client.DecodeJSON(resp, &sessionInfo); err != nil {
 		return nil, fmt.Errorf("failed to decode session info: %w", err)
 	}
 
 	return &sessionInfo, nil
 }
+
+// isSessionRejected reports whether err is Avanza refusing the session cookies.
+func isSessionRejected(err error) bool {
+	return errors.Is(err, client.ErrSessionExpired)
+}
@@ -273,7 +273,7 @@ func TestCollectBankID_AllStates(t *testing.T) {
 
 				resp := BankIDCollectResponse{
 					State:                "COMPLETE",
-					HintCode:             tt.hintCode,
+					HintCode:             HintCode(tt.hintCode),
 					Name:                 "FOO BAR",
 					TransactionID:        "FOO",
 					IdentificationNumber: "42",
@@ -358,7 +358,7 @@ func TestPollBankID_Complete(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		n := callCount.Add(1)
 
-		var state string
+		var state BankIDState
 		if n < 3 {
 			state = "PENDING"
 		} else {
@@ -484,7 +484,7 @@ func TestPollBankIDWithQRUpdates_Complete(t *testing.T) {
 		switch r.URL.Path {
 		case "/_api/authentication/v2/sessions/bankid/collect":
 			collectCalls++
-			var state string
+			var state BankIDState
 			if collectCalls < 3 {
 				state = "PENDING"
 			} else {
@@ -1042,3 +1042,71 @@ func TestEstablishSession_TradingPageHTTPError(t *testing.T) {
 		t.Errorf("status = %d, want 500", httpErr.StatusCode)
 	}
 }
+
+func TestStartBankIDSameDevice_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		var req BankIDStartRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req.Method != BankIDMethodSameDevice {
+			t.Errorf("expected method %s, got %s", BankIDMethodSameDevice, req.Method)
+		}
+
+		_, _ = w.Write([]byte(`{"transactionId":"tx","expires":"2026-01-01T00:00:30Z","autostartToken":"a1b2-c3"}`))
+	}))
+	defer server.Close()
+
+	service := NewAuthService(newTestClient(server.URL))
+
+	resp, err := service.StartBankIDSameDevice(context.Background(), "myapp://done")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.AutoStartToken != "a1b2-c3" {
+		t.Errorf("expected autostart token a1b2-c3, got %s", resp.AutoStartToken)
+	}
+	want := "bankid:///?autostarttoken=a1b2-c3&redirect=myapp%3A%2F%2Fdone"
+	if resp.AutoStartURL != want {
+		t.Errorf("expected AutoStartURL %s, got %s", want, resp.AutoStartURL)
+	}
+}
+
+func TestStartBankIDSameDevice_MissingToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"transactionId":"tx"}`))
+	}))
+	defer server.Close()
+
+	service := NewAuthService(newTestClient(server.URL))
+
+	if _, err := service.StartBankIDSameDevice(context.Background(), ""); err == nil {
+		t.Error("expected error when response has no autostart token")
+	}
+}
+
+func TestAutoStartURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		redirect string
+		want     string
+	}{
+		{"no redirect", "tok", "", "bankid:///?autostarttoken=tok&redirect=null"},
+		{"https redirect", "tok", "https://example.com/cb?x=1", "bankid:///?autostarttoken=tok&redirect=https%3A%2F%2Fexample.com%2Fcb%3Fx%3D1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AutoStartURL(tt.token, tt.redirect); got != tt.want {
+				t.Errorf("AutoStartURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Package auth provides BankID authentication functionality for the Avanza API.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// DefaultMaxBankIDRestarts is how many expired BankID transactions polling
+// replaces with new ones before giving up.
+const DefaultMaxBankIDRestarts = 3
+
+// bankIDPollInterval is how often polling collects. It is a variable only so
+// tests can lower it.
+var bankIDPollInterval = time.Second
+
+// BankIDState is the state of a BankID transaction as reported by collect.
+type BankIDState string
+
+const (
+	BankIDStateOutstandingTransaction BankIDState = "OUTSTANDING_TRANSACTION" // Waiting for the BankID app
+	BankIDStateStarted                BankIDState = "STARTED"                 // BankID app opened, no certificate picked yet
+	BankIDStateUserSign               BankIDState = "USER_SIGN"               // Waiting for the user to confirm in the app
+	BankIDStateComplete               BankIDState = "COMPLETE"                // Authenticated; Logins are available
+	BankIDStateFailed                 BankIDState = "FAILED"                  // Failed; see HintCode
+	BankIDStateExpired                BankIDState = "EXPIRED"                 // Transaction timed out before the user finished
+)
+
+// HintCode is BankID's detail code for the current state.
+type HintCode string
+
+const (
+	HintOutstandingTransaction HintCode = "outstandingTransaction" // Waiting for the user to start the app
+	HintNoClient               HintCode = "noClient"               // BankID app not started yet
+	HintStarted                HintCode = "started"                // App started, looking for a certificate
+	HintUserSign               HintCode = "userSign"               // Waiting for the user's security code
+	HintExpiredTransaction     HintCode = "expiredTransaction"     // Transaction timed out
+	HintCertificateErr         HintCode = "certificateErr"         // Certificate blocked or invalid
+	HintUserCancel             HintCode = "userCancel"             // User cancelled in the app
+	HintCancelled              HintCode = "cancelled"              // Replaced by a newer transaction
+	HintStartFailed            HintCode = "startFailed"            // App could not be started
+)
+
+// BankIDProgress is a BankID state change reported to the WithBankIDProgress
+// callback. When an expired transaction is replaced, State is
+// BankIDStateExpired and Start holds the new transaction, so same-device
+// logins can open the new AutoStartURL.
+type BankIDProgress struct {
+	State    BankIDState
+	HintCode HintCode
+	Expires  time.Time
+	Restarts int
+	Start    *BankIDStartResponse
+}
+
+// BankIDError is returned by PollBankID and PollBankIDWithQRUpdates when the
+// BankID transaction fails, or expires more often than WithMaxBankIDRestarts
+// allows.
+type BankIDError struct {
+	State    BankIDState
+	HintCode HintCode
+	Hint     string
+}
+
+func (e *BankIDError) Error() string {
+	return fmt.Sprintf("bankid authentication failed: %s", e.HintCode)
+}
+
+// ExpiresAt parses Expires. It returns the zero time if Expires is empty or
+// not an RFC 3339 timestamp.
+func (r *BankIDStartResponse) ExpiresAt() time.Time {
+	t, err := time.Parse(time.RFC3339Nano, r.Expires)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// bankIDTransaction is what polling needs to replace an expired transaction.
+type bankIDTransaction struct {
+	method   string
+	redirect string
+	expires  time.Time
+}
+
+func (a *AuthService) pollBankID(ctx context.Context, refreshQR bool) (*BankIDCollectResponse, error) {
+	ticker := time.NewTicker(bankIDPollInterval)
+	defer ticker.Stop()
+
+	var last BankIDProgress
+	restarts := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		collectResp, err := a.CollectBankID(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		a.mu.Lock()
+		tx := a.tx
+		a.mu.Unlock()
+
+		progress := BankIDProgress{
+			State:    collectResp.State,
+			HintCode: collectResp.HintCode,
+			Expires:  tx.expires,
+			Restarts: restarts,
+		}
+
+		switch {
+		case collectResp.State == BankIDStateComplete:
+			a.reportBankIDProgress(ctx, &last, progress)
+			return collectResp, nil
+
+		case transactionExpired(collectResp, tx):
+			progress.State = BankIDStateExpired
+			progress.HintCode = HintExpiredTransaction
+			// A transaction not started through this service cannot be replaced.
+			if restarts >= a.maxBankIDRestarts || tx.method == "" {
+				a.reportBankIDProgress(ctx, &last, progress)
+				return nil, &BankIDError{State: BankIDStateExpired, HintCode: HintExpiredTransaction, Hint: collectResp.Hint}
+			}
+
+			start, err := a.restartTransaction(ctx, tx)
+			if err != nil {
+				return nil, fmt.Errorf("restart expired bankid transaction: %w", err)
+			}
+			restarts++
+			progress.Restarts = restarts
+			progress.Expires = start.ExpiresAt()
+			progress.Start = start
+			a.reportBankIDProgress(ctx, &last, progress)
+			// Report the new transaction's first state even if it matches the old one.
+			last = BankIDProgress{}
+
+			if tx.method == BankIDMethodQR {
+				if err := a.presenter.PresentQR(ctx, start.QRToken); err != nil {
+					return nil, fmt.Errorf("present qr code: %w", err)
+				}
+			}
+			continue
+
+		case collectResp.State == BankIDStateFailed:
+			a.reportBankIDProgress(ctx, &last, progress)
+			return nil, &BankIDError{State: collectResp.State, HintCode: collectResp.HintCode, Hint: collectResp.Hint}
+		}
+
+		a.reportBankIDProgress(ctx, &last, progress)
+
+		if refreshQR {
+			// Still pending — refresh QR code for next scan attempt.
+			restartResp, err := a.RestartBankID(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("refresh qr code: %w", err)
+			}
+			if err := a.presenter.PresentQR(ctx, restartResp.QRToken); err != nil {
+				return nil, fmt.Errorf("present qr code: %w", err)
+			}
+		}
+	}
+}
+
+// transactionExpired reports whether BankID gave up on the transaction, or its
+// expiry time has passed while it is still pending.
+func transactionExpired(collectResp *BankIDCollectResponse, tx bankIDTransaction) bool {
+	if collectResp.State == BankIDStateExpired || collectResp.HintCode == HintExpiredTransaction {
+		return true
+	}
+	return collectResp.State != BankIDStateFailed && !tx.expires.IsZero() && time.Now().After(tx.expires)
+}
+
+// restartTransaction starts a new transaction with the same method as tx.
+func (a *AuthService) restartTransaction(ctx context.Context, tx bankIDTransaction) (*BankIDStartResponse, error) {
+	if tx.method == BankIDMethodSameDevice {
+		return a.StartBankIDSameDevice(ctx, tx.redirect)
+	}
+	return a.startBankID(ctx, BankIDStartRequest{
+		Method:       BankIDMethodQR,
+		ReturnScheme: "NULL",
+	})
+}
+
+// reportBankIDProgress logs p and calls the progress callback if p differs
+// from *last.
+func (a *AuthService) reportBankIDProgress(ctx context.Context, last *BankIDProgress, p BankIDProgress) {
+	if p.State == last.State && p.HintCode == last.HintCode && p.Start == nil {
+		return
+	}
+	*last = p
+
+	level := slog.LevelInfo
+	if p.State == BankIDStateFailed || p.State == BankIDStateExpired {
+		level = slog.LevelWarn
+	}
+	a.log(ctx, level, "bankid state changed", "state", p.State, "hint_code", p.HintCode, "restarts", p.Restarts)
+
+	if a.onBankIDProgress != nil {
+		a.onBankIDProgress(p)
+	}
+}
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/client"
+)
+
+func init() {
+	bankIDPollInterval = 10 * time.Millisecond
+}
+
+// bankIDServer serves the BankID endpoints, answering collect with the next
+// response from collects (repeating the last one) and counting new transactions.
+type bankIDServer struct {
+	mu       sync.Mutex
+	collects []BankIDCollectResponse
+	starts   []BankIDStartRequest
+	expires  string
+	restart  int // status code for /restart, 0 for 200
+}
+
+func (s *bankIDServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/":
+	case "/_api/authentication/v2/sessions/bankid":
+		var req BankIDStartRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.starts = append(s.starts, req)
+		_ = json.NewEncoder(w).Encode(BankIDStartResponse{
+			TransactionID:  "tx",
+			Expires:        s.expires,
+			QRToken:        "qr-start",
+			AutoStartToken: "auto",
+		})
+	case "/_api/authentication/v2/sessions/bankid/restart":
+		if s.restart != 0 {
+			w.WriteHeader(s.restart)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(BankIDStartResponse{QRToken: "qr-refresh"})
+	case "/_api/authentication/v2/sessions/bankid/collect":
+		resp := s.collects[0]
+		if len(s.collects) > 1 {
+			s.collects = s.collects[1:]
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *bankIDServer) startCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.starts)
+}
+
+func TestPollBankID_ReportsProgress(t *testing.T) {
+	srv := &bankIDServer{collects: []BankIDCollectResponse{
+		{State: BankIDStateOutstandingTransaction, HintCode: HintOutstandingTransaction},
+		{State: BankIDStateOutstandingTransaction, HintCode: HintOutstandingTransaction},
+		{State: BankIDStateStarted, HintCode: HintStarted},
+		{State: BankIDStateUserSign, HintCode: HintUserSign},
+		{State: BankIDStateComplete},
+	}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	var states []BankIDState
+	service := NewAuthService(newTestClient(server.URL), WithBankIDProgress(func(p BankIDProgress) {
+		states = append(states, p.State)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := service.PollBankID(ctx); err != nil {
+		t.Fatalf("PollBankID: %v", err)
+	}
+
+	want := []BankIDState{BankIDStateOutstandingTransaction, BankIDStateStarted, BankIDStateUserSign, BankIDStateComplete}
+	if len(states) != len(want) {
+		t.Fatalf("states = %v, want %v", states, want)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Errorf("states[%d] = %s, want %s", i, states[i], want[i])
+		}
+	}
+}
+
+func TestPollBankID_FailedReturnsBankIDError(t *testing.T) {
+	srv := &bankIDServer{collects: []BankIDCollectResponse{
+		{State: BankIDStateFailed, HintCode: HintCertificateErr, Hint: "blocked"},
+	}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	service := NewAuthService(newTestClient(server.URL))
+
+	_, err := service.PollBankID(context.Background())
+	var bankIDErr *BankIDError
+	if !errors.As(err, &bankIDErr) {
+		t.Fatalf("err = %v, want *BankIDError", err)
+	}
+	if bankIDErr.HintCode != HintCertificateErr || bankIDErr.Hint != "blocked" {
+		t.Errorf("BankIDError = %+v", bankIDErr)
+	}
+}
+
+func TestPollBankIDWithQRUpdates_RestartsExpiredTransaction(t *testing.T) {
+	srv := &bankIDServer{collects: []BankIDCollectResponse{
+		{State: BankIDStateFailed, HintCode: HintExpiredTransaction},
+		{State: BankIDStateOutstandingTransaction},
+		{State: BankIDStateComplete},
+	}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	var progress []BankIDProgress
+	var presented []string
+	service := NewAuthService(newTestClient(server.URL),
+		WithBankIDProgress(func(p BankIDProgress) { progress = append(progress, p) }),
+		WithQRPresenter(QRPresenterFunc(func(_ context.Context, token string) error {
+			presented = append(presented, token)
+			return nil
+		})))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := service.StartBankID(ctx); err != nil {
+		t.Fatalf("StartBankID: %v", err)
+	}
+	if _, err := service.PollBankIDWithQRUpdates(ctx); err != nil {
+		t.Fatalf("PollBankIDWithQRUpdates: %v", err)
+	}
+
+	if got := srv.startCount(); got != 2 {
+		t.Errorf("transactions started = %d, want 2", got)
+	}
+	if len(presented) == 0 || presented[0] != "qr-start" {
+		t.Errorf("presented = %v, want new transaction's QR first", presented)
+	}
+	if len(progress) == 0 || progress[0].State != BankIDStateExpired || progress[0].Start == nil || progress[0].Restarts != 1 {
+		t.Errorf("first progress = %+v, want expired with new transaction", progress[0])
+	}
+}
+
+func TestPollBankID_RestartLimit(t *testing.T) {
+	srv := &bankIDServer{collects: []BankIDCollectResponse{
+		{State: BankIDStateFailed, HintCode: HintExpiredTransaction},
+	}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	service := NewAuthService(newTestClient(server.URL), WithMaxBankIDRestarts(2))
+	ctx := context.Background()
+
+	if _, err := service.StartBankIDSameDevice(ctx, ""); err != nil {
+		t.Fatalf("StartBankIDSameDevice: %v", err)
+	}
+
+	_, err := service.PollBankID(ctx)
+	var bankIDErr *BankIDError
+	if !errors.As(err, &bankIDErr) || bankIDErr.HintCode != HintExpiredTransaction {
+		t.Fatalf("err = %v, want *BankIDError with expiredTransaction", err)
+	}
+	if got := srv.startCount(); got != 3 {
+		t.Errorf("transactions started = %d, want 3 (initial + 2 restarts)", got)
+	}
+	for _, req := range srv.starts {
+		if req.Method != BankIDMethodSameDevice {
+			t.Errorf("restart used method %s, want %s", req.Method, BankIDMethodSameDevice)
+		}
+	}
+}
+
+func TestPollBankID_ExpiresTimestamp(t *testing.T) {
+	srv := &bankIDServer{
+		collects: []BankIDCollectResponse{{State: BankIDStateOutstandingTransaction}},
+		expires:  time.Now().Add(-time.Second).Format(time.RFC3339),
+	}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	service := NewAuthService(newTestClient(server.URL), WithMaxBankIDRestarts(0))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := service.StartBankID(ctx); err != nil {
+		t.Fatalf("StartBankID: %v", err)
+	}
+
+	_, err := service.PollBankID(ctx)
+	var bankIDErr *BankIDError
+	if !errors.As(err, &bankIDErr) || bankIDErr.State != BankIDStateExpired {
+		t.Fatalf("err = %v, want expired *BankIDError", err)
+	}
+}
+
+func TestPollBankIDWithQRUpdates_RestartError(t *testing.T) {
+	srv := &bankIDServer{
+		collects: []BankIDCollectResponse{{State: BankIDStateOutstandingTransaction}},
+		restart:  http.StatusInternalServerError,
+	}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	service := NewAuthService(newTestClient(server.URL), WithQRPresenter(QRPresenterFunc(
+		func(context.Context, string) error { return nil })))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := service.PollBankIDWithQRUpdates(ctx)
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want qr refresh error", err)
+	}
+}
+
+func TestPollBankID_LogsStateChanges(t *testing.T) {
+	srv := &bankIDServer{collects: []BankIDCollectResponse{
+		{State: BankIDStateOutstandingTransaction, HintCode: HintOutstandingTransaction},
+		{State: BankIDStateComplete, IdentificationNumber: "199001011234"},
+	}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := client.NewClient(client.WithBaseURL(server.URL), client.WithLogger(logger))
+	service := NewAuthService(c, WithLogger(logger), WithQRPresenter(QRPresenterFunc(
+		func(context.Context, string) error { return nil })))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := service.StartBankID(ctx); err != nil {
+		t.Fatalf("StartBankID: %v", err)
+	}
+	if _, err := service.PollBankIDWithQRUpdates(ctx); err != nil {
+		t.Fatalf("PollBankIDWithQRUpdates: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"bankid transaction started", "state=OUTSTANDING_TRANSACTION", "state=COMPLETE"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q:\n%s", want, out)
+		}
+	}
+	for _, secret := range []string{"qr-start", "qr-refresh", "199001011234"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log contains %q:\n%s", secret, out)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package auth provides BankID authentication functionality for the Avanza API.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoMatchingLogin is returned when no login matches a LoginSelector.
+var ErrNoMatchingLogin = errors.New("no login matches selector")
+
+// LoginSelector picks one of the logins returned by BankID, for
+// EstablishSessionFor and SwitchLogin.
+type LoginSelector func(Login) bool
+
+// ByCustomerID selects the login for a customer ID.
+func ByCustomerID(customerID string) LoginSelector {
+	return func(l Login) bool {
+		return l.CustomerID == customerID
+	}
+}
+
+// ByUsername selects the login with a username.
+func ByUsername(username string) LoginSelector {
+	return func(l Login) bool {
+		return l.Username == username
+	}
+}
+
+// ByAccountType selects a login that holds an account of accountType
+// (e.g. "ISK", "KF", "AF").
+func ByAccountType(accountType string) LoginSelector {
+	return func(l Login) bool {
+		for _, acc := range l.Accounts {
+			if acc.AccountType == accountType {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Logins returns the logins from the last completed BankID transaction, or
+// from the restored session. It is empty before login.
+func (a *AuthService) Logins() []Login {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]Login(nil), a.logins...)
+}
+
+// SwitchLogin moves the session to another login from the same BankID
+// transaction without a new scan. It fails if Avanza no longer accepts the
+// transaction's login paths; run the BankID flow again in that case.
+//
+//	if err := client.Auth.SwitchLogin(ctx, auth.ByUsername("mycompany")); err != nil {
+//		log.Fatal(err)
+//	}
+func (a *AuthService) SwitchLogin(ctx context.Context, selector LoginSelector) error {
+	logins := a.Logins()
+	if len(logins) == 0 {
+		return fmt.Errorf("switch login: no logins available, establish a session first")
+	}
+
+	login, err := selectLogin(logins, selector)
+	if err != nil {
+		return fmt.Errorf("switch login: %w", err)
+	}
+
+	return a.establish(ctx, login)
+}
+
+func selectLogin(logins []Login, selector LoginSelector) (Login, error) {
+	if selector == nil {
+		return logins[0], nil
+	}
+	for _, l := range logins {
+		if selector(l) {
+			return l, nil
+		}
+	}
+	return Login{}, ErrNoMatchingLogin
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+var testLogins = []Login{
+	{CustomerID: "111", Username: "anna", LoginPath: "/login/111", Accounts: []Account{{AccountType: "ISK"}}},
+	{CustomerID: "222", Username: "annas-ab", LoginPath: "/login/222", Accounts: []Account{{AccountType: "AF"}}},
+}
+
+// loginServer records which login paths were visited and answers every
+// request with 200.
+func loginServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var visited []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		visited = append(visited, r.URL.Path)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		var logins []string
+		for _, p := range visited {
+			if p == "/login/111" || p == "/login/222" {
+				logins = append(logins, p)
+			}
+		}
+		return logins
+	}
+}
+
+func TestLoginSelectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector LoginSelector
+		want     string
+		wantErr  bool
+	}{
+		{"nil picks first", nil, "111", false},
+		{"by customer id", ByCustomerID("222"), "222", false},
+		{"by username", ByUsername("anna"), "111", false},
+		{"by account type", ByAccountType("AF"), "222", false},
+		{"no match", ByCustomerID("999"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectLogin(testLogins, tt.selector)
+			if tt.wantErr {
+				if !errors.Is(err, ErrNoMatchingLogin) {
+					t.Errorf("err = %v, want ErrNoMatchingLogin", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.CustomerID != tt.want {
+				t.Errorf("CustomerID = %s, want %s", got.CustomerID, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstablishSessionFor_SelectsLogin(t *testing.T) {
+	server, visited := loginServer(t)
+	service := NewAuthService(newTestClient(server.URL))
+
+	err := service.EstablishSessionFor(context.Background(), &BankIDCollectResponse{Logins: testLogins}, ByCustomerID("222"))
+	if err != nil {
+		t.Fatalf("EstablishSessionFor: %v", err)
+	}
+
+	if got := visited(); len(got) != 1 || got[0] != "/login/222" {
+		t.Errorf("visited logins = %v, want [/login/222]", got)
+	}
+	if got := service.Logins(); len(got) != 2 {
+		t.Errorf("Logins() has %d entries, want 2", len(got))
+	}
+}
+
+func TestEstablishSessionFor_NoMatch(t *testing.T) {
+	server, visited := loginServer(t)
+	service := NewAuthService(newTestClient(server.URL))
+
+	err := service.EstablishSessionFor(context.Background(), &BankIDCollectResponse{Logins: testLogins}, ByUsername("nobody"))
+	if !errors.Is(err, ErrNoMatchingLogin) {
+		t.Fatalf("err = %v, want ErrNoMatchingLogin", err)
+	}
+	if got := visited(); len(got) != 0 {
+		t.Errorf("visited logins = %v, want none", got)
+	}
+}
+
+func TestSwitchLogin(t *testing.T) {
+	server, visited := loginServer(t)
+	store := &memoryStore{}
+	service := NewAuthService(newTestClient(server.URL), WithSessionStore(store))
+	ctx := context.Background()
+
+	if err := service.SwitchLogin(ctx, ByCustomerID("222")); err == nil {
+		t.Error("expected error before a session is established")
+	}
+
+	if err := service.EstablishSession(ctx, &BankIDCollectResponse{Logins: testLogins}); err != nil {
+		t.Fatalf("EstablishSession: %v", err)
+	}
+	if err := service.SwitchLogin(ctx, ByAccountType("AF")); err != nil {
+		t.Fatalf("SwitchLogin: %v", err)
+	}
+
+	got := visited()
+	if len(got) != 2 || got[0] != "/login/111" || got[1] != "/login/222" {
+		t.Errorf("visited logins = %v, want [/login/111 /login/222]", got)
+	}
+	if len(store.session.Logins) != 2 {
+		t.Errorf("saved session has %d logins, want 2", len(store.session.Logins))
+	}
+
+	if err := service.SwitchLogin(ctx, ByCustomerID("999")); !errors.Is(err, ErrNoMatchingLogin) {
+		t.Errorf("err = %v, want ErrNoMatchingLogin", err)
+	}
+}
+
+func TestRestoreSession_RestoresLogins(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"user":{"loggedIn":true}}`))
+	}))
+	defer server.Close()
+
+	store := &memoryStore{session: &Session{Cookies: map[string]string{"csid": "c1"}, Logins: testLogins}}
+	service := NewAuthService(newTestClient(server.URL), WithSessionStore(store))
+
+	if _, err := service.RestoreSession(context.Background()); err != nil {
+		t.Fatalf("RestoreSession: %v", err)
+	}
+	if got := service.Logins(); len(got) != 2 || got[1].CustomerID != "222" {
+		t.Errorf("Logins() = %+v, want restored logins", got)
+	}
+}
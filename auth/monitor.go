@@ -0,0 +1,224 @@
+// Package auth provides BankID authentication functionality for the Avanza API.
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/internal/redact"
+)
+
+const (
+	// DefaultSessionCheckInterval is how often a SessionMonitor checks (and
+	// thereby keeps alive) the session when no interval is configured.
+	DefaultSessionCheckInterval = time.Minute
+
+	// DefaultSessionExpiringWithin is how long before MaxAge a SessionMonitor
+	// reports SessionStateExpiring when no threshold is configured.
+	DefaultSessionExpiringWithin = 10 * time.Minute
+)
+
+// SessionState is the lifecycle state reported by a SessionMonitor.
+type SessionState string
+
+const (
+	SessionStateActive   SessionState = "ACTIVE"   // Session is valid
+	SessionStateExpiring SessionState = "EXPIRING" // Session is valid but close to its maximum age
+	SessionStateExpired  SessionState = "EXPIRED"  // Avanza no longer accepts the session
+)
+
+// SessionEvent is a session lifecycle change reported by a SessionMonitor.
+// Info is nil for SessionStateExpired. Remaining is the estimated time left
+// before MaxAge, and is zero when MaxAge is not configured.
+type SessionEvent struct {
+	State     SessionState
+	Info      *SessionInfo
+	Remaining time.Duration
+	Time      time.Time
+}
+
+// SessionMonitorConfig configures a SessionMonitor. The zero value checks
+// every DefaultSessionCheckInterval and never reports SessionStateExpiring.
+type SessionMonitorConfig struct {
+	// Interval between session checks. Each check also counts as activity
+	// and keeps the session from idling out.
+	Interval time.Duration
+
+	// MaxAge is the longest Avanza keeps a session regardless of activity.
+	// When set, SessionStateExpiring is reported once ExpiringWithin remains.
+	MaxAge time.Duration
+
+	// ExpiringWithin is the warning threshold before MaxAge.
+	// Defaults to DefaultSessionExpiringWithin.
+	ExpiringWithin time.Duration
+
+	// StartedAt is when the session was established, used with MaxAge.
+	// Defaults to the time the monitor starts; pass Session.SavedAt for a
+	// restored session.
+	StartedAt time.Time
+}
+
+// SessionMonitor checks the session in the background, keeping it alive while
+// it is valid and reporting lifecycle changes on Events. It stops by itself
+// after reporting SessionStateExpired.
+type SessionMonitor struct {
+	auth   *AuthService
+	cfg    SessionMonitorConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+	events chan SessionEvent
+	errors chan error
+	wg     sync.WaitGroup
+}
+
+// MonitorSession starts a SessionMonitor for the current session. Call Close()
+// when done. A nil cfg uses the defaults.
+//
+//	mon := client.Auth.MonitorSession(ctx, &auth.SessionMonitorConfig{MaxAge: 24 * time.Hour})
+//	defer mon.Close()
+//	for ev := range mon.Events() {
+//		if ev.State == auth.SessionStateExpired {
+//			// stop trading and run the BankID flow again
+//		}
+//	}
+func (a *AuthService) MonitorSession(ctx context.Context, cfg *SessionMonitorConfig) *SessionMonitor {
+	var c SessionMonitorConfig
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.Interval <= 0 {
+		c.Interval = DefaultSessionCheckInterval
+	}
+	if c.ExpiringWithin <= 0 {
+		c.ExpiringWithin = DefaultSessionExpiringWithin
+	}
+	if c.StartedAt.IsZero() {
+		c.StartedAt = time.Now()
+	}
+
+	monCtx, cancel := context.WithCancel(ctx)
+	m := &SessionMonitor{
+		auth:   a,
+		cfg:    c,
+		ctx:    monCtx,
+		cancel: cancel,
+		events: make(chan SessionEvent, 10),
+		errors: make(chan error, 10),
+	}
+	m.wg.Add(1)
+	go m.run()
+	return m
+}
+
+// Events returns a channel that receives session lifecycle changes.
+// It is closed when the monitor stops.
+func (m *SessionMonitor) Events() <-chan SessionEvent {
+	return m.events
+}
+
+// Errors returns a channel that receives transient check failures (network
+// errors, 5xx). These do not change the reported state.
+func (m *SessionMonitor) Errors() <-chan error {
+	return m.errors
+}
+
+// Close stops the monitor and waits for it to finish.
+func (m *SessionMonitor) Close() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+func (m *SessionMonitor) run() {
+	defer m.wg.Done()
+	defer close(m.events)
+	defer close(m.errors)
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	var last SessionState
+	for {
+		state, info, err := m.check()
+		if m.ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err != nil:
+			m.auth.log(m.ctx, slog.LevelWarn, "session check failed", "error", redact.String(err.Error()))
+			m.trySendError(err)
+		case state != last:
+			last = state
+			level := slog.LevelInfo
+			if state == SessionStateExpired {
+				level = slog.LevelWarn
+			}
+			m.auth.log(m.ctx, level, "session state changed", "state", state, "remaining", m.remaining())
+			m.trySendEvent(SessionEvent{
+				State:     state,
+				Info:      info,
+				Remaining: m.remaining(),
+				Time:      time.Now(),
+			})
+		}
+		if state == SessionStateExpired {
+			return
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check fetches the session info once. A transient failure returns an error
+// and an empty state; an expired session is discarded like in RestoreSession.
+func (m *SessionMonitor) check() (SessionState, *SessionInfo, error) {
+	info, err := m.auth.GetSessionInfo(m.ctx)
+	if err != nil {
+		if isSessionRejected(err) {
+			m.expire()
+			return SessionStateExpired, nil, nil
+		}
+		return "", nil, err
+	}
+	if !info.User.LoggedIn {
+		m.expire()
+		return SessionStateExpired, nil, nil
+	}
+
+	if m.cfg.MaxAge > 0 && m.remaining() <= m.cfg.ExpiringWithin {
+		return SessionStateExpiring, info, nil
+	}
+	return SessionStateActive, info, nil
+}
+
+func (m *SessionMonitor) expire() {
+	if err := m.auth.ClearSession(m.ctx); err != nil {
+		m.trySendError(err)
+	}
+}
+
+func (m *SessionMonitor) remaining() time.Duration {
+	if m.cfg.MaxAge <= 0 {
+		return 0
+	}
+	return max(time.Until(m.cfg.StartedAt.Add(m.cfg.MaxAge)), 0)
+}
+
+func (m *SessionMonitor) trySendEvent(event SessionEvent) {
+	select {
+	case m.events <- event:
+	case <-m.ctx.Done():
+	}
+}
+
+func (m *SessionMonitor) trySendError(err error) {
+	select {
+	case m.errors <- err:
+	default:
+	}
+}
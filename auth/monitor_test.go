@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func nextSessionEvent(t *testing.T, mon *SessionMonitor) SessionEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-mon.Events():
+		if !ok {
+			t.Fatal("events channel closed")
+		}
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for session event")
+	}
+	return SessionEvent{}
+}
+
+func TestMonitorSession_ActiveThenExpired(t *testing.T) {
+	var checks atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_api/authentication/session/info/session" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if checks.Add(1) <= 3 {
+			_ = json.NewEncoder(w).Encode(SessionInfo{User: User{LoggedIn: true}})
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{session: &Session{Cookies: map[string]string{"csid": "a"}}}
+	c := newTestClient(server.URL)
+	c.SetCookies(map[string]string{"csid": "a"})
+	service := NewAuthService(c, WithSessionStore(store))
+
+	mon := service.MonitorSession(context.Background(), &SessionMonitorConfig{Interval: 10 * time.Millisecond})
+	defer mon.Close()
+
+	if ev := nextSessionEvent(t, mon); ev.State != SessionStateActive || ev.Info == nil {
+		t.Fatalf("first event = %+v, want active with info", ev)
+	}
+	// Repeated active checks are not re-reported.
+	if ev := nextSessionEvent(t, mon); ev.State != SessionStateExpired {
+		t.Fatalf("second event = %+v, want expired", ev)
+	}
+
+	if _, ok := <-mon.Events(); ok {
+		t.Error("expected events channel to close after expiry")
+	}
+	if store.session != nil {
+		t.Error("expected expired session to be cleared from store")
+	}
+	if len(c.Cookies()) != 0 {
+		t.Error("expected client cookies to be cleared")
+	}
+	if got := checks.Load(); got != 4 {
+		t.Errorf("checks = %d, want 4", got)
+	}
+}
+
+func TestMonitorSession_Expiring(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(SessionInfo{User: User{LoggedIn: true}})
+	}))
+	defer server.Close()
+
+	service := NewAuthService(newTestClient(server.URL))
+	mon := service.MonitorSession(context.Background(), &SessionMonitorConfig{
+		Interval:       10 * time.Millisecond,
+		MaxAge:         time.Hour,
+		ExpiringWithin: 30 * time.Minute,
+		StartedAt:      time.Now().Add(-45 * time.Minute),
+	})
+	defer mon.Close()
+
+	ev := nextSessionEvent(t, mon)
+	if ev.State != SessionStateExpiring {
+		t.Fatalf("state = %s, want %s", ev.State, SessionStateExpiring)
+	}
+	if ev.Remaining <= 0 || ev.Remaining > 15*time.Minute {
+		t.Errorf("Remaining = %v, want (0, 15m]", ev.Remaining)
+	}
+}
+
+func TestMonitorSession_TransientErrors(t *testing.T) {
+	var checks atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if checks.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(SessionInfo{User: User{LoggedIn: true}})
+	}))
+	defer server.Close()
+
+	service := NewAuthService(newTestClient(server.URL))
+	mon := service.MonitorSession(context.Background(), &SessionMonitorConfig{Interval: 10 * time.Millisecond})
+	defer mon.Close()
+
+	select {
+	case err := <-mon.Errors():
+		if err == nil {
+			t.Fatal("expected non-nil error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for error")
+	}
+
+	if ev := nextSessionEvent(t, mon); ev.State != SessionStateActive {
+		t.Errorf("state = %s, want %s", ev.State, SessionStateActive)
+	}
+}
+
+func TestMonitorSession_Close(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(SessionInfo{User: User{LoggedIn: true}})
+	}))
+	defer server.Close()
+
+	service := NewAuthService(newTestClient(server.URL))
+	mon := service.MonitorSession(context.Background(), &SessionMonitorConfig{Interval: time.Hour})
+
+	nextSessionEvent(t, mon)
+
+	done := make(chan struct{})
+	go func() {
+		mon.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return")
+	}
+}
@@ -0,0 +1,283 @@
+// Package auth provides BankID authentication functionality for the Avanza API.
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mdp/qrterminal/v3"
+	"rsc.io/qr"
+)
+
+// QRPresenter shows a BankID QR code to the user. PollBankIDWithQRUpdates
+// calls PresentQR with a fresh token about once a second, so implementations
+// should replace what they showed last rather than append.
+type QRPresenter interface {
+	PresentQR(ctx context.Context, qrToken string) error
+}
+
+// QRPresenterFunc adapts a function to the QRPresenter interface.
+//
+//	presenter := auth.QRPresenterFunc(func(ctx context.Context, token string) error {
+//		png, err := auth.QRCodePNG(token)
+//		if err != nil {
+//			return err
+//		}
+//		return gui.ShowImage(png)
+//	})
+type QRPresenterFunc func(ctx context.Context, qrToken string) error
+
+// PresentQR calls f(ctx, qrToken).
+func (f QRPresenterFunc) PresentQR(ctx context.Context, qrToken string) error {
+	return f(ctx, qrToken)
+}
+
+// TerminalQRPresenter clears the terminal and renders the QR code with
+// half-block characters. It is the default presenter.
+type TerminalQRPresenter struct {
+	// Writer receives the output. Defaults to os.Stdout.
+	Writer io.Writer
+}
+
+// PresentQR renders the QR code to the terminal.
+func (p *TerminalQRPresenter) PresentQR(_ context.Context, qrToken string) error {
+	if qrToken == "" {
+		return fmt.Errorf("empty qr code data")
+	}
+
+	w := p.Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	fmt.Fprint(w, "\033[H\033[2J")
+	fmt.Fprintln(w, "Scan QR code with BankID app to authenticate to Avanza...")
+	qrterminal.GenerateHalfBlock(qrToken, qrterminal.L, w)
+	return nil
+}
+
+// QRImageFormat is an image format for FileQRPresenter.
+type QRImageFormat string
+
+const (
+	QRImagePNG QRImageFormat = "png" // Portable Network Graphics
+	QRImageSVG QRImageFormat = "svg" // Scalable Vector Graphics
+)
+
+// FileQRPresenter writes the QR code to an image file, replacing it atomically
+// on every refresh. Point an image viewer or a web server at Path.
+type FileQRPresenter struct {
+	// Path of the image file.
+	Path string
+
+	// Format of the image. Defaults to the format matching Path's extension,
+	// or PNG if the extension is not recognised.
+	Format QRImageFormat
+}
+
+// PresentQR writes the QR code image to Path.
+func (p *FileQRPresenter) PresentQR(_ context.Context, qrToken string) error {
+	if p.Path == "" {
+		return fmt.Errorf("qr image path is empty")
+	}
+
+	format := p.Format
+	if format == "" {
+		format = QRImagePNG
+		if strings.EqualFold(filepath.Ext(p.Path), ".svg") {
+			format = QRImageSVG
+		}
+	}
+
+	var img []byte
+	var err error
+	switch format {
+	case QRImagePNG:
+		img, err = QRCodePNG(qrToken)
+	case QRImageSVG:
+		img, err = QRCodeSVG(qrToken)
+	default:
+		return fmt.Errorf("unsupported qr image format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(p.Path, img)
+}
+
+// QRCodePNG encodes a BankID QR token as a PNG image.
+func QRCodePNG(qrToken string) ([]byte, error) {
+	code, err := encodeQR(qrToken)
+	if err != nil {
+		return nil, err
+	}
+	return code.PNG(), nil
+}
+
+// QRCodeSVG encodes a BankID QR token as an SVG image, one square per module
+// with a four-module quiet zone.
+func QRCodeSVG(qrToken string) ([]byte, error) {
+	code, err := encodeQR(qrToken)
+	if err != nil {
+		return nil, err
+	}
+
+	const quiet = 4
+	dim := code.Size + 2*quiet
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, dim, dim)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, dim, dim)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Black(x, y) {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+quiet, y+quiet)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes(), nil
+}
+
+func encodeQR(qrToken string) (*qr.Code, error) {
+	if qrToken == "" {
+		return nil, fmt.Errorf("empty qr code data")
+	}
+	code, err := qr.Encode(qrToken, qr.L)
+	if err != nil {
+		return nil, fmt.Errorf("encode qr code: %w", err)
+	}
+	return code, nil
+}
+
+// HTTPQRPresenter serves a web page showing the current QR code, so the login
+// can be completed from a browser on another machine. The page refreshes the
+// image every second.
+//
+// The page shows a live login QR code, so bind it to localhost or a trusted
+// network.
+//
+//	p, err := auth.NewHTTPQRPresenter("127.0.0.1:8765")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer p.Close()
+//	fmt.Println("open", p.URL())
+//	client := avanza.New(avanza.WithQRPresenter(p))
+type HTTPQRPresenter struct {
+	mu    sync.RWMutex
+	token string
+
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewHTTPQRPresenter listens on addr (e.g. "127.0.0.1:8765", or
+// "127.0.0.1:0" for a random port) and serves the QR page until Close.
+func NewHTTPQRPresenter(addr string) (*HTTPQRPresenter, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+
+	p := &HTTPQRPresenter{listener: ln}
+	p.server = &http.Server{
+		Handler:           p,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() { _ = p.server.Serve(ln) }()
+	return p, nil
+}
+
+// URL returns the address of the QR page.
+func (p *HTTPQRPresenter) URL() string {
+	if p.listener == nil {
+		return ""
+	}
+	return "http://" + p.listener.Addr().String() + "/"
+}
+
+// PresentQR replaces the QR code shown on the page.
+func (p *HTTPQRPresenter) PresentQR(_ context.Context, qrToken string) error {
+	if qrToken == "" {
+		return fmt.Errorf("empty qr code data")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.token = qrToken
+	return nil
+}
+
+// ServeHTTP serves the page at "/" and the current image at "/qr.svg".
+// It can also be mounted on an existing mux instead of using NewHTTPQRPresenter.
+func (p *HTTPQRPresenter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	switch r.URL.Path {
+	case "/", "":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = io.WriteString(w, qrPage)
+	case "/qr.svg":
+		p.mu.RLock()
+		token := p.token
+		p.mu.RUnlock()
+
+		if token == "" {
+			http.Error(w, "no login in progress", http.StatusNotFound)
+			return
+		}
+		img, err := QRCodeSVG(token)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "image/svg+xml")
+		_, _ = w.Write(img)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Close stops the HTTP server.
+func (p *HTTPQRPresenter) Close() error {
+	if p.server == nil {
+		return nil
+	}
+	if err := p.server.Close(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+const qrPage = `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Log in to Avanza with BankID</title>
+<style>
+body { font-family: sans-serif; text-align: center; margin-top: 3em; }
+img { width: 280px; height: 280px; image-rendering: pixelated; }
+</style>
+</head>
+<body>
+<p>Scan the QR code with the BankID app to log in to Avanza.</p>
+<img id="qr" alt="BankID QR code" src="qr.svg">
+<script>
+setInterval(function () {
+  document.getElementById("qr").src = "qr.svg?t=" + Date.now();
+}, 1000);
+</script>
+</body>
+</html>
+`
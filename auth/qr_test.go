@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTerminalQRPresenter_WritesToWriter(t *testing.T) {
+	var buf bytes.Buffer
+	p := &TerminalQRPresenter{Writer: &buf}
+
+	if err := p.PresentQR(context.Background(), "bankid.token.0.abc"); err != nil {
+		t.Fatalf("PresentQR: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "\033[H\033[2J") {
+		t.Error("expected output to start with clear-screen sequence")
+	}
+	if !strings.Contains(out, "Scan QR code") {
+		t.Error("expected scan instructions in output")
+	}
+}
+
+func TestQRPresenters_EmptyToken(t *testing.T) {
+	presenters := map[string]QRPresenter{
+		"terminal": &TerminalQRPresenter{Writer: io.Discard},
+		"file":     &FileQRPresenter{Path: filepath.Join(t.TempDir(), "qr.png")},
+		"http":     &HTTPQRPresenter{},
+	}
+	for name, p := range presenters {
+		t.Run(name, func(t *testing.T) {
+			if err := p.PresentQR(context.Background(), ""); err == nil {
+				t.Error("expected error for empty token")
+			}
+		})
+	}
+}
+
+func TestFileQRPresenter_Formats(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		path   string
+		format QRImageFormat
+		prefix string
+	}{
+		{"png by extension", filepath.Join(dir, "qr.png"), "", "\x89PNG"},
+		{"svg by extension", filepath.Join(dir, "qr.svg"), "", "<svg"},
+		{"explicit svg", filepath.Join(dir, "qr.img"), QRImageSVG, "<svg"},
+		{"unknown extension defaults to png", filepath.Join(dir, "qr"), "", "\x89PNG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &FileQRPresenter{Path: tt.path, Format: tt.format}
+			if err := p.PresentQR(context.Background(), "bankid.token.1.def"); err != nil {
+				t.Fatalf("PresentQR: %v", err)
+			}
+			raw, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if !strings.HasPrefix(string(raw), tt.prefix) {
+				t.Errorf("file starts with %q, want %q", raw[:min(len(raw), 8)], tt.prefix)
+			}
+		})
+	}
+}
+
+func TestFileQRPresenter_UnsupportedFormat(t *testing.T) {
+	p := &FileQRPresenter{Path: filepath.Join(t.TempDir(), "qr"), Format: "gif"}
+	if err := p.PresentQR(context.Background(), "token"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestHTTPQRPresenter_ServesCurrentCode(t *testing.T) {
+	p, err := NewHTTPQRPresenter("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewHTTPQRPresenter: %v", err)
+	}
+	defer p.Close()
+
+	resp, err := http.Get(p.URL() + "qr.svg")
+	if err != nil {
+		t.Fatalf("get qr before login: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status before PresentQR = %d, want 404", resp.StatusCode)
+	}
+
+	if err := p.PresentQR(context.Background(), "bankid.token.2.ghi"); err != nil {
+		t.Fatalf("PresentQR: %v", err)
+	}
+
+	resp, err = http.Get(p.URL() + "qr.svg")
+	if err != nil {
+		t.Fatalf("get qr: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "image/svg+xml" {
+		t.Errorf("Content-Type = %q, want image/svg+xml", got)
+	}
+	want, _ := QRCodeSVG("bankid.token.2.ghi")
+	if !bytes.Equal(body, want) {
+		t.Error("served image does not match current token")
+	}
+
+	resp, err = http.Get(p.URL())
+	if err != nil {
+		t.Fatalf("get page: %v", err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(page), `src="qr.svg"`) {
+		t.Error("page does not reference qr.svg")
+	}
+}
+
+func TestPollBankIDWithQRUpdates_UsesPresenter(t *testing.T) {
+	var mu sync.Mutex
+	collectCalls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/_api/authentication/v2/sessions/bankid/collect":
+			collectCalls++
+			state := BankIDStateOutstandingTransaction
+			if collectCalls >= 2 {
+				state = BankIDStateComplete
+			}
+			_ = json.NewEncoder(w).Encode(BankIDCollectResponse{State: state})
+		case "/_api/authentication/v2/sessions/bankid/restart":
+			_ = json.NewEncoder(w).Encode(BankIDStartResponse{QRToken: "fresh-token"})
+		}
+	}))
+	defer server.Close()
+
+	var presented []string
+	presenter := QRPresenterFunc(func(_ context.Context, token string) error {
+		presented = append(presented, token)
+		return nil
+	})
+	service := NewAuthService(newTestClient(server.URL), WithQRPresenter(presenter))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := service.PollBankIDWithQRUpdates(ctx); err != nil {
+		t.Fatalf("PollBankIDWithQRUpdates: %v", err)
+	}
+	if len(presented) != 1 || presented[0] != "fresh-token" {
+		t.Errorf("presented = %v, want [fresh-token]", presented)
+	}
+}
+
+func TestDisplayQRCode_UsesPresenter(t *testing.T) {
+	var got string
+	service := NewAuthService(newTestClient("http://unused"), WithQRPresenter(QRPresenterFunc(
+		func(_ context.Context, token string) error {
+			got = token
+			return nil
+		})))
+
+	if err := service.DisplayQRCode("initial-token"); err != nil {
+		t.Fatalf("DisplayQRCode: %v", err)
+	}
+	if got != "initial-token" {
+		t.Errorf("presented %q, want initial-token", got)
+	}
+}
@@ -0,0 +1,259 @@
+// Package auth provides BankID authentication functionality for the Avanza API.
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/client"
+)
+
+var (
+	// ErrNoSession is returned when a SessionStore holds no saved session.
+	ErrNoSession = errors.New("no saved session")
+
+	// ErrSessionExpired is returned when a restored session is no longer
+	// accepted by Avanza. The saved session is discarded; run the BankID flow again.
+	// It is the same value as client.ErrSessionExpired, so one errors.Is check
+	// also covers 401/403 responses from any other service.
+	ErrSessionExpired = client.ErrSessionExpired
+)
+
+// Session is the persisted state of an authenticated session: the cookies
+// captured at login (csid, cstoken, AZACSRF, ...) and the logins offered by
+// BankID, so SwitchLogin keeps working after RestoreSession.
+type Session struct {
+	Cookies map[string]string `json:"cookies"`
+	SavedAt time.Time         `json:"savedAt"`
+	Logins  []Login           `json:"logins,omitempty"`
+}
+
+// SessionStore persists a session across process restarts.
+// Implementations must be safe for concurrent use.
+type SessionStore interface {
+	// Load returns the saved session, or ErrNoSession if there is none.
+	Load(ctx context.Context) (*Session, error)
+	// Save persists the session, replacing any previous one.
+	Save(ctx context.Context, session *Session) error
+	// Clear removes the saved session. Clearing an empty store is not an error.
+	Clear(ctx context.Context) error
+}
+
+const (
+	sessionFileVersion = 1
+	saltSize           = 16
+	keySize            = 32
+)
+
+// pbkdf2Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256.
+// It is a variable only so tests can lower it.
+var pbkdf2Iterations = 600_000
+
+// FileSessionStore saves the session to a single file, encrypted with
+// AES-256-GCM under a key derived from a passphrase. The file is written
+// with 0600 permissions and replaced atomically.
+//
+//	store := auth.NewFileSessionStore("~/.config/mybot/session", os.Getenv("SESSION_PASSPHRASE"))
+//	client := avanza.New(avanza.WithSessionStore(store))
+type FileSessionStore struct {
+	path       string
+	passphrase []byte
+	mu         sync.Mutex
+}
+
+// NewFileSessionStore creates a store that keeps the session at path,
+// encrypted with passphrase.
+func NewFileSessionStore(path, passphrase string) *FileSessionStore {
+	return &FileSessionStore{
+		path:       path,
+		passphrase: []byte(passphrase),
+	}
+}
+
+// sessionFile is the on-disk envelope. Ciphertext is the GCM-sealed JSON Session.
+type sessionFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Load decrypts and returns the saved session.
+func (f *FileSessionStore) Load(_ context.Context) (*Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	raw, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoSession
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read session file: %w", err)
+	}
+
+	var file sessionFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("decode session file: %w", err)
+	}
+	if file.Version != sessionFileVersion {
+		return nil, fmt.Errorf("unsupported session file version %d", file.Version)
+	}
+
+	gcm, err := f.cipher(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("decode session file: invalid nonce")
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt session: wrong passphrase or corrupted file")
+	}
+
+	var session Session
+	if err := json.Unmarshal(plaintext, &session); err != nil {
+		return nil, fmt.Errorf("decode session: %w", err)
+	}
+	return &session, nil
+}
+
+// Save encrypts and writes the session, replacing any previous file.
+func (f *FileSessionStore) Save(_ context.Context, session *Session) error {
+	if session == nil {
+		return fmt.Errorf("session is required")
+	}
+
+	plaintext, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("encode session: %w", err)
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("generate salt: %w", err)
+	}
+	gcm, err := f.cipher(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+
+	raw, err := json.Marshal(sessionFile{
+		Version:    sessionFileVersion,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("encode session file: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return writeFileAtomic(f.path, raw)
+}
+
+// Clear deletes the session file.
+func (f *FileSessionStore) Clear(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove session file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileSessionStore) cipher(salt []byte) (cipher.AEAD, error) {
+	if len(f.passphrase) == 0 {
+		return nil, fmt.Errorf("session passphrase is empty")
+	}
+	if len(salt) != saltSize {
+		return nil, fmt.Errorf("decode session file: invalid salt")
+	}
+	block, err := aes.NewCipher(pbkdf2SHA256(f.passphrase, salt, pbkdf2Iterations, keySize))
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it over path, so a crash never leaves a half-written session.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create session directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create session file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if err := tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("chmod session file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write session file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close session file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace session file: %w", err)
+	}
+	return nil
+}
+
+// pbkdf2SHA256 derives a key per RFC 8018 with HMAC-SHA256 as the PRF.
+// crypto/pbkdf2 only joined the standard library in Go 1.24.
+func pbkdf2SHA256(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var buf [4]byte
+	dk := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= numBlocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf[:], uint32(block))
+		prf.Write(buf[:])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+
+		for n := 2; n <= iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = u[:0]
+			u = prf.Sum(u)
+			for i := range u {
+				t[i] ^= u[i]
+			}
+		}
+	}
+	return dk[:keyLen]
+}
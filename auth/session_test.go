@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func init() {
+	// Full-strength key derivation makes every Save/Load take hundreds of ms.
+	pbkdf2Iterations = 1000
+}
+
+func TestPBKDF2SHA256_KnownAnswer(t *testing.T) {
+	// RFC 7914 section 11, PBKDF2-HMAC-SHA256 test vector.
+	want, _ := hex.DecodeString("55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783")
+	got := pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64)
+	if !bytes.Equal(got, want) {
+		t.Errorf("pbkdf2SHA256 = %x, want %x", got, want)
+	}
+}
+
+func TestFileSessionStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "session")
+	store := NewFileSessionStore(path, "correct horse")
+	ctx := context.Background()
+
+	saved := &Session{
+		Cookies: map[string]string{"csid": "c1", "cstoken": "t1", "AZACSRF": "x1"},
+		SavedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if err := store.Save(ctx, saved); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file mode = %o, want 600", perm)
+	}
+
+	raw, _ := os.ReadFile(path)
+	if strings.Contains(string(raw), "cstoken") || strings.Contains(string(raw), "t1") {
+		t.Error("session file contains plaintext cookie data")
+	}
+
+	loaded, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Cookies["cstoken"] != "t1" || loaded.Cookies["AZACSRF"] != "x1" {
+		t.Errorf("cookies = %v, want %v", loaded.Cookies, saved.Cookies)
+	}
+	if !loaded.SavedAt.Equal(saved.SavedAt) {
+		t.Errorf("SavedAt = %v, want %v", loaded.SavedAt, saved.SavedAt)
+	}
+}
+
+func TestFileSessionStore_WrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session")
+	ctx := context.Background()
+
+	if err := NewFileSessionStore(path, "right").Save(ctx, &Session{Cookies: map[string]string{"csid": "a"}}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	_, err := NewFileSessionStore(path, "wrong").Load(ctx)
+	if err == nil {
+		t.Fatal("expected error for wrong passphrase")
+	}
+	if errors.Is(err, ErrNoSession) {
+		t.Error("wrong passphrase should not look like a missing session")
+	}
+}
+
+func TestFileSessionStore_MissingAndClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session")
+	store := NewFileSessionStore(path, "pw")
+	ctx := context.Background()
+
+	if _, err := store.Load(ctx); !errors.Is(err, ErrNoSession) {
+		t.Fatalf("Load on empty store = %v, want ErrNoSession", err)
+	}
+	if err := store.Clear(ctx); err != nil {
+		t.Fatalf("Clear on empty store: %v", err)
+	}
+
+	if err := store.Save(ctx, &Session{Cookies: map[string]string{"csid": "a"}}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Clear(ctx); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if _, err := store.Load(ctx); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Load after Clear = %v, want ErrNoSession", err)
+	}
+}
+
+func TestFileSessionStore_EmptyPassphrase(t *testing.T) {
+	store := NewFileSessionStore(filepath.Join(t.TempDir(), "session"), "")
+	if err := store.Save(context.Background(), &Session{}); err == nil {
+		t.Error("expected error for empty passphrase")
+	}
+}
+
+// memoryStore is an in-memory SessionStore for exercising AuthService.
+type memoryStore struct {
+	session *Session
+	saves   atomic.Int32
+	clears  atomic.Int32
+}
+
+func (m *memoryStore) Load(context.Context) (*Session, error) {
+	if m.session == nil {
+		return nil, ErrNoSession
+	}
+	return m.session, nil
+}
+
+func (m *memoryStore) Save(_ context.Context, s *Session) error {
+	m.saves.Add(1)
+	m.session = s
+	return nil
+}
+
+func (m *memoryStore) Clear(context.Context) error {
+	m.clears.Add(1)
+	m.session = nil
+	return nil
+}
+
+func TestEstablishSession_SavesToStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == testLoginPath {
+			http.SetCookie(w, &http.Cookie{Name: "csid", Value: "c1"})
+			http.SetCookie(w, &http.Cookie{Name: "AZACSRF", Value: "x1"})
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	store := &memoryStore{}
+	service := NewAuthService(newTestClient(server.URL), WithSessionStore(store))
+
+	err := service.EstablishSession(context.Background(), &BankIDCollectResponse{
+		Logins: []Login{{LoginPath: testLoginPath}},
+	})
+	if err != nil {
+		t.Fatalf("EstablishSession: %v", err)
+	}
+
+	if store.saves.Load() != 1 {
+		t.Fatalf("saves = %d, want 1", store.saves.Load())
+	}
+	if got := store.session.Cookies["csid"]; got != "c1" {
+		t.Errorf("saved csid = %q, want c1", got)
+	}
+	if store.session.SavedAt.IsZero() {
+		t.Error("expected SavedAt to be set")
+	}
+}
+
+func TestRestoreSession_Valid(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-SecurityToken"); got != "x1" {
+			t.Errorf("X-SecurityToken = %q, want x1", got)
+		}
+		_ = json.NewEncoder(w).Encode(SessionInfo{User: User{LoggedIn: true, GreetingName: "Anna"}})
+	}))
+	defer server.Close()
+
+	store := &memoryStore{session: &Session{Cookies: map[string]string{"csid": "c1", "AZACSRF": "x1"}}}
+	c := newTestClient(server.URL)
+	service := NewAuthService(c, WithSessionStore(store))
+
+	info, err := service.RestoreSession(context.Background())
+	if err != nil {
+		t.Fatalf("RestoreSession: %v", err)
+	}
+	if info.User.GreetingName != "Anna" {
+		t.Errorf("GreetingName = %q, want Anna", info.User.GreetingName)
+	}
+	if c.Cookies()["csid"] != "c1" {
+		t.Error("expected restored cookies on client")
+	}
+	if store.clears.Load() != 0 {
+		t.Error("valid session should not be cleared")
+	}
+}
+
+func TestRestoreSession_Expired(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"unauthorized", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}},
+		{"not logged in", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(SessionInfo{User: User{LoggedIn: false}})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			store := &memoryStore{session: &Session{Cookies: map[string]string{"csid": "old"}}}
+			c := newTestClient(server.URL)
+			service := NewAuthService(c, WithSessionStore(store))
+
+			_, err := service.RestoreSession(context.Background())
+			if !errors.Is(err, ErrSessionExpired) {
+				t.Fatalf("err = %v, want ErrSessionExpired", err)
+			}
+			if store.session != nil {
+				t.Error("expected expired session to be cleared from store")
+			}
+			if len(c.Cookies()) != 0 {
+				t.Errorf("expected client cookies to be cleared, got %v", c.Cookies())
+			}
+		})
+	}
+}
+
+func TestRestoreSession_TransientErrorKeepsStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{session: &Session{Cookies: map[string]string{"csid": "c1"}}}
+	service := NewAuthService(newTestClient(server.URL), WithSessionStore(store))
+
+	_, err := service.RestoreSession(context.Background())
+	if err == nil || errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("err = %v, want non-expiry error", err)
+	}
+	if store.session == nil {
+		t.Error("transient failure should keep the saved session")
+	}
+}
+
+func TestRestoreSession_NoStore(t *testing.T) {
+	service := NewAuthService(newTestClient("http://unused"))
+	if _, err := service.RestoreSession(context.Background()); err == nil {
+		t.Error("expected error without a session store")
+	}
+}
+
+func TestRestoreSession_NothingSaved(t *testing.T) {
+	service := NewAuthService(newTestClient("http://unused"), WithSessionStore(&memoryStore{}))
+	if _, err := service.RestoreSession(context.Background()); !errors.Is(err, ErrNoSession) {
+		t.Errorf("err = %v, want ErrNoSession", err)
+	}
+}
@@ -34,6 +34,15 @@
 //		fmt.Printf("HTTP %d: %s\n", httpErr.StatusCode, httpErr.Body)
 //	}
 //
+//	if errors.Is(err, client.ErrSessionExpired) {
+//		// 401/403: run the BankID flow again
+//	}
+//
+//	var rejected *trading.OrderRejectedError
+//	if errors.As(err, &rejected) {
+//		fmt.Println(rejected.Message, rejected.Parameters)
+//	}
+//
 // Session lifetime:
 //
 // Session cookies captured at login are held for the life of the process and
@@ -43,18 +52,46 @@
 // is no automatic re-authentication — since login requires a human to scan a
 // BankID QR code, callers must detect the expired session and run the auth
 // flow again.
+//
+// Auth.MonitorSession checks the session in the background, which also keeps
+// it from idling out, and reports auth.SessionStateExpired as soon as Avanza
+// rejects it — before the next trade fails:
+//
+//	mon := client.Auth.MonitorSession(ctx, nil)
+//	defer mon.Close()
+//	go func() {
+//		for ev := range mon.Events() {
+//			log.Printf("session %s", ev.State)
+//		}
+//	}()
+//
+// With WithSessionStore the cookies are saved after EstablishSession, and
+// Auth.RestoreSession resumes them on the next start for as long as Avanza
+// still accepts the session:
+//
+//	client := avanza.New(avanza.WithSessionStore(auth.NewFileSessionStore(path, passphrase)))
+//	if _, err := client.Auth.RestoreSession(ctx); err != nil {
+//		// auth.ErrNoSession or auth.ErrSessionExpired: run the BankID flow
+//	}
 package avanza
 
 import (
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/vmorsell/avanza-sdk-go/accounts"
 	"github.com/vmorsell/avanza-sdk-go/auth"
+	"github.com/vmorsell/avanza-sdk-go/cache"
 	"github.com/vmorsell/avanza-sdk-go/client"
 	"github.com/vmorsell/avanza-sdk-go/market"
 	"github.com/vmorsell/avanza-sdk-go/trading"
 )
 
+// orderbookCacheSize is how many orderbooks WithTickSizeCheck keeps when no
+// market cache is configured.
+const orderbookCacheSize = 1000
+
 // Avanza is the main client for the Avanza API.
 type Avanza struct {
 	client   *client.Client
@@ -69,7 +106,11 @@ type Option func(*config)
 
 // config collects all options before building the client.
 type config struct {
-	clientOpts []client.Option
+	clientOpts    []client.Option
+	authOpts      []auth.Option
+	marketOpts    []market.Option
+	marketCache   bool
+	tickSizeCheck bool
 }
 
 // WithBaseURL sets a custom base URL. Useful for testing.
@@ -101,7 +142,8 @@ func WithUserAgent(userAgent string) Option {
 }
 
 // WithRateLimiter sets a rate limiter. Defaults to 100ms interval.
-// Pass nil to disable (not recommended).
+// Pass nil to disable (not recommended). client.NewTokenBucketLimiter adds
+// bursts, per-endpoint budgets, order priority and 429 backoff.
 //
 //	limiter := &client.SimpleRateLimiter{Interval: 200 * time.Millisecond}
 //	client := avanza.New(avanza.WithRateLimiter(limiter))
@@ -111,6 +153,140 @@ func WithRateLimiter(limiter client.RateLimiter) Option {
 	}
 }
 
+// WithRetryPolicy retries failed requests. Retries are off by default.
+//
+//	client := avanza.New(avanza.WithRetryPolicy(client.DefaultRetryPolicy()))
+func WithRetryPolicy(policy *client.RetryPolicy) Option {
+	return func(c *config) {
+		c.clientOpts = append(c.clientOpts, client.WithRetryPolicy(policy))
+	}
+}
+
+// WithMiddleware adds middleware that sees every request and response,
+// including SSE connects, e.g. for logging, metrics or fault injection.
+//
+//	client := avanza.New(avanza.WithMiddleware(logRequests))
+func WithMiddleware(middleware ...client.Middleware) Option {
+	return func(c *config) {
+		c.clientOpts = append(c.clientOpts, client.WithMiddleware(middleware...))
+	}
+}
+
+// WithLogger logs requests, responses, BankID and session state changes, and
+// SSE reconnects to logger. Cookie values, the security token, BankID tokens
+// and personal identity numbers are always redacted. Request details are
+// logged at debug level.
+//
+//	client := avanza.New(avanza.WithLogger(slog.Default()))
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.clientOpts = append(c.clientOpts, client.WithLogger(logger))
+		c.authOpts = append(c.authOpts, auth.WithLogger(logger))
+	}
+}
+
+// WithStreamObserver reports connects, disconnects and reconnects of SSE
+// subscriptions, e.g. for tracing with otelavanza.StreamObserver.
+//
+//	client := avanza.New(avanza.WithStreamObserver(otelavanza.StreamObserver()))
+func WithStreamObserver(observers ...client.StreamObserver) Option {
+	return func(c *config) {
+		c.clientOpts = append(c.clientOpts, client.WithStreamObserver(observers...))
+	}
+}
+
+// WithDriftHandler checks every response and SSE event against the SDK's
+// types and calls handler once for each new, missing or retyped field, so
+// Avanza's API changes show up before they break anything. It is off by
+// default because it buffers response bodies.
+//
+//	client := avanza.New(avanza.WithDriftHandler(func(d client.Drift) {
+//		log.Printf("schema drift: %s %s", d.Endpoint, d.Issue)
+//	}))
+func WithDriftHandler(handler client.DriftHandler) Option {
+	return func(c *config) {
+		c.clientOpts = append(c.clientOpts, client.WithDriftHandler(handler))
+	}
+}
+
+// WithDriftLogger reports schema drift, as WithDriftHandler does, to logger
+// at warn level.
+//
+//	client := avanza.New(avanza.WithDriftLogger(slog.Default()))
+func WithDriftLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.clientOpts = append(c.clientOpts, client.WithDriftLogger(logger))
+	}
+}
+
+// WithMarketCache caches slow-changing market data (order book rules and
+// instrument details) in store. ttls overrides market.DefaultCacheTTLs per
+// endpoint; pass nil for the defaults. Drop entries with
+// Market.InvalidateCache or Market.ClearCache.
+//
+//	client := avanza.New(avanza.WithMarketCache(cache.NewLRU(1000), nil))
+func WithMarketCache(store cache.Store, ttls map[market.CachedEndpoint]time.Duration) Option {
+	return func(c *config) {
+		c.marketOpts = append(c.marketOpts, market.WithCache(store, ttls))
+		c.marketCache = true
+	}
+}
+
+// WithTickSizeCheck makes Trading.PlaceOrder and Trading.ModifyOrder check
+// prices against the orderbook's tick size table, fetched with
+// Market.GetOrderbook, and fail with *trading.TickSizeError instead of sending
+// an off-tick order. Unless WithMarketCache is set, orderbooks are cached in
+// memory for market.DefaultCacheTTLs[market.CacheOrderbook].
+//
+//	client := avanza.New(avanza.WithTickSizeCheck())
+func WithTickSizeCheck() Option {
+	return func(c *config) {
+		c.tickSizeCheck = true
+	}
+}
+
+// WithSessionStore persists the session after login so it survives restarts.
+// Call Auth.RestoreSession at startup and fall back to BankID when it fails.
+//
+//	store := auth.NewFileSessionStore("session.enc", os.Getenv("AVANZA_SESSION_KEY"))
+//	client := avanza.New(avanza.WithSessionStore(store))
+func WithSessionStore(store auth.SessionStore) Option {
+	return func(c *config) {
+		c.authOpts = append(c.authOpts, auth.WithSessionStore(store))
+	}
+}
+
+// WithQRPresenter sets how BankID QR codes are shown during login, e.g. an
+// image file or a local web page instead of the terminal.
+//
+//	presenter, _ := auth.NewHTTPQRPresenter("127.0.0.1:8765")
+//	client := avanza.New(avanza.WithQRPresenter(presenter))
+func WithQRPresenter(presenter auth.QRPresenter) Option {
+	return func(c *config) {
+		c.authOpts = append(c.authOpts, auth.WithQRPresenter(presenter))
+	}
+}
+
+// WithBankIDProgress reports BankID state changes while polling, e.g. to tell
+// the user to open the app or confirm the login.
+//
+//	client := avanza.New(avanza.WithBankIDProgress(func(p auth.BankIDProgress) {
+//		log.Printf("bankid: %s (%s)", p.State, p.HintCode)
+//	}))
+func WithBankIDProgress(fn func(auth.BankIDProgress)) Option {
+	return func(c *config) {
+		c.authOpts = append(c.authOpts, auth.WithBankIDProgress(fn))
+	}
+}
+
+// WithMaxBankIDRestarts sets how many expired BankID transactions are replaced
+// with new ones while polling. Defaults to auth.DefaultMaxBankIDRestarts.
+func WithMaxBankIDRestarts(n int) Option {
+	return func(c *config) {
+		c.authOpts = append(c.authOpts, auth.WithMaxBankIDRestarts(n))
+	}
+}
+
 // New creates a new Avanza client.
 //
 //	client := avanza.New()
@@ -123,11 +299,25 @@ func New(opts ...Option) *Avanza {
 
 	c := client.NewClient(cfg.clientOpts...)
 
+	if cfg.tickSizeCheck && !cfg.marketCache {
+		cfg.marketOpts = append(cfg.marketOpts, market.WithCache(cache.NewLRU(orderbookCacheSize), map[market.CachedEndpoint]time.Duration{
+			market.CacheStockDetails:       0,
+			market.CacheCertificateDetails: 0,
+			market.CacheWarrantDetails:     0,
+		}))
+	}
+	mkt := market.NewService(c, cfg.marketOpts...)
+
+	tradingOpts := []trading.Option{trading.WithOrderbookSource(mkt)}
+	if cfg.tickSizeCheck {
+		tradingOpts = append(tradingOpts, trading.WithTickSizeCheck(mkt))
+	}
+
 	return &Avanza{
 		client:   c,
-		Auth:     auth.NewAuthService(c),
+		Auth:     auth.NewAuthService(c, cfg.authOpts...),
 		Accounts: accounts.NewService(c),
-		Trading:  trading.NewService(c),
-		Market:   market.NewService(c),
+		Trading:  trading.NewService(c, tradingOpts...),
+		Market:   mkt,
 	}
 }
@@ -1,11 +1,16 @@
 package avanza
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/trading"
 )
 
 func TestNew_DefaultClient(t *testing.T) {
@@ -83,3 +88,44 @@ func TestNew_SingleOption(t *testing.T) {
 		t.Errorf("UserAgent should be default, got %q", got)
 	}
 }
+
+func TestNew_WithTickSizeCheck(t *testing.T) {
+	var orderbookGets, placed int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_api/trading-critical/rest/orderbook/5247":
+			orderbookGets++
+			_, _ = w.Write([]byte(`{"id":"5247","tickSizeList":{"tickSizeEntries":[{"min":0,"max":499.95,"tick":0.05}]}}`))
+		case "/_api/trading-critical/rest/order/new":
+			placed++
+			_, _ = w.Write([]byte(`{"orderRequestStatus":"SUCCESS","orderId":"1"}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	a := New(WithBaseURL(server.URL), WithRateLimiter(nil), WithTickSizeCheck())
+	place := func(price string) error {
+		_, err := a.Trading.PlaceOrder(context.Background(), &trading.PlaceOrderRequest{
+			AccountID:   "12345",
+			OrderbookID: "5247",
+			Price:       decimal.MustParse(price),
+			Volume:      1,
+			Side:        trading.OrderSideBuy,
+			Condition:   trading.OrderConditionNormal,
+		})
+		return err
+	}
+
+	var tickErr *trading.TickSizeError
+	if err := place("245.52"); !errors.As(err, &tickErr) {
+		t.Fatalf("err = %v, want *trading.TickSizeError", err)
+	}
+	if err := place("245.55"); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if orderbookGets != 1 || placed != 1 {
+		t.Errorf("orderbook fetches = %d, orders = %d, want 1 and 1", orderbookGets, placed)
+	}
+}
@@ -0,0 +1,233 @@
+package avanzatest
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/vmorsell/avanza-sdk-go/accounts"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/trading"
+)
+
+// BuyingPower returns what the account can buy for: its cash less what open
+// buy orders hold.
+func (s *Server) BuyingPower(accountID string) decimal.Decimal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.accounts[accountID]; ok {
+		return s.buyingPower(a)
+	}
+	return decimal.Decimal{}
+}
+
+// Holding returns the volume of orderbookID held in the account.
+func (s *Server) Holding(accountID, orderbookID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.accounts[accountID]; ok {
+		if p, ok := a.positions[orderbookID]; ok {
+			return p.volume
+		}
+	}
+	return 0
+}
+
+// sortedAccounts returns the accounts in the order they were configured.
+// The caller must hold s.mu.
+func (s *Server) sortedAccounts() []*account {
+	out := make([]*account, 0, len(s.accounts))
+	for _, a := range s.accountList {
+		out = append(out, s.accounts[a.ID])
+	}
+	return out
+}
+
+func (s *Server) handleOverview(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	overview := accounts.AccountOverview{
+		Categories: []accounts.Category{},
+		Accounts:   []accounts.Account{},
+		Loans:      []accounts.Loan{},
+	}
+	for _, a := range s.sortedAccounts() {
+		total := a.Cash.Add(s.holdingsValue(a))
+		overview.Accounts = append(overview.Accounts, accounts.Account{
+			ID:                       a.ID,
+			CategoryID:               "1",
+			Balance:                  sek(a.Cash),
+			Type:                     a.Type,
+			TotalValue:               sek(total),
+			BuyingPower:              sek(s.buyingPower(a)),
+			BuyingPowerWithoutCredit: sek(s.buyingPower(a)),
+			Name:                     accounts.AccountName{DefaultName: a.Name},
+			Status:                   "ACTIVE",
+			CurrencyBalances:         []accounts.Money{sek(a.Cash)},
+			Overdrawn:                []any{},
+			URLParameterID:           a.urlParameterID,
+			Owner:                    true,
+		})
+	}
+	writeJSON(w, overview)
+}
+
+func (s *Server) handleTradingAccounts(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := []accounts.TradingAccount{}
+	for _, a := range s.sortedAccounts() {
+		out = append(out, accounts.TradingAccount{
+			Name:                              a.Name,
+			AccountID:                         a.ID,
+			AccountTypeName:                   a.Type,
+			AccountType:                       a.Type,
+			AvailableForPurchase:              s.buyingPower(a),
+			AvailableForPurchaseWithoutCredit: s.buyingPower(a),
+			IsTradable:                        true,
+			Positions:                         []any{},
+			CurrencyBalances:                  []accounts.CurrencyBalance{{Currency: "SEK", CountryCode: "SE", Balance: a.Cash}},
+			URLParameterID:                    a.urlParameterID,
+		})
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) handlePositions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.accountByURLParameterID(r.PathValue("account"))
+	if a == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	info := accounts.AccountInfo{ID: a.ID, Type: a.Type, Name: a.Name, URLParameterID: a.urlParameterID}
+	out := accounts.AccountPositions{
+		WithOrderbook:    []accounts.AccountPosition{},
+		WithoutOrderbook: []any{},
+		CashPositions:    []accounts.CashPosition{{Account: info, TotalBalance: sek(a.Cash), ID: a.ID}},
+	}
+
+	ids := make([]string, 0, len(a.positions))
+	for id, p := range a.positions {
+		if _, known := s.instruments[id]; known && p.volume > 0 {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		p := a.positions[id]
+		inst := s.instruments[id]
+		out.WithOrderbook = append(out.WithOrderbook, accounts.AccountPosition{
+			Account: info,
+			Instrument: accounts.Instrument{
+				ID:       inst.OrderbookID,
+				Type:     inst.Type,
+				Name:     inst.Name,
+				Currency: inst.Currency,
+				ISIN:     inst.ISIN,
+				Orderbook: accounts.Orderbook{
+					ID:          inst.OrderbookID,
+					FlagCode:    "SE",
+					Name:        inst.Name,
+					Type:        inst.Type,
+					TradeStatus: "BUYABLE_AND_SELLABLE",
+					Quote:       accounts.Quote{Latest: money(inst.Price, inst.Currency)},
+				},
+				VolumeFactor: 1,
+			},
+			ID:                   a.ID + "_" + id,
+			Volume:               accounts.Money{Value: decimal.NewFromInt(int64(p.volume)), UnitType: "NUMBER"},
+			Value:                sek(inst.Price.Mul(decimal.NewFromInt(int64(p.volume)))),
+			AverageAcquiredPrice: sek(p.avgPrice),
+			AcquiredValue:        sek(p.avgPrice.Mul(decimal.NewFromInt(int64(p.volume)))),
+		})
+	}
+	writeJSON(w, out)
+}
+
+// holdingsValue is the market value of the account's positions. The caller
+// must hold s.mu.
+func (s *Server) holdingsValue(a *account) decimal.Decimal {
+	var total decimal.Decimal
+	for id, p := range a.positions {
+		if inst, ok := s.instruments[id]; ok {
+			total = total.Add(inst.Price.Mul(decimal.NewFromInt(int64(p.volume))))
+		}
+	}
+	return total
+}
+
+func sek(v decimal.Decimal) accounts.Money {
+	return money(v, "SEK")
+}
+
+func money(v decimal.Decimal, currency string) accounts.Money {
+	return accounts.Money{Value: v, Unit: currency, UnitType: "MONETARY", DecimalPrecision: 2}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// trade is a fill, as the transactions endpoint lists it.
+type trade struct {
+	id          string
+	accountID   string
+	orderbookID string
+	side        trading.OrderSide
+	volume      int
+	price       decimal.Decimal
+	date        string // e.g. "2026-10-16"
+}
+
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, to, accountIDs := q.Get("from"), q.Get("to"), q["accountIds"]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := accounts.TransactionsResponse{Transactions: []accounts.Transaction{}}
+	// Newest first, as Avanza lists them.
+	for _, t := range slices.Backward(s.trades) {
+		a := s.accounts[t.accountID]
+		if t.date < from || t.date > to || (len(accountIDs) > 0 && !slices.Contains(accountIDs, a.urlParameterID)) {
+			continue
+		}
+		inst := s.instruments[t.orderbookID]
+		value := t.price.Mul(decimal.NewFromInt(int64(t.volume)))
+		description, amount := "Köp "+inst.Name, value.Neg()
+		if t.side == trading.OrderSideSell {
+			description, amount = "Sälj "+inst.Name, value
+		}
+		out.Transactions = append(out.Transactions, accounts.Transaction{
+			ID:        t.id,
+			Date:      t.date,
+			TradeDate: &t.date,
+			Account:   accounts.TransactionAccount{ID: a.ID, Name: a.Name, Type: a.Type, URLParameterID: a.urlParameterID},
+			Orderbook: &accounts.TransactionOrderbook{
+				ID:          inst.OrderbookID,
+				FlagCode:    "SE",
+				Name:        inst.Name,
+				Marketplace: "XSTO",
+				Type:        inst.Type,
+				Currency:    inst.Currency,
+				ISIN:        inst.ISIN,
+			},
+			InstrumentName:        &inst.Name,
+			Description:           description,
+			Type:                  string(t.side),
+			Volume:                &accounts.Money{Value: decimal.NewFromInt(int64(t.volume)), UnitType: "NUMBER"},
+			PriceInTradedCurrency: ptr(money(t.price, inst.Currency)),
+			Amount:                ptr(sek(amount)),
+			Intraday:              true,
+			ISIN:                  &inst.ISIN,
+		})
+	}
+	out.TransactionsAfterFiltering = len(out.Transactions)
+	writeJSON(w, out)
+}
@@ -0,0 +1,218 @@
+package avanzatest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/auth"
+)
+
+// bankIDTTL is how long a BankID transaction or QR code is valid.
+const bankIDTTL = 30 * time.Second
+
+// bankIDTransaction is a BankID login in progress.
+type bankIDTransaction struct {
+	id string
+}
+
+// session is a logged-in session, identified by its cookies.
+type session struct {
+	csid          string
+	cstoken       string
+	securityToken string
+}
+
+func newSession() *session {
+	return &session{csid: randomToken(), cstoken: randomToken(), securityToken: randomToken()}
+}
+
+func (s *session) cookies() map[string]string {
+	return map[string]string{"csid": s.csid, "cstoken": s.cstoken, "AZACSRF": s.securityToken}
+}
+
+func (s *session) valid(r *http.Request) bool {
+	csid, err := r.Cookie("csid")
+	return err == nil && csid.Value == s.csid
+}
+
+// ExpireSession ends the current session, as Avanza does after inactivity:
+// later requests get 401 until the client logs in again.
+func (s *Server) ExpireSession() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session = nil
+}
+
+// SessionStore returns a store holding a live session, so a client created
+// WithSessionStore can call RestoreSession instead of running BankID.
+func (s *Server) SessionStore() auth.SessionStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.session = newSession()
+	return &memoryStore{session: &auth.Session{
+		Cookies: s.session.cookies(),
+		SavedAt: time.Now(),
+		Logins:  []auth.Login{s.login("restored")},
+	}}
+}
+
+func (s *Server) handleStartPage(w http.ResponseWriter, _ *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: "AZAPERSISTENCE", Value: randomToken(), Path: "/"})
+}
+
+func (s *Server) handleBankIDStart(w http.ResponseWriter, r *http.Request) {
+	var req auth.BankIDStartRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	s.tx = &bankIDTransaction{id: randomToken()}
+	resp := s.bankIDStart(req.Method == auth.BankIDMethodSameDevice)
+	s.mu.Unlock()
+
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleBankIDRestart(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tx == nil {
+		http.Error(w, `{"message":"no bankid transaction"}`, http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, s.bankIDStart(false))
+}
+
+// bankIDStart describes the current transaction with a fresh QR token.
+// The caller must hold s.mu.
+func (s *Server) bankIDStart(sameDevice bool) auth.BankIDStartResponse {
+	resp := auth.BankIDStartResponse{
+		TransactionID: s.tx.id,
+		Expires:       time.Now().Add(bankIDTTL).UTC().Format(time.RFC3339Nano),
+		QRToken:       "bankid." + randomToken(),
+	}
+	if sameDevice {
+		resp.AutoStartToken = randomToken()
+	}
+	return resp
+}
+
+func (s *Server) handleBankIDCollect(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tx == nil {
+		http.Error(w, `{"message":"no bankid transaction"}`, http.StatusBadRequest)
+		return
+	}
+
+	state := s.bankIDStates[min(s.bankIDStep, len(s.bankIDStates)-1)]
+	s.bankIDStep++
+
+	resp := auth.BankIDCollectResponse{
+		TransactionID:              s.tx.id,
+		State:                      state,
+		HintCode:                   hintCodes[state],
+		RecommendedTargetCustomers: []any{},
+		Poa:                        auth.Poa{Letters: []any{}},
+	}
+	if state == auth.BankIDStateComplete {
+		resp.Name = "Test Testsson"
+		resp.IdentificationNumber = "199001011234"
+		resp.Logins = []auth.Login{s.login(s.tx.id)}
+	}
+	writeJSON(w, resp)
+}
+
+// hintCodes is the hint BankID gives with each state.
+var hintCodes = map[auth.BankIDState]auth.HintCode{
+	auth.BankIDStateOutstandingTransaction: auth.HintOutstandingTransaction,
+	auth.BankIDStateStarted:                auth.HintStarted,
+	auth.BankIDStateUserSign:               auth.HintUserSign,
+	auth.BankIDStateFailed:                 auth.HintUserCancel,
+	auth.BankIDStateExpired:                auth.HintExpiredTransaction,
+}
+
+// login is the customer's login for a BankID transaction. The caller must
+// hold s.mu.
+func (s *Server) login(txID string) auth.Login {
+	login := auth.Login{
+		CustomerID: CustomerID,
+		Username:   "testsson",
+		LoginPath:  "/_api/authentication/v2/sessions/bankid/" + txID + "/" + CustomerID,
+	}
+	for _, a := range s.accountList {
+		login.Accounts = append(login.Accounts, auth.Account{AccountName: a.Name, AccountType: a.Type})
+	}
+	return login
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// A login path is valid for the transaction that offered it, or for
+	// switching login within a live session.
+	pending := s.tx != nil && r.PathValue("tx") == s.tx.id
+	switching := s.session != nil && s.session.valid(r)
+	if r.PathValue("customer") != CustomerID || (!pending && !switching) {
+		http.Error(w, `{"message":"unknown login"}`, http.StatusUnauthorized)
+		return
+	}
+
+	s.tx = nil
+	s.bankIDStep = 0
+	s.session = newSession()
+	for name, value := range s.session.cookies() {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: value, Path: "/"})
+	}
+}
+
+func (s *Server) handleSessionInfo(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session == nil {
+		http.Error(w, `{"message":"unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	writeJSON(w, auth.SessionInfo{User: auth.User{
+		LoggedIn:           true,
+		GreetingName:       "Test",
+		PushSubscriptionID: CustomerID,
+		PushBaseURL:        "/_push/",
+		SecurityToken:      s.session.securityToken,
+		CustomerGroup:      "PRIVATE",
+		ID:                 CustomerID,
+	}})
+}
+
+// memoryStore is an auth.SessionStore kept in memory.
+type memoryStore struct {
+	mu      sync.Mutex
+	session *auth.Session
+}
+
+func (m *memoryStore) Load(context.Context) (*auth.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.session == nil {
+		return nil, auth.ErrNoSession
+	}
+	return m.session, nil
+}
+
+func (m *memoryStore) Save(_ context.Context, session *auth.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.session = session
+	return nil
+}
+
+func (m *memoryStore) Clear(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.session = nil
+	return nil
+}
@@ -0,0 +1,477 @@
+package avanzatest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/trading"
+)
+
+// Order states the fake reports. An order that fills completely leaves the
+// book like a deleted one, with no volume left.
+const (
+	stateActive  = "ACTIVE"
+	stateDeleted = "DELETED"
+)
+
+// stateTexts are the Swedish state names Avanza shows next to each state.
+var stateTexts = map[string]string{
+	stateActive:  "Aktiv",
+	stateDeleted: "Makulerad",
+}
+
+// Rejection messages, in the style of Avanza's error codes.
+const (
+	rejectAccount     = "order.account.invalid"
+	rejectOrderbook   = "order.orderbook.invalid"
+	rejectInvalid     = "order.invalid"
+	rejectBuyingPower = "order.insufficient.buyingpower"
+	rejectVolume      = "order.insufficient.volume"
+	rejectNotActive   = "order.not.active"
+)
+
+// avgPriceDecimals are the decimals a position's average acquired price
+// keeps after a buy.
+const avgPriceDecimals = 4
+
+// order is an order on the fake server.
+type order struct {
+	seq         int
+	id          string
+	accountID   string
+	orderbookID string
+	side        trading.OrderSide
+	condition   trading.OrderCondition
+	price       decimal.Decimal
+	volume      int // not yet filled
+	original    int
+	validUntil  string
+	created     time.Time
+	state       string
+}
+
+// SetPrice moves the price of orderbookID. Open orders the new price reaches
+// fill completely at it, and stop losses it triggers place their orders.
+func (s *Server) SetPrice(orderbookID string, price decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inst, ok := s.instruments[orderbookID]
+	if !ok {
+		return
+	}
+	inst.Price = price
+
+	for _, o := range s.openOrders() {
+		if o.orderbookID == orderbookID && marketable(o, price) {
+			s.fill(o, o.volume, price)
+		}
+	}
+	s.triggerStopLosses(inst)
+}
+
+// Fill executes volume of an open order at price, e.g. to test partial fills.
+func (s *Server) Fill(orderID string, volume int, price decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[orderID]
+	switch {
+	case !ok:
+		return fmt.Errorf("fill: unknown order %s", orderID)
+	case o.state != stateActive:
+		return fmt.Errorf("fill: order %s is %s", orderID, o.state)
+	case volume <= 0 || volume > o.volume:
+		return fmt.Errorf("fill: volume %d outside 1-%d", volume, o.volume)
+	}
+	s.fill(o, volume, price)
+	return nil
+}
+
+// buyingPower is the account's cash less what its open buy orders hold. The
+// caller must hold s.mu.
+func (s *Server) buyingPower(a *account) decimal.Decimal {
+	return a.Cash.Sub(s.heldCash(a.ID, ""))
+}
+
+// heldCash is what the account's open buy orders other than except hold.
+// The caller must hold s.mu.
+func (s *Server) heldCash(accountID, except string) decimal.Decimal {
+	var held decimal.Decimal
+	for _, o := range s.orders {
+		if o.accountID == accountID && o.id != except && o.state == stateActive && o.side == trading.OrderSideBuy {
+			held = held.Add(o.value())
+		}
+	}
+	return held
+}
+
+// sellableVolume is the holding not already offered by open sell orders other
+// than except. The caller must hold s.mu.
+func (s *Server) sellableVolume(a *account, orderbookID, except string) int {
+	p, ok := a.positions[orderbookID]
+	if !ok {
+		return 0
+	}
+	volume := p.volume
+	for _, o := range s.orders {
+		if o.accountID == a.ID && o.id != except && o.orderbookID == orderbookID && o.state == stateActive && o.side == trading.OrderSideSell {
+			volume -= o.volume
+		}
+	}
+	return volume
+}
+
+// checkOrder returns why an order for volume at price would be rejected, or
+// an empty string. except is the order being modified, if any. The caller
+// must hold s.mu.
+func (s *Server) checkOrder(a *account, orderbookID string, side trading.OrderSide, price decimal.Decimal, volume int, except string) string {
+	if price.Sign() <= 0 || volume <= 0 {
+		return rejectInvalid
+	}
+	switch side {
+	case trading.OrderSideBuy:
+		if price.Mul(decimal.NewFromInt(int64(volume))).Cmp(a.Cash.Sub(s.heldCash(a.ID, except))) > 0 {
+			return rejectBuyingPower
+		}
+	case trading.OrderSideSell:
+		if volume > s.sellableVolume(a, orderbookID, except) {
+			return rejectVolume
+		}
+	default:
+		return rejectInvalid
+	}
+	return ""
+}
+
+// placeOrder creates an order and fills it if the price allows. It returns
+// the order, or why it was rejected. The caller must hold s.mu.
+func (s *Server) placeOrder(accountID, orderbookID string, side trading.OrderSide, condition trading.OrderCondition, price decimal.Decimal, volume int, validUntil string) (*order, string) {
+	a, ok := s.accounts[accountID]
+	if !ok {
+		return nil, rejectAccount
+	}
+	inst, ok := s.instruments[orderbookID]
+	if !ok {
+		return nil, rejectOrderbook
+	}
+	if reason := s.checkOrder(a, orderbookID, side, price, volume, ""); reason != "" {
+		return nil, reason
+	}
+	if condition == "" {
+		condition = trading.OrderConditionNormal
+	}
+	if validUntil == "" {
+		validUntil = time.Now().Format(time.DateOnly)
+	}
+
+	o := &order{
+		id:          s.newID(),
+		seq:         s.nextID,
+		accountID:   accountID,
+		orderbookID: orderbookID,
+		side:        side,
+		condition:   condition,
+		price:       price,
+		volume:      volume,
+		original:    volume,
+		validUntil:  validUntil,
+		created:     time.Now(),
+		state:       stateActive,
+	}
+	s.orders[o.id] = o
+	s.pushOrder(o)
+
+	switch {
+	case marketable(o, inst.Price):
+		s.fill(o, o.volume, inst.Price)
+	case condition == trading.OrderConditionFillOrKill:
+		o.state = stateDeleted
+		s.pushOrder(o)
+	}
+	return o, ""
+}
+
+// fill executes volume of o at price, moving cash and holdings and booking
+// the trade. The caller must hold s.mu.
+func (s *Server) fill(o *order, volume int, price decimal.Decimal) {
+	a := s.accounts[o.accountID]
+	p, ok := a.positions[o.orderbookID]
+	if !ok {
+		p = &position{}
+		a.positions[o.orderbookID] = p
+	}
+
+	s.trades = append(s.trades, trade{
+		id:          s.newID(),
+		accountID:   o.accountID,
+		orderbookID: o.orderbookID,
+		side:        o.side,
+		volume:      volume,
+		price:       price,
+		date:        time.Now().Format(time.DateOnly),
+	})
+
+	value := price.Mul(decimal.NewFromInt(int64(volume)))
+	if o.side == trading.OrderSideBuy {
+		a.Cash = a.Cash.Sub(value)
+		acquired := p.avgPrice.Mul(decimal.NewFromInt(int64(p.volume))).Add(value)
+		p.avgPrice = acquired.Div(decimal.NewFromInt(int64(p.volume+volume)), avgPriceDecimals)
+		p.volume += volume
+	} else {
+		a.Cash = a.Cash.Add(value)
+		p.volume -= volume
+	}
+
+	o.volume -= volume
+	if o.volume == 0 {
+		o.state = stateDeleted
+	}
+	s.pushOrder(o)
+}
+
+// value is what the open volume of o is worth at its price.
+func (o *order) value() decimal.Decimal {
+	return o.price.Mul(decimal.NewFromInt(int64(o.volume)))
+}
+
+// marketable reports whether o would fill at price.
+func marketable(o *order, price decimal.Decimal) bool {
+	if o.side == trading.OrderSideBuy {
+		return o.price.Cmp(price) >= 0
+	}
+	return o.price.Cmp(price) <= 0
+}
+
+// openOrders returns the active orders, oldest first. The caller must hold s.mu.
+func (s *Server) openOrders() []*order {
+	var out []*order
+	for _, o := range s.orders {
+		if o.state == stateActive {
+			out = append(out, o)
+		}
+	}
+	slices.SortFunc(out, func(a, b *order) int { return a.seq - b.seq })
+	return out
+}
+
+func (s *Server) handlePlaceOrder(w http.ResponseWriter, r *http.Request) {
+	var req trading.PlaceOrderRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	var validUntil string
+	if req.ValidUntil != nil {
+		validUntil = req.ValidUntil.String()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, reason := s.placeOrder(req.AccountID, req.OrderbookID, req.Side, req.Condition, req.Price, req.Volume, validUntil)
+	if reason != "" {
+		writeJSON(w, trading.PlaceOrderResponse{OrderRequestStatus: trading.OrderRequestStatusError, Message: reason, Parameters: []string{}})
+		return
+	}
+	writeJSON(w, trading.PlaceOrderResponse{OrderRequestStatus: trading.OrderRequestStatusSuccess, Parameters: []string{}, OrderID: o.id})
+}
+
+func (s *Server) handleModifyOrder(w http.ResponseWriter, r *http.Request) {
+	var req trading.ModifyOrderRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := trading.ModifyOrderResponse{OrderRequestStatus: trading.OrderRequestStatusError, Parameters: []string{}, OrderID: req.OrderID}
+	o, reason := s.activeOrder(req.AccountID, req.OrderID)
+	if reason == "" {
+		reason = s.checkOrder(s.accounts[o.accountID], o.orderbookID, o.side, req.Price, req.Volume, o.id)
+	}
+	if reason != "" {
+		resp.Message = reason
+		writeJSON(w, resp)
+		return
+	}
+
+	o.original += req.Volume - o.volume
+	o.volume = req.Volume
+	o.price = req.Price
+	if req.ValidUntil != nil {
+		o.validUntil = req.ValidUntil.String()
+	}
+	s.pushOrder(o)
+	if price := s.instruments[o.orderbookID].Price; marketable(o, price) {
+		s.fill(o, o.volume, price)
+	}
+
+	resp.OrderRequestStatus = trading.OrderRequestStatusSuccess
+	writeJSON(w, resp)
+}
+
+func (s *Server) handleDeleteOrder(w http.ResponseWriter, r *http.Request) {
+	var req trading.DeleteOrderRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := trading.DeleteOrderResponse{OrderRequestStatus: trading.OrderRequestStatusError, Parameters: []string{}, OrderID: req.OrderID}
+	o, reason := s.activeOrder(req.AccountID, req.OrderID)
+	if reason != "" {
+		resp.Message = reason
+		writeJSON(w, resp)
+		return
+	}
+
+	o.state = stateDeleted
+	s.pushOrder(o)
+
+	resp.OrderRequestStatus = trading.OrderRequestStatusSuccess
+	writeJSON(w, resp)
+}
+
+// activeOrder returns the open order orderID in accountID, or why it cannot
+// be changed. The caller must hold s.mu.
+func (s *Server) activeOrder(accountID, orderID string) (*order, string) {
+	o, ok := s.orders[orderID]
+	switch {
+	case !ok || o.accountID != accountID:
+		return nil, rejectInvalid
+	case o.state != stateActive:
+		return nil, rejectNotActive
+	}
+	return o, ""
+}
+
+func (s *Server) handleFindOrder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[r.URL.Query().Get("orderId")]
+	if !ok || o.accountID != r.URL.Query().Get("cAccountId") {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, trading.GetOrderResponse{
+		OrderID:        o.id,
+		OrderbookID:    o.orderbookID,
+		Side:           o.side,
+		State:          o.state,
+		Price:          o.price,
+		Volume:         o.volume,
+		OriginalVolume: o.original,
+		AccountID:      o.accountID,
+		Condition:      o.condition,
+		ValidUntil:     o.validUntil,
+		Modifiable:     o.state == stateActive,
+		Deletable:      o.state == stateActive,
+	})
+}
+
+func (s *Server) handleOrders(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := trading.GetOrdersResponse{Orders: []trading.Order{}, FundOrders: []any{}, CancelledOrders: []any{}}
+	for _, o := range s.openOrders() {
+		a := s.accounts[o.accountID]
+		inst := s.instruments[o.orderbookID]
+
+		var acc trading.OrderAccount
+		acc.AccountID = a.ID
+		acc.Name.Value = a.Name
+		acc.Type.AccountType = a.Type
+		acc.URLParameterID = a.urlParameterID
+
+		resp.Orders = append(resp.Orders, trading.Order{
+			Account:        acc,
+			OrderID:        o.id,
+			Volume:         o.volume,
+			OriginalVolume: o.original,
+			Price:          o.price,
+			Amount:         o.value(),
+			OrderbookID:    o.orderbookID,
+			Side:           o.side,
+			ValidUntil:     o.validUntil,
+			Created:        o.created.Format(time.RFC3339),
+			Deletable:      true,
+			Modifiable:     true,
+			State:          o.state,
+			StateText:      stateTexts[o.state],
+			Orderbook: trading.OrderOrderbook{
+				ID:             inst.OrderbookID,
+				Name:           inst.Name,
+				CountryCode:    "SE",
+				Currency:       inst.Currency,
+				InstrumentType: inst.Type,
+				VolumeFactor:   "1",
+				ISIN:           inst.ISIN,
+				MIC:            "XSTO",
+			},
+			AdditionalParameters: map[string]any{},
+			Condition:            o.condition,
+		})
+	}
+	writeJSON(w, resp)
+}
+
+// pushOrder sends an ORDER event for o. Like Avanza's captured events, it is
+// NEW while o is in the book, including after a change or a partial fill, and
+// DELETED once o has left it; currentVolume tells a fill from a deletion. The
+// caller must hold s.mu.
+func (s *Server) pushOrder(o *order) {
+	inst := s.instruments[o.orderbookID]
+	action, state := trading.OrderActionNew, trading.OrderEventState{
+		Value:       "Väntande",
+		Description: "Din order skickas iväg när marknaden öppnar.",
+		Name:        trading.OrderStateActivePending,
+	}
+	if o.state != stateActive {
+		action, state = trading.OrderActionDeleted, trading.OrderEventState{
+			Value:       "Makulerad",
+			Description: "Din order har tagits bort.",
+			Name:        trading.OrderStateDeleted,
+		}
+	}
+	now := time.Now().UnixMilli()
+	id := o.id + "_" + string(action) + "_" + strconv.FormatInt(now, 10)
+	s.push.publish(streamOrders, "ORDER", id, trading.OrderEventData{
+		ID:        o.id,
+		AccountID: o.accountID,
+		Orderbook: trading.OrderEventOrderbook{
+			ID:              inst.OrderbookID,
+			Name:            inst.Name,
+			TickerSymbol:    inst.TickerSymbol,
+			MarketplaceName: "XSTO",
+			CountryCode:     "SE",
+			InstrumentType:  inst.Type,
+			Tradable:        true,
+			VolumeFactor:    1,
+			CurrencyCode:    inst.Currency,
+			FlagCode:        "SE",
+		},
+		CurrentVolume:  float64(o.volume),
+		OriginalVolume: float64(o.original),
+		Price:          o.price,
+		ValidDate:      &o.validUntil,
+		Type:           o.side,
+		State:          state,
+		Action:         action,
+		Modifiable:     o.state == stateActive,
+		Deletable:      o.state == stateActive,
+		Sum:            o.value(),
+		OrderDateTime:  o.created.UnixMilli(),
+		EventTimeStamp: now,
+		UniqueID:       id,
+		Condition:      o.condition,
+	})
+}
@@ -0,0 +1,127 @@
+package avanzatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Push streams.
+const (
+	streamOrders   = "orders"
+	streamStopLoss = "stoploss"
+)
+
+// pushEvent is one SSE event, with its data already encoded.
+type pushEvent struct {
+	id    string
+	event string
+	data  []byte
+}
+
+// broker fans events out to the SSE streams connected at the time.
+type broker struct {
+	mu        sync.Mutex
+	subs      map[*subscriber]string // subscriber -> stream
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// subscriber queues events for one connection, so publishing never waits
+// for a slow reader.
+type subscriber struct {
+	mu      sync.Mutex
+	pending []pushEvent
+	notify  chan struct{}
+}
+
+func newBroker() *broker {
+	return &broker{subs: make(map[*subscriber]string), closed: make(chan struct{})}
+}
+
+func (b *broker) subscribe(stream string) *subscriber {
+	sub := &subscriber{notify: make(chan struct{}, 1)}
+	b.mu.Lock()
+	b.subs[sub] = stream
+	b.mu.Unlock()
+	return sub
+}
+
+func (b *broker) unsubscribe(sub *subscriber) {
+	b.mu.Lock()
+	delete(b.subs, sub)
+	b.mu.Unlock()
+}
+
+func (b *broker) publish(stream, event, id string, data any) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		panic(fmt.Sprintf("avanzatest: encode %s event: %v", event, err))
+	}
+	e := pushEvent{id: id, event: event, data: raw}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub, s := range b.subs {
+		if s != stream {
+			continue
+		}
+		sub.mu.Lock()
+		sub.pending = append(sub.pending, e)
+		sub.mu.Unlock()
+		select {
+		case sub.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (b *broker) close() {
+	b.closeOnce.Do(func() { close(b.closed) })
+}
+
+// take returns the queued events and empties the queue.
+func (sub *subscriber) take() []pushEvent {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	events := sub.pending
+	sub.pending = nil
+	return events
+}
+
+// handlePush streams the events published to stream until the client
+// disconnects or the server closes.
+func (s *Server) handlePush(stream string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		sub := s.push.subscribe(stream)
+		defer s.push.unsubscribe(sub)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-s.push.closed:
+				return
+			case <-sub.notify:
+			}
+			for _, e := range sub.take() {
+				if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.id, e.event, e.data); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
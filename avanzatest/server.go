@@ -0,0 +1,298 @@
+// Package avanzatest provides an in-process fake of the Avanza API for tests
+// of code built on this SDK. The fake is stateful: it runs the BankID login,
+// keeps accounts, positions, orders and stop losses, moves buying power as
+// orders are placed, filled and deleted, books fills as transactions, and
+// pushes order and stop loss events to SSE subscribers in the shapes Avanza
+// sends.
+//
+//	srv := avanzatest.NewServer()
+//	defer srv.Close()
+//
+//	client := avanza.New(
+//		avanza.WithBaseURL(srv.URL),
+//		avanza.WithSessionStore(srv.SessionStore()),
+//	)
+//	if _, err := client.Auth.RestoreSession(ctx); err != nil { ... }
+//
+// Orders fill at the instrument's price: a buy at or above it, or a sell at or
+// below it, fills when placed. Others rest until SetPrice moves the price
+// through them, or until Fill executes them explicitly, e.g. partially.
+//
+// The fake serves one customer and is not a model of Avanza's matching,
+// fees or trading hours.
+package avanzatest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"github.com/vmorsell/avanza-sdk-go/auth"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+)
+
+// CustomerID is the customer the fake logs in as.
+const CustomerID = "1234567"
+
+// Account is a trading account on the fake server.
+type Account struct {
+	ID        string // e.g. "9876543"
+	Name      string
+	Type      string          // "ISK", "KF", "AF", ...
+	Cash      decimal.Decimal // SEK balance
+	Positions []Position      // holdings at start
+}
+
+// Position is a holding in an Account.
+type Position struct {
+	OrderbookID          string
+	Volume               int
+	AverageAcquiredPrice decimal.Decimal
+}
+
+// Instrument is a tradable instrument on the fake server.
+type Instrument struct {
+	OrderbookID  string // e.g. "5247"
+	Name         string
+	TickerSymbol string
+	ISIN         string
+	Currency     string // defaults to "SEK"
+	Type         string // defaults to "STOCK"
+	Price        decimal.Decimal
+}
+
+// DefaultAccounts are the accounts NewServer creates unless WithAccounts is given.
+var DefaultAccounts = []Account{
+	{ID: "9876543", Name: "ISK", Type: "ISK", Cash: decimal.NewFromInt(100_000)},
+}
+
+// DefaultInstruments are the instruments NewServer creates unless
+// WithInstruments is given.
+var DefaultInstruments = []Instrument{
+	{OrderbookID: "5247", Name: "Investor B", TickerSymbol: "INVE B", ISIN: "SE0015811963", Price: decimal.MustParse("245.50")},
+	{OrderbookID: "5240", Name: "Ericsson B", TickerSymbol: "ERIC B", ISIN: "SE0000108656", Price: decimal.MustParse("90.00")},
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithAccounts replaces DefaultAccounts.
+func WithAccounts(accounts ...Account) Option {
+	return func(s *Server) {
+		s.accountList = accounts
+	}
+}
+
+// WithInstruments replaces DefaultInstruments.
+func WithInstruments(instruments ...Instrument) Option {
+	return func(s *Server) {
+		s.instrumentList = instruments
+	}
+}
+
+// WithBankIDStates sets the states BankID collect reports, one per call,
+// staying on the last one. The default is a single BankIDStateComplete.
+// The sequence carries over when polling replaces an expired transaction,
+// and starts over after each login.
+//
+//	avanzatest.NewServer(avanzatest.WithBankIDStates(
+//		auth.BankIDStateOutstandingTransaction,
+//		auth.BankIDStateUserSign,
+//		auth.BankIDStateComplete,
+//	))
+func WithBankIDStates(states ...auth.BankIDState) Option {
+	return func(s *Server) {
+		s.bankIDStates = states
+	}
+}
+
+// Server is a fake Avanza API. It is safe for concurrent use.
+type Server struct {
+	// URL is the base URL to pass to avanza.WithBaseURL.
+	URL string
+
+	server *httptest.Server
+	push   *broker
+
+	mu             sync.Mutex
+	accountList    []Account
+	instrumentList []Instrument
+	accounts       map[string]*account
+	instruments    map[string]*Instrument
+	orders         map[string]*order
+	stopLosses     map[string]*stopLoss
+	trades         []trade
+	nextID         int
+	bankIDStates   []auth.BankIDState
+	bankIDStep     int
+	tx             *bankIDTransaction
+	session        *session
+}
+
+// NewServer starts a fake Avanza API. Close it when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		push:           newBroker(),
+		accountList:    DefaultAccounts,
+		instrumentList: DefaultInstruments,
+		accounts:       make(map[string]*account),
+		instruments:    make(map[string]*Instrument),
+		orders:         make(map[string]*order),
+		stopLosses:     make(map[string]*stopLoss),
+		nextID:         1000,
+		bankIDStates:   []auth.BankIDState{auth.BankIDStateComplete},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	for _, a := range s.accountList {
+		s.accounts[a.ID] = newAccount(a)
+	}
+	for _, inst := range s.instrumentList {
+		if inst.Currency == "" {
+			inst.Currency = "SEK"
+		}
+		if inst.Type == "" {
+			inst.Type = "STOCK"
+		}
+		s.instruments[inst.OrderbookID] = &inst
+	}
+
+	s.server = httptest.NewServer(s.routes())
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down, ending open SSE streams.
+func (s *Server) Close() {
+	s.push.close()
+	s.server.Close()
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /{$}", s.handleStartPage)
+	mux.HandleFunc("POST /_api/authentication/v2/sessions/bankid", s.handleBankIDStart)
+	mux.HandleFunc("POST /_api/authentication/v2/sessions/bankid/restart", s.handleBankIDRestart)
+	mux.HandleFunc("POST /_api/authentication/v2/sessions/bankid/collect", s.handleBankIDCollect)
+	mux.HandleFunc("GET /_api/authentication/v2/sessions/bankid/{tx}/{customer}", s.handleLogin)
+
+	authed := http.NewServeMux()
+	authed.HandleFunc("GET /handla/order.html", func(http.ResponseWriter, *http.Request) {})
+	authed.HandleFunc("GET /_api/authentication/session/info/session", s.handleSessionInfo)
+
+	authed.HandleFunc("GET /_api/account-overview/overview/categorizedAccounts", s.handleOverview)
+	authed.HandleFunc("GET /_api/trading-critical/rest/accounts", s.handleTradingAccounts)
+	authed.HandleFunc("GET /_api/position-data/positions/{account}", s.handlePositions)
+	authed.HandleFunc("GET /_api/transactions/list", s.handleTransactions)
+
+	authed.HandleFunc("POST /_api/trading-critical/rest/order/new", s.handlePlaceOrder)
+	authed.HandleFunc("POST /_api/trading-critical/rest/order/modify", s.handleModifyOrder)
+	authed.HandleFunc("POST /_api/trading-critical/rest/order/delete", s.handleDeleteOrder)
+	authed.HandleFunc("GET /_api/trading-critical/rest/order/find", s.handleFindOrder)
+	authed.HandleFunc("GET /_api/trading/rest/orders", s.handleOrders)
+
+	authed.HandleFunc("POST /_api/trading/stoploss/new", s.handlePlaceStopLoss)
+	authed.HandleFunc("POST /_api/trading/stoploss/modify", s.handleModifyStopLoss)
+	authed.HandleFunc("GET /_api/trading/stoploss/{$}", s.handleStopLosses)
+	authed.HandleFunc("GET /_api/trading/stoploss/{account}/{id}", s.handleGetStopLoss)
+	authed.HandleFunc("DELETE /_api/trading/stoploss/{account}/{id}", s.handleDeleteStopLoss)
+
+	authed.HandleFunc("GET /_push/trading/orders/", s.handlePush(streamOrders))
+	authed.HandleFunc("GET /_push/trading/stoploss/", s.handlePush(streamStopLoss))
+
+	mux.Handle("/", s.requireSession(authed))
+	return mux
+}
+
+// requireSession answers 401 to requests without the session cookies, and
+// 403 to changes without the security token.
+func (s *Server) requireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		sess := s.session
+		s.mu.Unlock()
+
+		if sess == nil || !sess.valid(r) {
+			http.Error(w, `{"message":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodGet && r.Header.Get("X-SecurityToken") != sess.securityToken {
+			http.Error(w, `{"message":"invalid security token"}`, http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// account is the live state of an Account.
+type account struct {
+	Account
+	urlParameterID string
+	positions      map[string]*position
+}
+
+// position is the live state of a Position.
+type position struct {
+	volume   int
+	avgPrice decimal.Decimal
+}
+
+func newAccount(a Account) *account {
+	// Avanza identifies accounts in some URLs by an opaque ID instead of the
+	// account number; the fake derives one so the two cannot be mixed up.
+	sum := sha256.Sum256([]byte(a.ID))
+	acc := &account{
+		Account:        a,
+		urlParameterID: hex.EncodeToString(sum[:16]),
+		positions:      make(map[string]*position),
+	}
+	for _, p := range a.Positions {
+		acc.positions[p.OrderbookID] = &position{volume: p.Volume, avgPrice: p.AverageAcquiredPrice}
+	}
+	return acc
+}
+
+// accountByURLParameterID returns the account for an opaque URL ID, or for
+// its account number. The caller must hold s.mu.
+func (s *Server) accountByURLParameterID(id string) *account {
+	for _, a := range s.accounts {
+		if a.urlParameterID == id || a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+// newID returns a unique numeric ID. The caller must hold s.mu.
+func (s *Server) newID() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, `{"message":"invalid request body"}`, http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// randomToken returns a random hex string for cookies and BankID tokens.
+func randomToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
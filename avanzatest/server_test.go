@@ -0,0 +1,320 @@
+package avanzatest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go"
+	"github.com/vmorsell/avanza-sdk-go/auth"
+	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/trading"
+)
+
+const testAccount = "9876543"
+
+// newClient returns a client logged in to srv through a restored session.
+func newClient(t *testing.T, srv *Server) *avanza.Avanza {
+	t.Helper()
+	c := avanza.New(avanza.WithBaseURL(srv.URL), avanza.WithSessionStore(srv.SessionStore()))
+	if _, err := c.Auth.RestoreSession(context.Background()); err != nil {
+		t.Fatalf("RestoreSession: %v", err)
+	}
+	return c
+}
+
+func TestBankIDLogin(t *testing.T) {
+	srv := NewServer(WithBankIDStates(auth.BankIDStateExpired, auth.BankIDStateComplete))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := avanza.New(
+		avanza.WithBaseURL(srv.URL),
+		avanza.WithQRPresenter(auth.QRPresenterFunc(func(context.Context, string) error { return nil })),
+	)
+
+	if _, err := c.Trading.GetOrders(ctx); !errors.Is(err, client.ErrSessionExpired) {
+		t.Fatalf("GetOrders before login: err = %v, want ErrSessionExpired", err)
+	}
+
+	start, err := c.Auth.StartBankID(ctx)
+	if err != nil {
+		t.Fatalf("StartBankID: %v", err)
+	}
+	if start.QRToken == "" || start.ExpiresAt().IsZero() {
+		t.Errorf("start = %+v, want a QR token and expiry", start)
+	}
+
+	collect, err := c.Auth.PollBankID(ctx)
+	if err != nil {
+		t.Fatalf("PollBankID: %v", err)
+	}
+	if collect.State != auth.BankIDStateComplete || len(collect.Logins) != 1 {
+		t.Fatalf("collect = %+v, want COMPLETE with one login", collect)
+	}
+	if err := c.Auth.EstablishSession(ctx, collect); err != nil {
+		t.Fatalf("EstablishSession: %v", err)
+	}
+
+	info, err := c.Auth.GetSessionInfo(ctx)
+	if err != nil {
+		t.Fatalf("GetSessionInfo: %v", err)
+	}
+	if !info.User.LoggedIn || info.User.SecurityToken == "" {
+		t.Errorf("user = %+v, want logged in with a security token", info.User)
+	}
+
+	srv.ExpireSession()
+	if _, err := c.Accounts.GetTradingAccounts(ctx); !errors.Is(err, client.ErrSessionExpired) {
+		t.Errorf("after ExpireSession: err = %v, want ErrSessionExpired", err)
+	}
+}
+
+func TestOrderLifecycle(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	c := newClient(t, srv)
+	ctx := context.Background()
+
+	sub, err := c.Trading.SubscribeToOrders(ctx)
+	if err != nil {
+		t.Fatalf("SubscribeToOrders: %v", err)
+	}
+	defer sub.Close()
+	time.Sleep(100 * time.Millisecond) // let the stream connect
+
+	// Below the price of 245.5: the order rests and holds buying power.
+	placed, err := c.Trading.PlaceOrder(ctx, &trading.PlaceOrderRequest{
+		AccountID:   testAccount,
+		OrderbookID: "5247",
+		Side:        trading.OrderSideBuy,
+		Condition:   trading.OrderConditionNormal,
+		Price:       decimal.NewFromInt(240),
+		Volume:      10,
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if got, want := srv.BuyingPower(testAccount), decimal.NewFromInt(100_000-2400); !got.Equal(want) {
+		t.Errorf("BuyingPower = %v, want %v", got, want)
+	}
+	accs, err := c.Accounts.GetTradingAccounts(ctx)
+	if err != nil {
+		t.Fatalf("GetTradingAccounts: %v", err)
+	}
+	if got, want := accs[0].AvailableForPurchase, decimal.NewFromInt(100_000-2400); !got.Equal(want) {
+		t.Errorf("AvailableForPurchase = %v, want %v", got, want)
+	}
+	orders, err := c.Trading.GetOrders(ctx)
+	if err != nil {
+		t.Fatalf("GetOrders: %v", err)
+	}
+	if len(orders.Orders) != 1 || orders.Orders[0].OrderID != placed.OrderID {
+		t.Fatalf("orders = %+v, want the placed order", orders.Orders)
+	}
+
+	srv.SetPrice("5247", decimal.MustParse("239.50"))
+
+	got, err := c.Trading.GetOrder(ctx, &trading.GetOrderRequest{OrderID: placed.OrderID, AccountID: testAccount})
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if got.State != "DELETED" || got.Volume != 0 {
+		t.Errorf("order = %+v, want DELETED with no volume left", got)
+	}
+	if got := srv.Holding(testAccount, "5247"); got != 10 {
+		t.Errorf("Holding = %d, want 10", got)
+	}
+	if got, want := srv.BuyingPower(testAccount), decimal.NewFromInt(100_000-2395); !got.Equal(want) {
+		t.Errorf("BuyingPower = %v, want %v", got, want)
+	}
+
+	positions, err := c.Accounts.GetPositions(ctx, accs[0].URLParameterID)
+	if err != nil {
+		t.Fatalf("GetPositions: %v", err)
+	}
+	if len(positions.WithOrderbook) != 1 || !positions.WithOrderbook[0].Volume.Value.Equal(decimal.MustParse("10")) {
+		t.Errorf("positions = %+v, want 10 of 5247", positions.WithOrderbook)
+	} else if p := positions.WithOrderbook[0]; !p.AverageAcquiredPrice.Value.Equal(decimal.MustParse("239.50")) || p.Value.Value.String() != "2395.00" {
+		t.Errorf("average price, value = %s, %s, want 239.50, 2395.00", p.AverageAcquiredPrice.Value, p.Value.Value)
+	}
+
+	for _, want := range []trading.OrderAction{trading.OrderActionNew, trading.OrderActionDeleted} {
+		select {
+		case e := <-sub.Events():
+			if e.Data.ID != placed.OrderID || e.Data.Action != want {
+				t.Errorf("event = %s %s, want %s %s", e.Data.ID, e.Data.Action, placed.OrderID, want)
+			}
+			if want == trading.OrderActionDeleted && e.Data.CurrentVolume != 0 {
+				t.Errorf("filled event currentVolume = %v, want 0", e.Data.CurrentVolume)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s event", want)
+		}
+	}
+}
+
+func TestOrderRejectedForBuyingPower(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	c := newClient(t, srv)
+
+	_, err := c.Trading.PlaceOrder(context.Background(), &trading.PlaceOrderRequest{
+		AccountID:   testAccount,
+		OrderbookID: "5247",
+		Side:        trading.OrderSideBuy,
+		Condition:   trading.OrderConditionNormal,
+		Price:       decimal.MustParse("245.5"),
+		Volume:      1000,
+	})
+	var rejected *trading.OrderRejectedError
+	if !errors.As(err, &rejected) || rejected.Message != rejectBuyingPower {
+		t.Fatalf("err = %v, want %s rejection", err, rejectBuyingPower)
+	}
+}
+
+func TestPartialFillThenDelete(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	c := newClient(t, srv)
+	ctx := context.Background()
+
+	placed, err := c.Trading.PlaceOrder(ctx, &trading.PlaceOrderRequest{
+		AccountID:   testAccount,
+		OrderbookID: "5240",
+		Side:        trading.OrderSideBuy,
+		Condition:   trading.OrderConditionNormal,
+		Price:       decimal.NewFromInt(80),
+		Volume:      100,
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if err := srv.Fill(placed.OrderID, 40, decimal.NewFromInt(79)); err != nil {
+		t.Fatalf("Fill: %v", err)
+	}
+	if _, err := c.Trading.DeleteOrder(ctx, &trading.DeleteOrderRequest{AccountID: testAccount, OrderID: placed.OrderID}); err != nil {
+		t.Fatalf("DeleteOrder: %v", err)
+	}
+
+	got, err := c.Trading.GetOrder(ctx, &trading.GetOrderRequest{OrderID: placed.OrderID, AccountID: testAccount})
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if got.State != "DELETED" || got.Volume != 60 || got.OriginalVolume != 100 {
+		t.Errorf("order = %+v, want DELETED with 60 of 100 left", got)
+	}
+	if got, want := srv.BuyingPower(testAccount), decimal.NewFromInt(100_000-40*79); !got.Equal(want) {
+		t.Errorf("BuyingPower = %v, want %v", got, want)
+	}
+}
+
+func TestPlaceOrderAndWaitAveragesFills(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	c := newClient(t, srv)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Fill the resting order in two trades once it is in the book.
+	go func() {
+		for {
+			orders, err := c.Trading.GetOrders(ctx)
+			if err != nil {
+				return
+			}
+			if len(orders.Orders) == 1 {
+				_ = srv.Fill(orders.Orders[0].OrderID, 4, decimal.MustParse("239.50"))
+				srv.SetPrice("5247", decimal.MustParse("239.00"))
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	outcome, err := c.Trading.PlaceOrderAndWait(ctx, &trading.PlaceOrderRequest{
+		AccountID:   testAccount,
+		OrderbookID: "5247",
+		Side:        trading.OrderSideBuy,
+		Condition:   trading.OrderConditionNormal,
+		Price:       decimal.NewFromInt(240),
+		Volume:      10,
+	}, nil)
+	if err != nil {
+		t.Fatalf("PlaceOrderAndWait: %v", err)
+	}
+	if outcome.State != trading.OrderOutcomeFilled || outcome.FilledVolume != 10 {
+		t.Errorf("outcome = %+v, want filled with 10", outcome)
+	}
+	if outcome.AveragePrice.String() != "239.20" || outcome.FilledValue.String() != "2392.00" {
+		t.Errorf("AveragePrice, FilledValue = %s, %s, want 239.20, 2392.00", outcome.AveragePrice, outcome.FilledValue)
+	}
+}
+
+func TestStopLossTriggersSell(t *testing.T) {
+	srv := NewServer(WithAccounts(Account{
+		ID:        testAccount,
+		Name:      "ISK",
+		Type:      "ISK",
+		Positions: []Position{{OrderbookID: "5247", Volume: 10, AverageAcquiredPrice: decimal.NewFromInt(200)}},
+	}))
+	defer srv.Close()
+	c := newClient(t, srv)
+	ctx := context.Background()
+
+	sub, err := c.Trading.SubscribeToStopLoss(ctx)
+	if err != nil {
+		t.Fatalf("SubscribeToStopLoss: %v", err)
+	}
+	defer sub.Close()
+	time.Sleep(100 * time.Millisecond) // let the stream connect
+
+	placed, err := c.Trading.PlaceStopLoss(ctx, &trading.PlaceStopLossRequest{
+		AccountID:   testAccount,
+		OrderbookID: "5247",
+		StopLossTrigger: trading.StopLossTrigger{
+			Type:      trading.StopLossTriggerLessOrEqual,
+			Value:     230,
+			ValueType: trading.StopLossValueMonetary,
+		},
+		StopLossOrderEvent: trading.StopLossOrderEvent{
+			Type:      trading.StopLossOrderEventSell,
+			Price:     225,
+			Volume:    10,
+			ValidDays: 1,
+			PriceType: trading.StopLossPriceMonetary,
+		},
+	})
+	if err != nil {
+		t.Fatalf("PlaceStopLoss: %v", err)
+	}
+	stopLosses, err := c.Trading.GetStopLossOrders(ctx)
+	if err != nil {
+		t.Fatalf("GetStopLossOrders: %v", err)
+	}
+	if len(stopLosses) != 1 || stopLosses[0].ID != placed.StopLossOrderID {
+		t.Fatalf("stop losses = %+v, want the placed one", stopLosses)
+	}
+
+	srv.SetPrice("5247", decimal.NewFromInt(229))
+
+	if got := srv.Holding(testAccount, "5247"); got != 0 {
+		t.Errorf("Holding = %d, want 0 after the stop loss sold", got)
+	}
+	if got, want := srv.BuyingPower(testAccount), decimal.NewFromInt(2290); !got.Equal(want) {
+		t.Errorf("BuyingPower = %v, want %v", got, want)
+	}
+
+	for _, want := range []trading.StopLossPushAction{trading.StopLossPushActionUpdated, trading.StopLossPushActionDeleted} {
+		select {
+		case e := <-sub.Events():
+			if e.Data.ID != placed.StopLossOrderID || e.Data.PushAction != want {
+				t.Errorf("event = %s %s, want %s %s", e.Data.ID, e.Data.PushAction, placed.StopLossOrderID, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s event", want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package avanzatest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/trading"
+)
+
+// stopLoss is a stop loss on the fake server.
+type stopLoss struct {
+	seq         int
+	id          string
+	accountID   string
+	orderbookID string
+	trigger     trading.StopLossTrigger
+	event       trading.StopLossOrderEvent
+	reference   decimal.Decimal // price when placed, the base of percentage triggers
+}
+
+// triggered reports whether price reaches the stop loss's trigger.
+func (sl *stopLoss) triggered(price decimal.Decimal) bool {
+	below := sl.trigger.Type == trading.StopLossTriggerLessOrEqual
+	level := decimal.NewFromFloat(sl.trigger.Value)
+	if sl.trigger.ValueType == trading.StopLossValuePercentage {
+		level = percentOff(sl.reference, level, below)
+	}
+	if below {
+		return price.Cmp(level) <= 0
+	}
+	return price.Cmp(level) >= 0
+}
+
+// orderPrice is the price of the order the stop loss places at price.
+func (sl *stopLoss) orderPrice(price decimal.Decimal) decimal.Decimal {
+	p := decimal.NewFromFloat(sl.event.Price)
+	if sl.event.PriceType != trading.StopLossPricePercentage {
+		return p
+	}
+	return percentOff(price, p, sl.event.Type != trading.StopLossOrderEventBuy)
+}
+
+// percentOff returns price moved by percent, down if below and up otherwise.
+func percentOff(price, percent decimal.Decimal, below bool) decimal.Decimal {
+	change := price.Mul(percent).Mul(decimal.New(1, 2)) // percent / 100
+	if below {
+		return price.Sub(change)
+	}
+	return price.Add(change)
+}
+
+// triggerStopLosses replaces the stop losses on inst that its price reaches
+// with orders. The caller must hold s.mu.
+func (s *Server) triggerStopLosses(inst *Instrument) {
+	var triggered []*stopLoss
+	for _, sl := range s.stopLosses {
+		if sl.orderbookID == inst.OrderbookID && sl.triggered(inst.Price) {
+			triggered = append(triggered, sl)
+		}
+	}
+	slices.SortFunc(triggered, func(a, b *stopLoss) int { return a.seq - b.seq })
+
+	for _, sl := range triggered {
+		delete(s.stopLosses, sl.id)
+		s.pushStopLoss(sl, trading.StopLossPushActionDeleted)
+		validUntil := time.Now().AddDate(0, 0, sl.event.ValidDays).Format(time.DateOnly)
+		// A rejected order is dropped, as when Avanza cannot place it.
+		_, _ = s.placeOrder(sl.accountID, sl.orderbookID, trading.OrderSide(sl.event.Type), trading.OrderConditionNormal,
+			sl.orderPrice(inst.Price), sl.event.Volume, validUntil)
+	}
+}
+
+func (s *Server) handlePlaceStopLoss(w http.ResponseWriter, r *http.Request) {
+	var req trading.PlaceStopLossRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, accountOK := s.accounts[req.AccountID]
+	inst, instOK := s.instruments[req.OrderbookID]
+	if !accountOK || !instOK {
+		writeJSON(w, trading.PlaceStopLossResponse{Status: trading.StopLossStatusError})
+		return
+	}
+
+	id := s.newID()
+	sl := &stopLoss{
+		seq:         s.nextID,
+		id:          "A2^" + strconv.FormatInt(time.Now().UnixMilli(), 10) + "^" + id,
+		accountID:   req.AccountID,
+		orderbookID: req.OrderbookID,
+		trigger:     req.StopLossTrigger,
+		event:       req.StopLossOrderEvent,
+		reference:   inst.Price,
+	}
+	s.stopLosses[sl.id] = sl
+	s.pushStopLoss(sl, trading.StopLossPushActionUpdated)
+
+	writeJSON(w, trading.PlaceStopLossResponse{Status: trading.StopLossStatusSuccess, StopLossOrderID: sl.id})
+}
+
+func (s *Server) handleModifyStopLoss(w http.ResponseWriter, r *http.Request) {
+	var req trading.ModifyStopLossRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sl, ok := s.stopLosses[req.StopLossOrderID]
+	if !ok || sl.accountID != req.AccountID {
+		writeJSON(w, trading.PlaceStopLossResponse{Status: trading.StopLossStatusError, StopLossOrderID: req.StopLossOrderID})
+		return
+	}
+	sl.trigger = req.StopLossTrigger
+	sl.event = req.StopLossOrderEvent
+	s.pushStopLoss(sl, trading.StopLossPushActionUpdated)
+
+	writeJSON(w, trading.PlaceStopLossResponse{Status: trading.StopLossStatusSuccess, StopLossOrderID: sl.id})
+}
+
+func (s *Server) handleStopLosses(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := make([]*stopLoss, 0, len(s.stopLosses))
+	for _, sl := range s.stopLosses {
+		all = append(all, sl)
+	}
+	slices.SortFunc(all, func(a, b *stopLoss) int { return a.seq - b.seq })
+
+	out := make([]trading.StopLossOrder, 0, len(all))
+	for _, sl := range all {
+		out = append(out, s.stopLossOrder(sl))
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) handleGetStopLoss(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.accountByURLParameterID(r.PathValue("account"))
+	sl, ok := s.stopLosses[r.PathValue("id")]
+	if a == nil || !ok || sl.accountID != a.ID {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, s.stopLossOrder(sl))
+}
+
+func (s *Server) handleDeleteStopLoss(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sl, ok := s.stopLosses[r.PathValue("id")]
+	if !ok || sl.accountID != r.PathValue("account") {
+		http.NotFound(w, r)
+		return
+	}
+	delete(s.stopLosses, sl.id)
+	s.pushStopLoss(sl, trading.StopLossPushActionDeleted)
+}
+
+// stopLossOrder describes sl as the stop loss endpoints return it. The
+// caller must hold s.mu.
+func (s *Server) stopLossOrder(sl *stopLoss) trading.StopLossOrder {
+	a := s.accounts[sl.accountID]
+	return trading.StopLossOrder{
+		ID:     sl.id,
+		Status: "ACTIVE",
+		Account: trading.StopLossAccount{
+			ID:             a.ID,
+			Name:           a.Name,
+			Type:           a.Type,
+			URLParameterID: a.urlParameterID,
+		},
+		Orderbook: s.stopLossOrderbook(sl),
+		Trigger: trading.StopLossTriggerResponse{
+			Value:                     sl.trigger.Value,
+			Type:                      sl.trigger.Type,
+			ValidUntil:                sl.trigger.ValidUntil,
+			ValueType:                 sl.trigger.ValueType,
+			TriggerOnMarketMakerQuote: sl.trigger.TriggerOnMarketMakerQuote,
+		},
+		Order: trading.StopLossOrderDetails{
+			Type:                  sl.event.Type,
+			Price:                 sl.event.Price,
+			Volume:                sl.event.Volume,
+			ShortSellingAllowed:   sl.event.ShortSellingAllowed,
+			ValidDays:             sl.event.ValidDays,
+			PriceType:             sl.event.PriceType,
+			PriceDecimalPrecision: 2,
+		},
+		Editable:  true,
+		Deletable: true,
+	}
+}
+
+// stopLossOrderbook describes the instrument of sl. The caller must hold s.mu.
+func (s *Server) stopLossOrderbook(sl *stopLoss) trading.StopLossOrderbook {
+	inst := s.instruments[sl.orderbookID]
+	return trading.StopLossOrderbook{
+		ID:          inst.OrderbookID,
+		Name:        inst.Name,
+		CountryCode: "SE",
+		Currency:    inst.Currency,
+		ShortName:   inst.TickerSymbol,
+		Type:        inst.Type,
+	}
+}
+
+// pushStopLoss sends a STOPLOSS event for sl. The caller must hold s.mu.
+func (s *Server) pushStopLoss(sl *stopLoss, action trading.StopLossPushAction) {
+	id := sl.id + "_" + string(action) + "_" + strconv.FormatInt(time.Now().UnixMilli(), 10)
+	data := trading.StopLossEventData{
+		ID:         sl.id,
+		UniqueID:   id,
+		Status:     trading.StopLossEventStatusActive,
+		AccountID:  sl.accountID,
+		Orderbook:  s.stopLossOrderbook(sl),
+		Editable:   true,
+		Deletable:  true,
+		PushAction: action,
+	}
+	if action == trading.StopLossPushActionDeleted {
+		data.Status = trading.StopLossEventStatusDeleted
+		data.Editable = false
+		data.Deletable = false
+	} else {
+		data.Order = &trading.StopLossEventOrder{
+			Type:                  sl.event.Type,
+			Price:                 sl.event.Price,
+			Volume:                float64(sl.event.Volume),
+			ShortSellingAllowed:   sl.event.ShortSellingAllowed,
+			ValidDays:             sl.event.ValidDays,
+			PriceType:             sl.event.PriceType,
+			PriceDecimalPrecision: 2,
+		}
+		data.Trigger = &trading.StopLossEventTrigger{
+			Value:      sl.trigger.Value,
+			Type:       sl.trigger.Type,
+			ValidUntil: sl.trigger.ValidUntil,
+			ValueType:  sl.trigger.ValueType,
+		}
+	}
+	s.push.publish(streamStopLoss, "STOPLOSS", id, data)
+}
@@ -0,0 +1,105 @@
+// Package cache stores API responses for a limited time, so data that rarely
+// changes, like tick size tables and instrument details, is not fetched again
+// before every order.
+//
+//	store := cache.NewLRU(1000)
+//	client := avanza.New(avanza.WithMarketCache(store, nil))
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store holds cached values by key until they expire. Implementations must
+// be safe for concurrent use. A Store that fails to read or write, e.g. a
+// full disk, behaves as if the value was not cached.
+type Store interface {
+	// Get returns the value for key, or false if it is missing or expired.
+	// Callers must not modify the returned slice.
+	Get(key string) ([]byte, bool)
+
+	// Set stores value under key for ttl.
+	Set(key string, value []byte, ttl time.Duration)
+
+	// Delete removes key.
+	Delete(key string)
+
+	// Clear removes all keys.
+	Clear()
+}
+
+// Cache reads through a Store and collapses concurrent fetches of the same
+// key into one. It is safe for concurrent use.
+type Cache struct {
+	store Store
+
+	mu     sync.Mutex
+	flight map[string]*call
+}
+
+// call is a fetch in progress that other callers for the same key wait on.
+type call struct {
+	done  chan struct{}
+	value []byte
+	err   error
+}
+
+// New creates a Cache backed by store.
+func New(store Store) *Cache {
+	return &Cache{store: store, flight: make(map[string]*call)}
+}
+
+// Fetch returns the cached value for key, or calls fetch and caches its
+// result for ttl. Concurrent Fetch calls for a key that is not cached share a
+// single fetch, which keeps running if the caller that started it gives up.
+// Errors are returned but not cached. A ttl of zero or less skips the store
+// but still collapses concurrent fetches.
+func (c *Cache) Fetch(ctx context.Context, key string, ttl time.Duration, fetch func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if ttl > 0 {
+		if value, ok := c.store.Get(key); ok {
+			return value, nil
+		}
+	}
+
+	c.mu.Lock()
+	cl, ok := c.flight[key]
+	if !ok {
+		cl = &call{done: make(chan struct{})}
+		c.flight[key] = cl
+		go c.run(context.WithoutCancel(ctx), key, ttl, cl, fetch)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-cl.done:
+		return cl.value, cl.err
+	}
+}
+
+func (c *Cache) run(ctx context.Context, key string, ttl time.Duration, cl *call, fetch func(ctx context.Context) ([]byte, error)) {
+	cl.value, cl.err = fetch(ctx)
+	if cl.err == nil && ttl > 0 {
+		c.store.Set(key, cl.value, ttl)
+	}
+
+	c.mu.Lock()
+	delete(c.flight, key)
+	c.mu.Unlock()
+	close(cl.done)
+}
+
+// Invalidate removes keys, so the next Fetch for each calls fetch again.
+func (c *Cache) Invalidate(keys ...string) {
+	for _, key := range keys {
+		c.store.Delete(key)
+	}
+}
+
+// Clear removes everything from the store.
+func (c *Cache) Clear() {
+	c.store.Clear()
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache_FetchCachesValue(t *testing.T) {
+	c := New(NewLRU(10))
+	var calls atomic.Int32
+	fetch := func(context.Context) ([]byte, error) {
+		calls.Add(1)
+		return []byte("v"), nil
+	}
+
+	for range 3 {
+		got, err := c.Fetch(context.Background(), "k", time.Hour, fetch)
+		if err != nil || string(got) != "v" {
+			t.Fatalf("Fetch = %q, %v", got, err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("fetch called %d times, want 1", calls.Load())
+	}
+
+	c.Invalidate("k")
+	if _, err := c.Fetch(context.Background(), "k", time.Hour, fetch); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("fetch called %d times after Invalidate, want 2", calls.Load())
+	}
+}
+
+func TestCache_ErrorsAreNotCached(t *testing.T) {
+	c := New(NewLRU(10))
+	wantErr := errors.New("boom")
+	var calls atomic.Int32
+	fetch := func(context.Context) ([]byte, error) {
+		calls.Add(1)
+		return nil, wantErr
+	}
+
+	for range 2 {
+		if _, err := c.Fetch(context.Background(), "k", time.Hour, fetch); !errors.Is(err, wantErr) {
+			t.Fatalf("err = %v, want %v", err, wantErr)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("fetch called %d times, want 2", calls.Load())
+	}
+}
+
+func TestCache_CollapsesConcurrentFetches(t *testing.T) {
+	c := New(NewLRU(10))
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fetch := func(context.Context) ([]byte, error) {
+		calls.Add(1)
+		<-release
+		return []byte("v"), nil
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := c.Fetch(context.Background(), "k", time.Hour, fetch)
+			if err != nil || string(got) != "v" {
+				t.Errorf("Fetch = %q, %v", got, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("fetch called %d times, want 1", calls.Load())
+	}
+}
+
+func TestCache_CallerCancelDoesNotAbortSharedFetch(t *testing.T) {
+	c := New(NewLRU(10))
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) ([]byte, error) {
+		<-release
+		return []byte("v"), ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := c.Fetch(ctx, "k", time.Hour, fetch)
+		errc <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	done := make(chan []byte)
+	go func() {
+		got, _ := c.Fetch(context.Background(), "k", time.Hour, fetch)
+		done <- got
+	}()
+	close(release)
+	if got := <-done; string(got) != "v" {
+		t.Errorf("second caller got %q, want v", got)
+	}
+}
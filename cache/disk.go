@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// diskExt marks the files a Disk store owns, so Clear leaves others alone.
+const diskExt = ".cache"
+
+// Disk is a Store that keeps one file per key in a directory, so cached
+// values survive restarts and can be shared by processes on one host. Files
+// start with the expiry time on a line of its own, followed by the value.
+type Disk struct {
+	dir string
+}
+
+// NewDisk creates a Disk store in dir, creating the directory if needed.
+func NewDisk(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+	return &Disk{dir: dir}, nil
+}
+
+// Get implements Store.
+func (d *Disk) Get(key string) ([]byte, bool) {
+	path := d.path(key)
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	header, value, ok := bytes.Cut(raw, []byte("\n"))
+	if !ok {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	expires, err := strconv.ParseInt(string(header), 10, 64)
+	if err != nil || time.Now().UnixNano() > expires {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	return value, true
+}
+
+// Set implements Store. The file is written under a temporary name and
+// renamed into place, so readers never see half a value.
+func (d *Disk) Set(key string, value []byte, ttl time.Duration) {
+	tmp, err := os.CreateTemp(d.dir, "tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	header := strconv.FormatInt(time.Now().Add(ttl).UnixNano(), 10) + "\n"
+	if _, err := tmp.WriteString(header); err != nil {
+		_ = tmp.Close()
+		return
+	}
+	if _, err := tmp.Write(value); err != nil {
+		_ = tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), d.path(key))
+}
+
+// Delete implements Store.
+func (d *Disk) Delete(key string) {
+	_ = os.Remove(d.path(key))
+}
+
+// Clear implements Store.
+func (d *Disk) Clear() {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), diskExt) {
+			_ = os.Remove(filepath.Join(d.dir, entry.Name()))
+		}
+	}
+}
+
+// path names the file for key by its hash, since keys contain slashes.
+func (d *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+diskExt)
+}
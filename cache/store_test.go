@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStores(t *testing.T) {
+	disk, err := NewDisk(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDisk: %v", err)
+	}
+	stores := map[string]Store{
+		"lru":  NewLRU(10),
+		"disk": disk,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if _, ok := store.Get("/missing"); ok {
+				t.Error("Get on empty store returned a value")
+			}
+
+			store.Set("/_api/trading-critical/rest/orderbook/5247", []byte(`{"a":1}`), time.Hour)
+			got, ok := store.Get("/_api/trading-critical/rest/orderbook/5247")
+			if !ok || string(got) != `{"a":1}` {
+				t.Errorf("Get = %q, %v; want {\"a\":1}, true", got, ok)
+			}
+
+			store.Set("/short", []byte("x"), 10*time.Millisecond)
+			time.Sleep(20 * time.Millisecond)
+			if _, ok := store.Get("/short"); ok {
+				t.Error("expired value returned")
+			}
+
+			store.Delete("/_api/trading-critical/rest/orderbook/5247")
+			if _, ok := store.Get("/_api/trading-critical/rest/orderbook/5247"); ok {
+				t.Error("deleted value returned")
+			}
+
+			store.Set("/a", []byte("a"), time.Hour)
+			store.Set("/b", []byte("b"), time.Hour)
+			store.Clear()
+			if _, ok := store.Get("/a"); ok {
+				t.Error("value returned after Clear")
+			}
+		})
+	}
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	l := NewLRU(2)
+	l.Set("a", []byte("a"), time.Hour)
+	l.Set("b", []byte("b"), time.Hour)
+	l.Get("a") // a is now more recent than b
+	l.Set("c", []byte("c"), time.Hour)
+
+	if _, ok := l.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := l.Get(key); !ok {
+			t.Errorf("%s missing", key)
+		}
+	}
+	if l.Len() != 2 {
+		t.Errorf("Len = %d, want 2", l.Len())
+	}
+}
+
+func TestDisk_SurvivesNewInstance(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewDisk(dir)
+	if err != nil {
+		t.Fatalf("NewDisk: %v", err)
+	}
+	first.Set("/k", []byte("value"), time.Hour)
+
+	second, err := NewDisk(dir)
+	if err != nil {
+		t.Fatalf("NewDisk: %v", err)
+	}
+	if got, ok := second.Get("/k"); !ok || string(got) != "value" {
+		t.Errorf("Get = %q, %v; want value, true", got, ok)
+	}
+}
+
+func TestDisk_ClearKeepsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	d, err := NewDisk(dir)
+	if err != nil {
+		t.Fatalf("NewDisk: %v", err)
+	}
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	d.Set("/k", []byte("value"), time.Hour)
+	d.Clear()
+
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("Clear removed an unrelated file: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Package cassette records the HTTP traffic of a client, including SSE
+// streams, to a file with secrets removed, and replays it without a network.
+// Record once against the real site:
+//
+//	rec := cassette.NewRecorder(nil)
+//	client := avanza.New(avanza.WithHTTPClient(&http.Client{Transport: rec}))
+//	// ... log in, place orders, subscribe ...
+//	if err := rec.Save("testdata/place_order.json"); err != nil { ... }
+//
+// and replay it in tests:
+//
+//	c, err := cassette.Load("testdata/place_order.json")
+//	client := avanza.New(avanza.WithHTTPClient(&http.Client{Transport: cassette.NewReplayer(c)}))
+//
+// Security tokens, cookies, BankID tokens and personal identity numbers are
+// masked in the saved file. The masked values are replayed as they are, which
+// the client accepts like any other token.
+package cassette
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/vmorsell/avanza-sdk-go/internal/redact"
+)
+
+// Cassette is a recorded sequence of HTTP interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request and the response it got.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. URL holds the path and query only, so a
+// cassette replays against any base URL.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response. SSE responses keep their events in Events,
+// one per element without the blank line that ends it, instead of in Body.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	Events []string    `json:"events,omitempty"`
+}
+
+// Load reads a cassette saved by Recorder.Save or Cassette.Save.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is chosen by the test author
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("decode cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path as indented JSON.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cassette: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return nil
+}
+
+// isStream reports whether h describes a server-sent event stream.
+func isStream(h http.Header) bool {
+	return strings.HasPrefix(h.Get("Content-Type"), "text/event-stream")
+}
+
+// redactEvent masks the JSON payload of each data line in an SSE event, and
+// personal identity numbers in the other lines.
+func redactEvent(event string) string {
+	lines := strings.Split(event, "\n")
+	for i, line := range lines {
+		if payload, ok := strings.CutPrefix(line, "data:"); ok {
+			lines[i] = "data: " + string(redact.JSON([]byte(strings.TrimPrefix(payload, " "))))
+			continue
+		}
+		lines[i] = redact.String(line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,174 @@
+package cassette
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/client"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_api/authentication/session/info/session":
+			http.SetCookie(w, &http.Cookie{Name: "AZACSRF", Value: "secret-token"})
+			_, _ = w.Write([]byte(`{"user":{"securityToken":"secret-token","name":"Anna"},"personnummer":"199001011234"}`))
+		case "/_push/trading/orders/":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(w, "event: ORDER\ndata: {\"orderId\":\"1\",\"note\":\"id 19900101-1234\"}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	rec := NewRecorder(nil)
+	recorded := client.NewClient(
+		client.WithBaseURL(server.URL),
+		client.WithHTTPClient(&http.Client{Transport: rec}),
+		client.WithRateLimiter(nil),
+	)
+	live := exercise(t, recorded)
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	server.Close()
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(c.Interactions) != 2 {
+		t.Fatalf("recorded %d interactions, want 2", len(c.Interactions))
+	}
+
+	raw := c.Interactions[0].Response.Body
+	for _, secret := range []string{"secret-token", "199001011234"} {
+		if strings.Contains(raw, secret) {
+			t.Errorf("response body contains %q: %s", secret, raw)
+		}
+	}
+	if got := c.Interactions[0].Response.Header.Get("Set-Cookie"); got != "AZACSRF=[REDACTED]" {
+		t.Errorf("Set-Cookie = %q, want it redacted", got)
+	}
+	events := c.Interactions[1].Response.Events
+	if len(events) != 1 || strings.Contains(events[0], "19900101-1234") || !strings.HasPrefix(events[0], "event: ORDER\n") {
+		t.Errorf("events = %q, want one redacted ORDER event", events)
+	}
+
+	replayer := NewReplayer(c)
+	replayed := client.NewClient(
+		client.WithBaseURL("http://replay.invalid"),
+		client.WithHTTPClient(&http.Client{Transport: replayer}),
+		client.WithRateLimiter(nil),
+	)
+	if got := exercise(t, replayed); got != raw {
+		t.Errorf("replayed body = %s, want %s", got, raw)
+	}
+	if live == raw {
+		t.Error("recorded body was not redacted")
+	}
+	if replayed.SecurityToken() != "[REDACTED]" {
+		t.Errorf("SecurityToken = %q, want the replayed cookie value", replayed.SecurityToken())
+	}
+	if n := replayer.Remaining(); n != 0 {
+		t.Errorf("Remaining = %d, want 0", n)
+	}
+}
+
+// exercise makes one JSON call and reads one event from a stream, returning
+// the JSON body.
+func exercise(t *testing.T, c *client.Client) string {
+	t.Helper()
+	ctx := context.Background()
+
+	resp, err := c.Get(ctx, "/_api/authentication/session/info/session")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.Stream(streamCtx, "/_push/trading/orders/", nil)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	defer stream.Body.Close()
+
+	scanner := bufio.NewScanner(stream.Body)
+	var lines []string
+	for scanner.Scan() && scanner.Text() != "" {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 2 || lines[0] != "event: ORDER" {
+		t.Fatalf("stream lines = %q, want an ORDER event", lines)
+	}
+	return string(body)
+}
+
+func TestReplayer_ReplaysRepeatedRequestsInOrder(t *testing.T) {
+	replayer := NewReplayer(&Cassette{Interactions: []Interaction{
+		{Request: Request{Method: http.MethodGet, URL: "/collect"}, Response: Response{Status: 200, Body: `{"state":"PENDING"}`}},
+		{Request: Request{Method: http.MethodGet, URL: "/collect"}, Response: Response{Status: 200, Body: `{"state":"COMPLETE"}`}},
+	}})
+	hc := &http.Client{Transport: replayer}
+
+	for _, want := range []string{`{"state":"PENDING"}`, `{"state":"COMPLETE"}`} {
+		resp, err := hc.Get("http://replay.invalid/collect")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != want {
+			t.Errorf("body = %s, want %s", body, want)
+		}
+	}
+
+	if _, err := hc.Get("http://replay.invalid/collect"); !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("err = %v, want ErrNoInteraction", err)
+	}
+}
+
+func TestReplayer_StreamStaysOpenUntilCanceled(t *testing.T) {
+	replayer := NewReplayer(&Cassette{Interactions: []Interaction{{
+		Request: Request{Method: http.MethodGet, URL: "/_push/x"},
+		Response: Response{
+			Status: 200,
+			Header: http.Header{"Content-Type": {"text/event-stream"}},
+			Events: []string{"data: {}"},
+		},
+	}}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://replay.invalid/_push/x", nil)
+	resp, err := replayer.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if string(body) != "data: {}\n\n" {
+		t.Errorf("body = %q, want the recorded event", body)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the stream to end with the context", err)
+	}
+}
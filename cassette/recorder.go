@@ -0,0 +1,157 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/vmorsell/avanza-sdk-go/internal/redact"
+)
+
+// Recorder is an http.RoundTripper that records every request it sends and
+// the response it gets. SSE streams are recorded as they are read, so the
+// cassette holds the events the caller consumed before closing the stream.
+// It is safe for concurrent use.
+type Recorder struct {
+	transport http.RoundTripper
+
+	mu      sync.Mutex
+	entries []*entry
+}
+
+// entry is an interaction as recorded, before redaction.
+type entry struct {
+	method     string
+	url        string
+	reqHeader  http.Header
+	reqBody    []byte
+	status     int
+	respHeader http.Header
+	respBody   bytes.Buffer
+}
+
+// NewRecorder returns a Recorder that sends requests through transport, or
+// http.DefaultTransport if it is nil.
+func NewRecorder(transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{transport: transport}
+}
+
+// RoundTrip implements http.RoundTripper. Failed requests are not recorded.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read request body: %w", err)
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &entry{
+		method:     req.Method,
+		url:        req.URL.RequestURI(),
+		reqHeader:  req.Header.Clone(),
+		reqBody:    reqBody,
+		status:     resp.StatusCode,
+		respHeader: resp.Header.Clone(),
+	}
+	r.mu.Lock()
+	r.entries = append(r.entries, e)
+	r.mu.Unlock()
+
+	if isStream(resp.Header) {
+		resp.Body = &streamRecorder{ReadCloser: resp.Body, mu: &r.mu, buf: &e.respBody}
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	r.mu.Lock()
+	e.respBody.Write(body)
+	r.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// Cassette returns the interactions recorded so far, redacted.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := &Cassette{Interactions: make([]Interaction, 0, len(r.entries))}
+	for _, e := range r.entries {
+		c.Interactions = append(c.Interactions, e.interaction())
+	}
+	return c
+}
+
+// Save writes the redacted interactions recorded so far to path.
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+func (e *entry) interaction() Interaction {
+	resp := Response{Status: e.status, Header: redact.Header(e.respHeader)}
+	if isStream(e.respHeader) {
+		resp.Events = events(e.respBody.String())
+	} else {
+		resp.Body = string(redact.JSON(e.respBody.Bytes()))
+	}
+	return Interaction{
+		Request: Request{
+			Method: e.method,
+			URL:    redact.String(e.url),
+			Header: redact.Header(e.reqHeader),
+			Body:   string(redact.JSON(e.reqBody)),
+		},
+		Response: resp,
+	}
+}
+
+// events splits a recorded SSE stream into redacted events. An event the
+// caller had only partly read when the stream was closed is dropped.
+func events(stream string) []string {
+	stream = strings.ReplaceAll(stream, "\r\n", "\n")
+	parts := strings.Split(stream, "\n\n")
+	var out []string
+	for _, part := range parts[:len(parts)-1] {
+		if part = strings.Trim(part, "\n"); part != "" {
+			out = append(out, redactEvent(part))
+		}
+	}
+	return out
+}
+
+// streamRecorder copies what the caller reads from an SSE stream into buf.
+type streamRecorder struct {
+	io.ReadCloser
+	mu  *sync.Mutex
+	buf *bytes.Buffer
+}
+
+func (s *streamRecorder) Read(p []byte) (int, error) {
+	n, err := s.ReadCloser.Read(p)
+	if n > 0 {
+		s.mu.Lock()
+		s.buf.Write(p[:n])
+		s.mu.Unlock()
+	}
+	return n, err
+}
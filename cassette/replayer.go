@@ -0,0 +1,114 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrNoInteraction is returned by Replayer when the cassette has no unused
+// interaction for a request.
+var ErrNoInteraction = errors.New("cassette: no recorded interaction")
+
+// Replayer is an http.RoundTripper that answers requests from a cassette
+// without a network. Each request gets the first interaction with the same
+// method and URL that has not been replayed yet, so repeated calls, like
+// BankID polling, replay in the order they were recorded. It is safe for
+// concurrent use.
+//
+// SSE streams send their recorded events and then stay open until the
+// request is canceled, like a live stream with no further updates.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer returns a Replayer for c.
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{
+		interactions: c.Interactions,
+		used:         make([]bool, len(c.Interactions)),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+	in, ok := r.next(req.Method, req.URL.RequestURI())
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.RequestURI())
+	}
+
+	resp := &http.Response{
+		Status:     fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+		StatusCode: in.Response.Status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     in.Response.Header.Clone(),
+		Request:    req,
+	}
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+
+	if isStream(in.Response.Header) {
+		var stream strings.Builder
+		for _, event := range in.Response.Events {
+			stream.WriteString(event)
+			stream.WriteString("\n\n")
+		}
+		resp.ContentLength = -1
+		resp.Body = io.NopCloser(io.MultiReader(strings.NewReader(stream.String()), openStream{req.Context()}))
+		return resp, nil
+	}
+
+	resp.ContentLength = int64(len(in.Response.Body))
+	resp.Body = io.NopCloser(strings.NewReader(in.Response.Body))
+	return resp, nil
+}
+
+// Remaining returns how many interactions have not been replayed, e.g. to
+// check at the end of a test that the code under test made every call.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, used := range r.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Replayer) next(method, url string) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if !r.used[i] && in.Request.Method == method && in.Request.URL == url {
+			r.used[i] = true
+			return in, true
+		}
+	}
+	return Interaction{}, false
+}
+
+// openStream blocks reads until ctx is done, keeping a replayed SSE stream
+// open after its events.
+type openStream struct {
+	ctx context.Context
+}
+
+func (s openStream) Read([]byte) (int, error) {
+	<-s.ctx.Done()
+	return 0, s.ctx.Err()
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"maps"
 	"net/http"
 	"strings"
@@ -31,6 +32,14 @@ type Client struct {
 	securityToken string
 	userAgent     string
 	rateLimiter   RateLimiter
+	retryPolicy   *RetryPolicy
+	middleware    []Middleware
+
+	streamObservers []StreamObserver
+
+	driftHandlers []DriftHandler
+	driftMu       sync.Mutex
+	driftSeen     map[Drift]struct{}
 }
 
 // BaseURL returns the base URL configured for the client.
@@ -43,6 +52,11 @@ func (c *Client) HTTPClient() *http.Client {
 	return c.httpClient
 }
 
+// RateLimiter returns the rate limiter, or nil if rate limiting is disabled.
+func (c *Client) RateLimiter() RateLimiter {
+	return c.rateLimiter
+}
+
 // SecurityToken returns the current CSRF security token.
 func (c *Client) SecurityToken() string {
 	c.mu.RLock()
@@ -86,11 +100,13 @@ func (c *Client) cookieHeaderLocked() string {
 	return strings.Join(pairs, "; ")
 }
 
-// SetMockCookies sets cookies for testing. AZACSRF is also set as the security token.
-func (c *Client) SetMockCookies(cookies map[string]string) {
+// SetCookies replaces the session cookies, e.g. to restore a previously saved
+// session. AZACSRF is also set as the security token. Passing nil clears the session.
+func (c *Client) SetCookies(cookies map[string]string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.cookies = make(map[string]string, len(cookies))
+	c.securityToken = ""
 	for k, v := range cookies {
 		c.cookies[k] = v
 		if k == "AZACSRF" {
@@ -99,6 +115,11 @@ func (c *Client) SetMockCookies(cookies map[string]string) {
 	}
 }
 
+// SetMockCookies sets cookies for testing. AZACSRF is also set as the security token.
+func (c *Client) SetMockCookies(cookies map[string]string) {
+	c.SetCookies(cookies)
+}
+
 // Option is a functional option for configuring the Client.
 type Option func(*Client)
 
@@ -164,8 +185,6 @@ func NewClient(opts ...Option) *Client {
 // Post sends a POST request. Body is marshaled to JSON.
 // Cookies, security tokens, and rate limiting are handled automatically.
 func (c *Client) Post(ctx context.Context, endpoint string, body any) (*http.Response, error) {
-	url := fmt.Sprintf("%s%s", c.baseURL, endpoint)
-
 	var jsonBody []byte
 	var err error
 	if body != nil {
@@ -175,75 +194,78 @@ func (c *Client) Post(ctx context.Context, endpoint string, body any) (*http.Res
 		}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("new request: %w", err)
-	}
-
-	c.setHeaders(req)
-
-	if c.rateLimiter != nil {
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
-		}
-	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("do: %w", err)
-	}
-
-	c.extractCookies(resp)
-	return resp, nil
+	return c.do(ctx, http.MethodPost, endpoint, jsonBody)
 }
 
 // Get sends a GET request. Cookies, security tokens, and rate limiting are handled automatically.
 func (c *Client) Get(ctx context.Context, endpoint string) (*http.Response, error) {
-	url := fmt.Sprintf("%s%s", c.baseURL, endpoint)
+	return c.do(ctx, http.MethodGet, endpoint, nil)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("new request: %w", err)
-	}
+// Delete sends a DELETE request. Cookies, security tokens, and rate limiting are handled automatically.
+func (c *Client) Delete(ctx context.Context, endpoint string) (*http.Response, error) {
+	return c.do(ctx, http.MethodDelete, endpoint, nil)
+}
 
-	c.setHeaders(req)
+// do sends a request, retrying it as the retry policy allows.
+func (c *Client) do(ctx context.Context, method, endpoint string, body []byte) (*http.Response, error) {
+	attempts := c.retryPolicy.attempts(method, endpoint)
 
-	if c.rateLimiter != nil {
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return nil, fmt.Errorf("rate limiter: %w", err)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, method, endpoint, body, attempt)
+		if attempt >= attempts || ctx.Err() != nil {
+			return resp, err
 		}
-	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("do: %w", err)
-	}
+		wait, retry := c.retryPolicy.delay(attempt, resp, err)
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+			_ = resp.Body.Close()
+		}
 
-	c.extractCookies(resp)
-	return resp, nil
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
 }
 
-// Delete sends a DELETE request. Cookies, security tokens, and rate limiting are handled automatically.
-func (c *Client) Delete(ctx context.Context, endpoint string) (*http.Response, error) {
+// send makes a single attempt through the middleware chain.
+func (c *Client) send(ctx context.Context, method, endpoint string, body []byte, attempt int) (*http.Response, error) {
 	url := fmt.Sprintf("%s%s", c.baseURL, endpoint)
 
-	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	var reqBody io.Reader
+	if method == http.MethodPost {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("new request: %w", err)
 	}
 
 	c.setHeaders(req)
 
+	info := RequestInfo{Method: method, Endpoint: endpoint, Body: body, Attempt: attempt}
 	if c.rateLimiter != nil {
-		if err := c.rateLimiter.Wait(ctx); err != nil {
+		waitStart := time.Now()
+		if err := c.rateLimiter.Wait(context.WithValue(ctx, requestInfoKey{}, info)); err != nil {
 			return nil, fmt.Errorf("rate limiter: %w", err)
 		}
+		info.RateLimitWait = time.Since(waitStart)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.handle(req, info, c.httpClient.Do)
 	if err != nil {
 		return nil, fmt.Errorf("do: %w", err)
 	}
+	if adaptive, ok := c.rateLimiter.(AdaptiveRateLimiter); ok {
+		adaptive.Observe(info, resp)
+	}
 
 	c.extractCookies(resp)
 	return resp, nil
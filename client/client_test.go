@@ -2,6 +2,8 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -420,6 +422,70 @@ func TestSetHeaders_WithCookies(t *testing.T) {
 	defer resp.Body.Close()
 }
 
+func TestHTTPError_Is(t *testing.T) {
+	tests := []struct {
+		status      int
+		expired     bool
+		rateLimited bool
+	}{
+		{http.StatusUnauthorized, true, false},
+		{http.StatusForbidden, true, false},
+		{http.StatusTooManyRequests, false, true},
+		{http.StatusInternalServerError, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			err := fmt.Errorf("get overview: %w", &HTTPError{StatusCode: tt.status})
+			if got := errors.Is(err, ErrSessionExpired); got != tt.expired {
+				t.Errorf("errors.Is(err, ErrSessionExpired) = %v, want %v", got, tt.expired)
+			}
+			if got := errors.Is(err, ErrRateLimited); got != tt.rateLimited {
+				t.Errorf("errors.Is(err, ErrRateLimited) = %v, want %v", got, tt.rateLimited)
+			}
+		})
+	}
+}
+
+func TestNewHTTPError_RetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got := NewHTTPError(resp).RetryAfter; got != 7*time.Second {
+		t.Errorf("RetryAfter = %v, want 7s", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-1", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
 func TestNewHTTPError_SizeLimit(t *testing.T) {
 	// Create a large error response body (larger than maxErrorBodySize)
 	largeBody := make([]byte, 2048) // 2KB
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/vmorsell/avanza-sdk-go/schema"
+)
+
+// Drift is a difference between a live response and the SDK type it
+// decodes into: a field Avanza has added, renamed or dropped, a value whose
+// JSON type changed, or an unexpected null.
+type Drift struct {
+	// Endpoint is the endpoint template the response came from,
+	// e.g. "/_api/trading/rest/orders" or "/_push/trading/orders/".
+	Endpoint string
+
+	// Issue is the difference, with the dotted path of the field,
+	// e.g. "orders[].orderbook.newField".
+	schema.Issue
+}
+
+// DriftHandler receives schema drift found in responses.
+type DriftHandler func(Drift)
+
+// WithDriftHandler checks every decoded response and SSE event against the
+// type it decodes into with schema.Check and calls handler for each issue.
+// Each issue is reported once per endpoint and client. Checking buffers each
+// response body, so it is off by default. Calling WithDriftHandler again
+// adds another handler.
+//
+//	c := client.NewClient(client.WithDriftHandler(func(d client.Drift) {
+//		log.Printf("avanza schema drift: %s %s", d.Endpoint, d.Issue)
+//	}))
+func WithDriftHandler(handler DriftHandler) Option {
+	return func(c *Client) {
+		if handler == nil {
+			return
+		}
+		c.driftHandlers = append(c.driftHandlers, handler)
+		if c.driftSeen == nil {
+			c.driftSeen = make(map[Drift]struct{})
+		}
+	}
+}
+
+// WithDriftLogger reports schema drift to logger at warn level, as
+// WithDriftHandler does.
+//
+//	c := client.NewClient(client.WithDriftLogger(slog.Default()))
+func WithDriftLogger(logger *slog.Logger) Option {
+	if logger == nil {
+		return func(*Client) {}
+	}
+	return WithDriftHandler(func(d Drift) {
+		logger.LogAttrs(context.Background(), slog.LevelWarn, "avanza schema drift",
+			slog.String("endpoint", d.Endpoint),
+			slog.String("kind", string(d.Kind)),
+			slog.String("path", d.Path),
+			slog.String("issue", d.Issue.String()))
+	})
+}
+
+// DecodeJSON decodes the body of resp into v and, when drift reporting is
+// on, reports where the body differs from v's type. Drift is reported even
+// when decoding fails, since a changed value type is a common cause.
+func (c *Client) DecodeJSON(resp *http.Response, v any) error {
+	if len(c.driftHandlers) == 0 {
+		return json.NewDecoder(resp.Body).Decode(v)
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(raw, v)
+	c.CheckDrift(responseEndpoint(resp), raw, v)
+	return err
+}
+
+// CheckDrift reports where raw, taken from endpoint, differs from the type
+// of v, the pointer raw was decoded into. It does nothing unless drift
+// reporting is on.
+func (c *Client) CheckDrift(endpoint string, raw []byte, v any) {
+	if len(c.driftHandlers) == 0 {
+		return
+	}
+	issues, err := schema.Check(raw, v)
+	if err != nil {
+		return // not JSON at all, so not drift
+	}
+
+	endpoint = EndpointTemplate(endpoint)
+	var found []Drift
+	c.driftMu.Lock()
+	for _, issue := range issues {
+		d := Drift{Endpoint: endpoint, Issue: issue}
+		if _, seen := c.driftSeen[d]; !seen {
+			c.driftSeen[d] = struct{}{}
+			found = append(found, d)
+		}
+	}
+	c.driftMu.Unlock()
+
+	for _, d := range found {
+		for _, h := range c.driftHandlers {
+			h(d)
+		}
+	}
+}
+
+// responseEndpoint returns the endpoint resp answers, as the client requested
+// it if known.
+func responseEndpoint(resp *http.Response) string {
+	if resp.Request == nil {
+		return ""
+	}
+	if info, ok := RequestInfoFromContext(resp.Request.Context()); ok {
+		return info.Endpoint
+	}
+	return resp.Request.URL.Path
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmorsell/avanza-sdk-go/schema"
+)
+
+type driftOrder struct {
+	ID        string `json:"id"`
+	Orderbook struct {
+		Name string `json:"name"`
+	} `json:"orderbook"`
+}
+
+type driftOrders struct {
+	Orders []driftOrder `json:"orders"`
+}
+
+func TestDecodeJSON_ReportsDriftOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"orders":[{"id":"1","orderbook":{"name":"Investor B","isin":"SE0015811963"}},{"id":"2","fee":3}],"total":2}`))
+	}))
+	defer server.Close()
+
+	var got []Drift
+	c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithDriftHandler(func(d Drift) {
+		got = append(got, d)
+	}))
+
+	for range 2 {
+		resp, err := c.Get(context.Background(), "/_api/trading/rest/orders/123?x=1")
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		var orders driftOrders
+		err = c.DecodeJSON(resp, &orders)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("DecodeJSON: %v", err)
+		}
+		if len(orders.Orders) != 2 || orders.Orders[0].Orderbook.Name != "Investor B" {
+			t.Fatalf("orders = %+v, want both decoded", orders)
+		}
+	}
+
+	want := []Drift{
+		{Endpoint: "/_api/trading/rest/orders/{id}", Issue: schema.Issue{Kind: schema.Unknown, Path: "orders[].fee"}},
+		{Endpoint: "/_api/trading/rest/orders/{id}", Issue: schema.Issue{Kind: schema.Missing, Path: "orders[].orderbook"}},
+		{Endpoint: "/_api/trading/rest/orders/{id}", Issue: schema.Issue{Kind: schema.Unknown, Path: "orders[].orderbook.isin"}},
+		{Endpoint: "/_api/trading/rest/orders/{id}", Issue: schema.Issue{Kind: schema.Unknown, Path: "total"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("drift = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeJSON_ReportsDriftWhenDecodingFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1,"orderbook":{"name":"Investor B"}}`))
+	}))
+	defer server.Close()
+
+	var got []Drift
+	c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithDriftHandler(func(d Drift) {
+		got = append(got, d)
+	}))
+
+	resp, err := c.Get(context.Background(), "/_api/trading/rest/order")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	var order driftOrder
+	if err := c.DecodeJSON(resp, &order); err == nil {
+		t.Fatal("DecodeJSON: want an error for a number in a string field")
+	}
+
+	want := []Drift{{
+		Endpoint: "/_api/trading/rest/order",
+		Issue:    schema.Issue{Kind: schema.Mismatch, Path: "id", Want: "string", Got: "number"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("drift = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckDrift_OffByDefault(t *testing.T) {
+	c := NewClient()
+	var orders driftOrders
+	// Without a handler there is nothing to report to, and nothing to panic on.
+	c.CheckDrift("/_push/trading/orders/", []byte(`{"unknown":1}`), &orders)
+}
+
+func TestWithDriftLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	c := NewClient(WithDriftLogger(logger))
+	var order driftOrder
+	c.CheckDrift("/_push/trading/orders/", []byte(`{"id":"1","newField":true}`), &order)
+
+	out := buf.String()
+	for _, want := range []string{`"level":"WARN"`, `"msg":"avanza schema drift"`, `"endpoint":"/_push/trading/orders/"`, `"kind":"unknown"`, `"path":"newField"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %s:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"regexp"
+	"strings"
+)
+
+// endpointRoutes are the SDK's endpoints that carry IDs in the path, with
+// each ID segment written as "{id}". Segments are matched by position, so a
+// literal such as "v2" or "collect" is kept whatever it looks like.
+var endpointRoutes = splitRoutes(
+	"/_api/authentication/v2/sessions/bankid/{id}/{id}",
+	"/_api/market-guide/stock/{id}",
+	"/_api/market-guide/stock/{id}/details",
+	"/_api/market-guide/stock/{id}/marketplace",
+	"/_api/market-guide/stock/{id}/orderdepth",
+	"/_api/market-guide/stock/{id}/quote",
+	"/_api/market-guide/certificate/{id}",
+	"/_api/market-guide/certificate/{id}/details",
+	"/_api/market-guide/warrant/{id}",
+	"/_api/market-guide/warrant/{id}/details",
+	"/_api/market-guide/forum/{id}",
+	"/_api/market-guide/news/{id}",
+	"/_api/position-data/positions/{id}",
+	"/_api/price-chart/marketmaker/{id}",
+	"/_api/price-chart/stock/{id}",
+	"/_api/price-chart/stock/{id}/compare/{id}",
+	"/_api/trading-critical/rest/marketdata/{id}",
+	"/_api/trading-critical/rest/orderbook/{id}",
+	"/_api/trading/stoploss/{id}/{id}",
+	"/_push/market-offhours-price/latest/{id}",
+	"/_push/order-depth-web-push/{id}",
+)
+
+// versionSegment matches API version segments such as "v2".
+var versionSegment = regexp.MustCompile(`^v\d+$`)
+
+// EndpointTemplate returns endpoint with its query removed and its IDs
+// replaced by "{id}", for grouping requests in logs and metrics. IDs are
+// found by position in the SDK's known routes. For any other path, a segment
+// containing a digit or an escaped character is treated as an ID, except a
+// version segment such as "v2".
+//
+//	client.EndpointTemplate("/_api/market-guide/stock/5247/details") // "/_api/market-guide/stock/{id}/details"
+func EndpointTemplate(endpoint string) string {
+	path, _, _ := strings.Cut(endpoint, "?")
+
+	segments := strings.Split(path, "/")
+	for _, route := range endpointRoutes {
+		if matchRoute(route, segments) {
+			return strings.Join(route, "/")
+		}
+	}
+	for i, seg := range segments {
+		if strings.ContainsAny(seg, "0123456789%") && !versionSegment.MatchString(seg) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// matchRoute reports whether segments fit route, where "{id}" matches any
+// non-empty segment.
+func matchRoute(route, segments []string) bool {
+	if len(route) != len(segments) {
+		return false
+	}
+	for i, seg := range route {
+		if seg == "{id}" {
+			if segments[i] == "" {
+				return false
+			}
+		} else if seg != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func splitRoutes(routes ...string) [][]string {
+	out := make([][]string, len(routes))
+	for i, r := range routes {
+		out[i] = strings.Split(r, "/")
+	}
+	return out
+}
@@ -2,15 +2,32 @@
 package client
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 const (
 	maxErrorBodySize = 1024
 )
 
+var (
+	// ErrSessionExpired matches an *HTTPError with status 401 or 403: Avanza no
+	// longer accepts the session cookies and the BankID flow must run again.
+	//
+	//	if errors.Is(err, client.ErrSessionExpired) {
+	//	    // log in again
+	//	}
+	ErrSessionExpired = errors.New("session expired")
+
+	// ErrRateLimited matches an *HTTPError with status 429. The error's
+	// RetryAfter says how long Avanza asked callers to wait, if it said.
+	ErrRateLimited = errors.New("rate limited")
+)
+
 // HTTPError represents an HTTP error response.
 //
 //	var httpErr *client.HTTPError
@@ -20,6 +37,10 @@ const (
 type HTTPError struct {
 	StatusCode int
 	Body       string
+
+	// RetryAfter is the delay from the Retry-After header, or 0 if the
+	// response had none.
+	RetryAfter time.Duration
 }
 
 // Error implements the error interface.
@@ -30,6 +51,18 @@ func (e *HTTPError) Error() string {
 	return fmt.Sprintf("HTTP %d", e.StatusCode)
 }
 
+// Is reports whether the error matches ErrSessionExpired or ErrRateLimited,
+// so callers can use errors.Is instead of checking status codes.
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrSessionExpired:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
 // NewHTTPError creates an HTTPError from an HTTP response.
 // Response body is limited to maxErrorBodySize.
 func NewHTTPError(resp *http.Response) *HTTPError {
@@ -38,5 +71,24 @@ func NewHTTPError(resp *http.Response) *HTTPError {
 	return &HTTPError{
 		StatusCode: resp.StatusCode,
 		Body:       string(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter parses a Retry-After value given in seconds or as an HTTP
+// date. Unparseable or past values give 0.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
 	}
+	return 0
 }
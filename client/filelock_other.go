@@ -0,0 +1,39 @@
+//go:build !unix
+
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// staleLockAge is when a lock file left by a crashed process is removed.
+const staleLockAge = 10 * time.Second
+
+// lockFile creates f's name plus ".lock" exclusively, retrying until ctx is
+// done. It is used where flock is not available.
+func lockFile(ctx context.Context, f *os.File) (unlock func(), err error) {
+	path := f.Name() + ".lock"
+	for {
+		lock, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = lock.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/internal/redact"
+)
+
+// WithLogger logs requests and responses, and SSE connects, disconnects and
+// reconnects, to logger. Successful requests are logged at debug level,
+// together with headers and request body; failures and error statuses at
+// warn or error. Cookie values, the security token, BankID tokens and
+// personal identity numbers are always redacted.
+//
+//	c := client.NewClient(client.WithLogger(slog.Default()))
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		if logger == nil {
+			return
+		}
+		c.middleware = append(c.middleware, logRequests(logger))
+		c.streamObservers = append(c.streamObservers, logStream(logger))
+	}
+}
+
+func logRequests(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			info, ok := RequestInfoFromContext(ctx)
+			if !ok {
+				info = RequestInfo{Method: req.Method, Endpoint: req.URL.Path, Attempt: 1}
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", info.Method),
+				slog.String("endpoint", redact.String(info.Endpoint)),
+				slog.Int("attempt", info.Attempt),
+			}
+			if info.Stream {
+				attrs = append(attrs, slog.Bool("stream", true))
+			}
+
+			if logger.Enabled(ctx, slog.LevelDebug) {
+				debug := append(attrs[:len(attrs):len(attrs)],
+					slog.Any("headers", redact.Header(req.Header)),
+					slog.Duration("rate_limit_wait", info.RateLimitWait))
+				if len(info.Body) > 0 {
+					debug = append(debug, slog.String("body", string(redact.JSON(info.Body))))
+				}
+				logger.LogAttrs(ctx, slog.LevelDebug, "avanza request", debug...)
+			}
+
+			start := time.Now()
+			resp, err := next(req)
+			attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+
+			if err != nil {
+				level := slog.LevelError
+				if errors.Is(err, context.Canceled) {
+					level = slog.LevelDebug
+				}
+				attrs = append(attrs, slog.String("error", redact.String(err.Error())))
+				logger.LogAttrs(ctx, level, "avanza request failed", attrs...)
+				return resp, err
+			}
+
+			level := slog.LevelDebug
+			if resp.StatusCode >= 400 {
+				level = slog.LevelWarn
+			}
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			logger.LogAttrs(ctx, level, "avanza response", attrs...)
+			return resp, err
+		}
+	}
+}
+
+func logStream(logger *slog.Logger) StreamObserver {
+	return func(ctx context.Context, endpoint string) func(StreamEvent) {
+		endpoint = redact.String(endpoint)
+		ctx = context.WithoutCancel(ctx)
+
+		return func(ev StreamEvent) {
+			attrs := []slog.Attr{slog.String("endpoint", endpoint)}
+			if ev.Err != nil {
+				attrs = append(attrs, slog.String("error", redact.String(ev.Err.Error())))
+			}
+
+			switch ev.Kind {
+			case StreamConnect:
+				logger.LogAttrs(ctx, slog.LevelInfo, "avanza stream connected", attrs...)
+			case StreamDisconnect:
+				level := slog.LevelInfo
+				if ev.Err != nil {
+					level = slog.LevelWarn
+				}
+				logger.LogAttrs(ctx, level, "avanza stream disconnected", attrs...)
+			case StreamReconnect:
+				attrs = append(attrs, slog.Int("attempt", ev.Attempt), slog.Duration("wait", ev.Wait))
+				logger.LogAttrs(ctx, slog.LevelInfo, "avanza stream reconnecting", attrs...)
+			case StreamClose:
+				level := slog.LevelInfo
+				if ev.Err != nil {
+					level = slog.LevelError
+				}
+				logger.LogAttrs(ctx, level, "avanza stream closed", attrs...)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger_RedactsSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithLogger(logger))
+	c.SetMockCookies(map[string]string{"csid": "csid-secret", "cstoken": "cstoken-secret", "AZACSRF": "csrf-secret"})
+
+	body := map[string]string{"identificationNumber": "199001011234", "qrToken": "qr-secret"}
+	resp, err := c.Post(context.Background(), "/ok", body)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = c.Get(context.Background(), "/fail")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+
+	out := buf.String()
+	for _, secret := range []string{"csid-secret", "cstoken-secret", "csrf-secret", "199001011234", "qr-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log contains %q:\n%s", secret, out)
+		}
+	}
+	for _, want := range []string{`"msg":"avanza request"`, `"msg":"avanza response"`, `"endpoint":"/ok"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %s:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(out, `"level":"WARN","msg":"avanza response"`) || !strings.Contains(out, `"status":500`) {
+		t.Errorf("expected a warning for the 500 response:\n%s", out)
+	}
+}
+
+func TestWithLogger_StreamEvents(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	c := NewClient(WithLogger(logger))
+
+	observe := c.ObserveStream(context.Background(), "/_push/trading/orders/")
+	observe(StreamEvent{Kind: StreamConnect})
+	observe(StreamEvent{Kind: StreamDisconnect, Err: errors.New("stream error: unexpected EOF")})
+	observe(StreamEvent{Kind: StreamReconnect, Attempt: 2})
+
+	out := buf.String()
+	for _, want := range []string{
+		`"level":"INFO","msg":"avanza stream connected"`,
+		`"level":"WARN","msg":"avanza stream disconnected"`,
+		`"msg":"avanza stream reconnecting","endpoint":"/_push/trading/orders/","attempt":2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestWithLogger_Nil(t *testing.T) {
+	c := NewClient(WithLogger(nil))
+	if len(c.middleware) != 0 || len(c.streamObservers) != 0 {
+		t.Error("nil logger should not add middleware or observers")
+	}
+}
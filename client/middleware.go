@@ -0,0 +1,107 @@
+// Package client provides HTTP client functionality for the Avanza API.
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Handler sends a finished request and returns the response.
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler to observe or change traffic. It sees every
+// request made through Get, Post and Delete (once per retry attempt) and every
+// SSE connect, after cookies, security token and other headers are set.
+// RequestInfoFromContext(req.Context()) describes the request.
+//
+//	logging := func(next client.Handler) client.Handler {
+//		return func(req *http.Request) (*http.Response, error) {
+//			info, _ := client.RequestInfoFromContext(req.Context())
+//			resp, err := next(req)
+//			log.Printf("%s %s attempt=%d err=%v", info.Method, info.Endpoint, info.Attempt, err)
+//			return resp, err
+//		}
+//	}
+//	c := client.NewClient(client.WithMiddleware(logging))
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middleware to the client. The first middleware is the
+// outermost: it sees the request first and the response last. Calling
+// WithMiddleware again appends to the chain.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// RequestInfo describes a request passing through the middleware chain.
+type RequestInfo struct {
+	// Method is the HTTP method.
+	Method string
+
+	// Endpoint is the path and query relative to the base URL,
+	// e.g. "/_api/trading/rest/orders".
+	Endpoint string
+
+	// Body is the JSON request body, or nil. Do not modify it.
+	Body []byte
+
+	// Attempt is 1 for the first attempt and counts up on retries.
+	Attempt int
+
+	// Stream is true for long-lived SSE connections.
+	Stream bool
+
+	// RateLimitWait is how long the request waited for the rate limiter
+	// before entering the middleware chain.
+	RateLimitWait time.Duration
+}
+
+type requestInfoKey struct{}
+
+// RequestInfoFromContext returns the RequestInfo attached to a request's
+// context by the client.
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
+
+// handle sends req through the middleware chain, ending with send.
+func (c *Client) handle(req *http.Request, info RequestInfo, send Handler) (*http.Response, error) {
+	req = req.WithContext(context.WithValue(req.Context(), requestInfoKey{}, info))
+
+	h := send
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+	return h(req)
+}
+
+// Stream opens a long-lived GET connection, such as an SSE subscription, to
+// endpoint. header is sent as given; cookies and security token are not
+// added. The request goes through the middleware chain with Stream set, uses
+// the client's transport, and has no overall timeout, so cancel ctx to close it.
+func (c *Client) Stream(ctx context.Context, endpoint string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header = header.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+
+	streamClient := &http.Client{
+		Transport: c.httpClient.Transport,
+		Timeout:   0,
+	}
+
+	info := RequestInfo{Method: http.MethodGet, Endpoint: endpoint, Attempt: 1, Stream: true}
+	resp, err := c.handle(req, info, streamClient.Do)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMiddleware_OrderAndRequestInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var trace []string
+	var seen RequestInfo
+	var seenHeader http.Header
+
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				trace = append(trace, name+" in")
+				mu.Unlock()
+				resp, err := next(req)
+				mu.Lock()
+				trace = append(trace, name+" out")
+				mu.Unlock()
+				return resp, err
+			}
+		}
+	}
+	inspect := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			seen, _ = RequestInfoFromContext(req.Context())
+			seenHeader = req.Header.Clone()
+			return next(req)
+		}
+	}
+
+	c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil),
+		WithMiddleware(record("outer"), record("inner")), WithMiddleware(inspect))
+	c.SetCookies(map[string]string{"csid": "s1", "AZACSRF": "tok"})
+
+	resp, err := c.Post(context.Background(), "/_api/trading-critical/rest/order/new", map[string]int{"volume": 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	want := []string{"outer in", "inner in", "inner out", "outer out"}
+	if strings.Join(trace, ",") != strings.Join(want, ",") {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+
+	if seen.Method != http.MethodPost || seen.Endpoint != "/_api/trading-critical/rest/order/new" {
+		t.Errorf("RequestInfo = %+v", seen)
+	}
+	if string(seen.Body) != `{"volume":3}` {
+		t.Errorf("Body = %s, want {\"volume\":3}", seen.Body)
+	}
+	if seen.Attempt != 1 || seen.Stream {
+		t.Errorf("Attempt = %d, Stream = %v, want 1, false", seen.Attempt, seen.Stream)
+	}
+	if seenHeader.Get("X-SecurityToken") != "tok" || !strings.Contains(seenHeader.Get("Cookie"), "csid=s1") {
+		t.Errorf("middleware saw headers %v, want session headers set", seenHeader)
+	}
+}
+
+func TestMiddleware_SeesEachRetryAttempt(t *testing.T) {
+	server, _ := flakyServer(t, 1, http.StatusBadGateway, nil)
+
+	var attempts []int
+	mw := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			info, _ := RequestInfoFromContext(req.Context())
+			attempts = append(attempts, info.Attempt)
+			return next(req)
+		}
+	}
+
+	c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithRetryPolicy(fastRetries()), WithMiddleware(mw))
+	resp, err := c.Get(context.Background(), "/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("attempts = %v, want [1 2]", attempts)
+	}
+}
+
+func TestMiddleware_ShortCircuit(t *testing.T) {
+	injected := errors.New("injected fault")
+	fault := func(Handler) Handler {
+		return func(*http.Request) (*http.Response, error) {
+			return nil, injected
+		}
+	}
+
+	c := NewClient(WithBaseURL("http://unreachable.invalid"), WithRateLimiter(nil), WithMiddleware(fault))
+	if _, err := c.Get(context.Background(), "/test"); !errors.Is(err, injected) {
+		t.Errorf("err = %v, want injected fault", err)
+	}
+}
+
+func TestStream_UsesMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Accept = %q, want text/event-stream", r.Header.Get("Accept"))
+		}
+		_, _ = io.WriteString(w, "data: {}\n\n")
+	}))
+	defer server.Close()
+
+	var seen RequestInfo
+	mw := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			seen, _ = RequestInfoFromContext(req.Context())
+			return next(req)
+		}
+	}
+
+	c := NewClient(WithBaseURL(server.URL), WithMiddleware(mw))
+	resp, err := c.Stream(context.Background(), "/_push/test", http.Header{"Accept": {"text/event-stream"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if !seen.Stream || seen.Endpoint != "/_push/test" || seen.Method != http.MethodGet {
+		t.Errorf("RequestInfo = %+v, want stream GET /_push/test", seen)
+	}
+}
+
+func TestEndpointTemplate(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{"/_api/market-guide/stock/5247", "/_api/market-guide/stock/{id}"},
+		{"/_api/market-guide/stock/5247/details", "/_api/market-guide/stock/{id}/details"},
+		{"/_api/price-chart/stock/5247/compare/1002994?timePeriod=one_year", "/_api/price-chart/stock/{id}/compare/{id}"},
+		{"/_api/position-data/positions/a%2Fb", "/_api/position-data/positions/{id}"},
+		{"/_api/trading/stoploss/A1/B2", "/_api/trading/stoploss/{id}/{id}"},
+		{"/_api/trading-critical/rest/order/new", "/_api/trading-critical/rest/order/new"},
+		{"/_push/trading/orders/", "/_push/trading/orders/"},
+		{"/_api/authentication/v2/sessions/bankid/collect", "/_api/authentication/v2/sessions/bankid/collect"},
+		{"/_api/authentication/v2/sessions/bankid/tx-abc/customer", "/_api/authentication/v2/sessions/bankid/{id}/{id}"},
+		{"/_api/trading/stoploss/acc/sl", "/_api/trading/stoploss/{id}/{id}"},
+		{"/_api/trading/stoploss/new", "/_api/trading/stoploss/new"},
+		{"/_api/market-guide/stock/ABC/quote", "/_api/market-guide/stock/{id}/quote"},
+		{"/_api/unknown/v3/items/42", "/_api/unknown/v3/items/{id}"},
+	}
+	for _, tt := range tests {
+		if got := EndpointTemplate(tt.endpoint); got != tt.want {
+			t.Errorf("EndpointTemplate(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package client
 
 import (
 	"context"
+	"net/http"
 	"sync"
 	"time"
 )
@@ -13,10 +14,20 @@ const (
 )
 
 // RateLimiter controls request rate. Implementations block until the next request is allowed.
+// The context passed to Wait carries the request's RequestInfo, without
+// RateLimitWait; see RequestInfoFromContext.
 type RateLimiter interface {
 	Wait(ctx context.Context) error
 }
 
+// AdaptiveRateLimiter is a RateLimiter that also sees the response to each
+// request it let through, e.g. to slow down after a 429. Observe must not
+// read or close the response body.
+type AdaptiveRateLimiter interface {
+	RateLimiter
+	Observe(info RequestInfo, resp *http.Response)
+}
+
 // SimpleRateLimiter enforces a minimum interval between requests.
 // It is safe for concurrent use.
 type SimpleRateLimiter struct {
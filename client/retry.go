@@ -0,0 +1,138 @@
+// Package client provides HTTP client functionality for the Avanza API.
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/internal/backoff"
+)
+
+const (
+	// DefaultRetryAttempts is the total number of attempts, including the
+	// first, made by DefaultRetryPolicy.
+	DefaultRetryAttempts = 3
+
+	// DefaultRetryBaseDelay is the wait before the first retry; later retries
+	// double it.
+	DefaultRetryBaseDelay = 200 * time.Millisecond
+
+	// DefaultRetryMaxDelay caps the wait between attempts.
+	DefaultRetryMaxDelay = 5 * time.Second
+)
+
+// placeOrderEndpoint is never retried: a retry after a lost response could
+// place the same order twice.
+const placeOrderEndpoint = "/_api/trading-critical/rest/order/new"
+
+// RetryPolicy retries requests that fail with a network error or a 408, 429
+// or 5xx response. Waits grow exponentially from BaseDelay with random jitter.
+// A Retry-After header replaces the computed wait; if it asks for longer than
+// MaxDelay, the response is returned instead so the caller can decide.
+//
+// Requests to place a new order are never retried, whatever Retryable says.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// BaseDelay is the wait before the first retry. Defaults to DefaultRetryBaseDelay.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the wait between attempts. Defaults to DefaultRetryMaxDelay.
+	MaxDelay time.Duration
+
+	// Retryable reports whether a request may be sent again. Defaults to
+	// IdempotentRequests, which only allows GET.
+	Retryable func(method, endpoint string) bool
+}
+
+// DefaultRetryPolicy returns a policy that retries GET requests up to
+// DefaultRetryAttempts times.
+//
+//	client := NewClient(WithRetryPolicy(DefaultRetryPolicy()))
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: DefaultRetryAttempts,
+		BaseDelay:   DefaultRetryBaseDelay,
+		MaxDelay:    DefaultRetryMaxDelay,
+	}
+}
+
+// IdempotentRequests allows retrying GET requests only. It is the default
+// RetryPolicy.Retryable.
+func IdempotentRequests(method, _ string) bool {
+	return method == http.MethodGet
+}
+
+// WithRetryPolicy retries failed requests according to policy. Retries are off
+// by default; pass nil to turn them off again.
+//
+//	client := NewClient(WithRetryPolicy(DefaultRetryPolicy()))
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// attempts returns how many times a request may be sent.
+func (p *RetryPolicy) attempts(method, endpoint string) int {
+	if p == nil || p.MaxAttempts < 2 {
+		return 1
+	}
+	path, _, _ := strings.Cut(endpoint, "?")
+	if path == placeOrderEndpoint {
+		return 1
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IdempotentRequests
+	}
+	if !retryable(method, endpoint) {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) baseDelay() time.Duration {
+	if p.BaseDelay <= 0 {
+		return DefaultRetryBaseDelay
+	}
+	return p.BaseDelay
+}
+
+func (p *RetryPolicy) maxDelay() time.Duration {
+	if p.MaxDelay <= 0 {
+		return DefaultRetryMaxDelay
+	}
+	return p.MaxDelay
+}
+
+// delay returns how long to wait before retrying after attempt (1-based)
+// produced resp or err, or false if the failure should not be retried.
+func (p *RetryPolicy) delay(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		// Only transport failures are transient; marshal, request and rate
+		// limiter errors would fail the same way again.
+		var urlErr *url.Error
+		if !errors.As(err, &urlErr) {
+			return 0, false
+		}
+	} else if !backoff.RecoverableStatus(resp.StatusCode) {
+		return 0, false
+	}
+
+	wait := backoff.Jitter(backoff.Exponential(p.baseDelay(), attempt-1, p.maxDelay()))
+	if resp != nil {
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); retryAfter > 0 {
+			if retryAfter > p.maxDelay() {
+				return 0, false
+			}
+			wait = retryAfter
+		}
+	}
+	return wait, true
+}
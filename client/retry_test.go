@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetries is a policy with short waits so tests stay quick.
+func fastRetries() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond}
+}
+
+// flakyServer fails the first `failures` requests with status, then answers 200.
+func flakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestRetry_GetRecoversFromTransientError(t *testing.T) {
+	server, calls := flakyServer(t, 2, http.StatusBadGateway, nil)
+	c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithRetryPolicy(fastRetries()))
+
+	resp, err := c.Get(context.Background(), "/_api/position-data/positions")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", resp.StatusCode)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+}
+
+func TestRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	server, calls := flakyServer(t, 10, http.StatusServiceUnavailable, nil)
+	c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithRetryPolicy(fastRetries()))
+
+	resp, err := c.Get(context.Background(), "/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("StatusCode = %d, want 503", resp.StatusCode)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+}
+
+func TestRetry_NotApplied(t *testing.T) {
+	always := fastRetries()
+	always.Retryable = func(string, string) bool { return true }
+
+	tests := []struct {
+		name   string
+		policy *RetryPolicy
+		status int
+		send   func(c *Client) (*http.Response, error)
+	}{
+		{"no policy", nil, http.StatusBadGateway, func(c *Client) (*http.Response, error) {
+			return c.Get(context.Background(), "/test")
+		}},
+		{"post by default", fastRetries(), http.StatusBadGateway, func(c *Client) (*http.Response, error) {
+			return c.Post(context.Background(), "/_api/trading-critical/rest/order/delete", nil)
+		}},
+		{"place order even when allowed", always, http.StatusBadGateway, func(c *Client) (*http.Response, error) {
+			return c.Post(context.Background(), "/_api/trading-critical/rest/order/new", nil)
+		}},
+		{"client error", fastRetries(), http.StatusNotFound, func(c *Client) (*http.Response, error) {
+			return c.Get(context.Background(), "/test")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := flakyServer(t, 10, tt.status, nil)
+			c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithRetryPolicy(tt.policy))
+
+			resp, err := tt.send(c)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if got := calls.Load(); got != 1 {
+				t.Errorf("calls = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestRetry_CustomRetryableAllowsPost(t *testing.T) {
+	server, calls := flakyServer(t, 1, http.StatusBadGateway, nil)
+	policy := fastRetries()
+	policy.Retryable = func(method, endpoint string) bool {
+		return method == http.MethodGet || endpoint == "/_api/trading/rest/orders/search"
+	}
+	c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithRetryPolicy(policy))
+
+	resp, err := c.Post(context.Background(), "/_api/trading/rest/orders/search", map[string]string{"q": "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestRetry_RetryAfter(t *testing.T) {
+	t.Run("honoured", func(t *testing.T) {
+		server, calls := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+		policy := fastRetries()
+		policy.MaxDelay = 2 * time.Second
+		c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithRetryPolicy(policy))
+
+		start := time.Now()
+		resp, err := c.Get(context.Background(), "/test")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Errorf("retried after %v, want at least 1s", elapsed)
+		}
+		if got := calls.Load(); got != 2 {
+			t.Errorf("calls = %d, want 2", got)
+		}
+	})
+
+	t.Run("longer than MaxDelay", func(t *testing.T) {
+		server, calls := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"60"}})
+		c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithRetryPolicy(fastRetries()))
+
+		resp, err := c.Get(context.Background(), "/test")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("StatusCode = %d, want 429", resp.StatusCode)
+		}
+		if got := calls.Load(); got != 1 {
+			t.Errorf("calls = %d, want 1", got)
+		}
+	})
+}
+
+func TestRetry_ConnectionError(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithRetryPolicy(fastRetries()))
+
+	resp, err := c.Get(context.Background(), "/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestRetry_ContextCancelledDuringWait(t *testing.T) {
+	server, calls := flakyServer(t, 10, http.StatusBadGateway, nil)
+	policy := &RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second}
+	c := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil), WithRetryPolicy(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Get(ctx, "/test"); err == nil {
+		t.Fatal("expected context error")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultSharedRateLimitKey is the SharedRateLimiter key used when none is set.
+const DefaultSharedRateLimitKey = "avanza"
+
+// SharedStore keeps rate limiter state where several processes can reach it,
+// e.g. a file on the local host or a Redis-compatible server.
+type SharedStore interface {
+	// Reserve atomically claims the next free slot for key and returns when
+	// the caller may send: the later of now and the previous slot plus
+	// interval. The next slot is interval after the returned time.
+	Reserve(ctx context.Context, key string, interval time.Duration) (time.Time, error)
+}
+
+// SharedRateLimiter enforces a minimum interval between requests across all
+// processes that share its Store and Key, so several bots on one login keep
+// a single request budget. It is safe for concurrent use.
+//
+//	limiter := &client.SharedRateLimiter{
+//		Store:    &client.FileSharedStore{Path: "/tmp/avanza-ratelimit.json"},
+//		Interval: 200 * time.Millisecond,
+//	}
+//	c := client.NewClient(client.WithRateLimiter(limiter))
+type SharedRateLimiter struct {
+	// Store holds the shared state. Required.
+	Store SharedStore
+
+	// Key identifies the budget within Store. Defaults to DefaultSharedRateLimitKey.
+	Key string
+
+	// Interval is the minimum time between requests from all processes
+	// together. Defaults to DefaultRateLimitInterval.
+	Interval time.Duration
+}
+
+// Wait blocks until the shared budget allows a request to proceed.
+func (r *SharedRateLimiter) Wait(ctx context.Context) error {
+	if r.Store == nil {
+		return errors.New("shared rate limiter: no store")
+	}
+	key := r.Key
+	if key == "" {
+		key = DefaultSharedRateLimitKey
+	}
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultRateLimitInterval
+	}
+
+	at, err := r.Store.Reserve(ctx, key, interval)
+	if err != nil {
+		return fmt.Errorf("reserve: %w", err)
+	}
+
+	wait := time.Until(at)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve returns the slot for a request at now, given the next free slot,
+// and the next free slot after it.
+func reserve(next, now time.Time, interval time.Duration) (at, after time.Time) {
+	at = now
+	if next.After(now) {
+		at = next
+	}
+	return at, at.Add(interval)
+}
+
+// MemorySharedStore is a SharedStore for limiters in one process, e.g.
+// several clients or tests. It is safe for concurrent use.
+type MemorySharedStore struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// NewMemorySharedStore creates an empty MemorySharedStore.
+func NewMemorySharedStore() *MemorySharedStore {
+	return &MemorySharedStore{next: make(map[string]time.Time)}
+}
+
+// Reserve implements SharedStore.
+func (s *MemorySharedStore) Reserve(_ context.Context, key string, interval time.Duration) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.next == nil {
+		s.next = make(map[string]time.Time)
+	}
+	at, after := reserve(s.next[key], time.Now(), interval)
+	s.next[key] = after
+	return at, nil
+}
+
+// FileSharedStore is a SharedStore for processes on one host. The state is
+// a small JSON file, and each reservation holds an exclusive lock on it.
+// Every process must use the same Path.
+type FileSharedStore struct {
+	Path string
+}
+
+// Reserve implements SharedStore.
+func (s *FileSharedStore) Reserve(ctx context.Context, key string, interval time.Duration) (time.Time, error) {
+	f, err := os.OpenFile(s.Path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("open rate limit file: %w", err)
+	}
+	defer f.Close()
+
+	unlock, err := lockFile(ctx, f)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("lock rate limit file: %w", err)
+	}
+	defer unlock()
+
+	state := map[string]time.Time{}
+	raw, err := io.ReadAll(f)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read rate limit file: %w", err)
+	}
+	if len(raw) > 0 {
+		// A damaged file only costs one reset budget; start over.
+		if err := json.Unmarshal(raw, &state); err != nil {
+			state = map[string]time.Time{}
+		}
+	}
+
+	at, after := reserve(state[key], time.Now(), interval)
+	state[key] = after
+
+	raw, err = json.Marshal(state)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("encode rate limit state: %w", err)
+	}
+	if err := f.Truncate(0); err != nil {
+		return time.Time{}, fmt.Errorf("write rate limit file: %w", err)
+	}
+	if _, err := f.WriteAt(raw, 0); err != nil {
+		return time.Time{}, fmt.Errorf("write rate limit file: %w", err)
+	}
+	return at, nil
+}
+
+// lockPollInterval is how often lockFile retries a held lock.
+const lockPollInterval = time.Millisecond
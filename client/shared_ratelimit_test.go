@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSharedRateLimiter_StoresShareBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+	memory := NewMemorySharedStore()
+
+	stores := map[string][2]SharedStore{
+		"memory": {memory, memory},
+		// Two stores on one path stand in for two processes.
+		"file": {&FileSharedStore{Path: path}, &FileSharedStore{Path: path}},
+	}
+
+	for name, pair := range stores {
+		t.Run(name, func(t *testing.T) {
+			a := &SharedRateLimiter{Store: pair[0], Interval: 20 * time.Millisecond}
+			b := &SharedRateLimiter{Store: pair[1], Interval: 20 * time.Millisecond}
+
+			start := time.Now()
+			var wg sync.WaitGroup
+			for _, l := range []*SharedRateLimiter{a, b, a, b, a, b} {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := l.Wait(context.Background()); err != nil {
+						t.Errorf("Wait: %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			// Six requests at 20ms apart: the last goes 100ms after the first.
+			if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+				t.Errorf("six requests took %v, want at least 100ms", elapsed)
+			}
+		})
+	}
+}
+
+func TestSharedRateLimiter_KeysAreIndependent(t *testing.T) {
+	store := NewMemorySharedStore()
+	a := &SharedRateLimiter{Store: store, Key: "login-a", Interval: time.Hour}
+	b := &SharedRateLimiter{Store: store, Key: "login-b", Interval: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := a.Wait(ctx); err != nil {
+		t.Fatalf("first wait for a: %v", err)
+	}
+	if err := b.Wait(ctx); err != nil {
+		t.Fatalf("first wait for b: %v", err)
+	}
+	if err := a.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second wait for a: err = %v, want deadline exceeded", err)
+	}
+}
+
+func TestSharedRateLimiter_NoStore(t *testing.T) {
+	l := &SharedRateLimiter{}
+	if err := l.Wait(context.Background()); err == nil {
+		t.Error("expected error without store")
+	}
+}
+
+func TestFileSharedStore_RecoversFromDamagedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimit.json")
+	store := &FileSharedStore{Path: path}
+	if _, err := store.Reserve(context.Background(), "k", time.Second); err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	at, err := store.Reserve(context.Background(), "k", time.Second)
+	if err != nil {
+		t.Fatalf("Reserve after damage: %v", err)
+	}
+	if time.Until(at) > 10*time.Millisecond {
+		t.Errorf("slot after reset is %v away, want now", time.Until(at))
+	}
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// StreamEventKind is a step in the life of an SSE subscription.
+type StreamEventKind string
+
+const (
+	StreamConnect    StreamEventKind = "connect"    // Connected and receiving events
+	StreamDisconnect StreamEventKind = "disconnect" // An established connection ended
+	StreamReconnect  StreamEventKind = "reconnect"  // Waiting before the next connection attempt
+	StreamClose      StreamEventKind = "close"      // Subscription stopped for good
+)
+
+// StreamEvent reports a connection change of an SSE subscription.
+type StreamEvent struct {
+	Kind     StreamEventKind
+	Endpoint string
+
+	// Attempt counts consecutive reconnects since the last successful
+	// connect. Set for StreamReconnect.
+	Attempt int
+
+	// Wait is the delay before the next connection attempt. Set for
+	// StreamReconnect.
+	Wait time.Duration
+
+	// Err is why the connection ended or failed, if known.
+	Err error
+
+	Time time.Time
+}
+
+// StreamObserver is called once when an SSE subscription starts, with the
+// context the subscription was started with, and returns a function that
+// receives the subscription's StreamEvents. The last event is always
+// StreamClose. The returned function is called from a single goroutine and
+// must not block.
+//
+//	observer := func(ctx context.Context, endpoint string) func(client.StreamEvent) {
+//		return func(ev client.StreamEvent) {
+//			log.Printf("%s %s err=%v", endpoint, ev.Kind, ev.Err)
+//		}
+//	}
+//	c := client.NewClient(client.WithStreamObserver(observer))
+type StreamObserver func(ctx context.Context, endpoint string) func(StreamEvent)
+
+// WithStreamObserver adds observers of SSE subscriptions. Calling
+// WithStreamObserver again appends.
+func WithStreamObserver(observers ...StreamObserver) Option {
+	return func(c *Client) {
+		c.streamObservers = append(c.streamObservers, observers...)
+	}
+}
+
+// ObserveStream starts observing a subscription to endpoint and returns the
+// function to report its events to. It is a no-op without stream observers.
+func (c *Client) ObserveStream(ctx context.Context, endpoint string) func(StreamEvent) {
+	var fns []func(StreamEvent)
+	for _, obs := range c.streamObservers {
+		if fn := obs(ctx, endpoint); fn != nil {
+			fns = append(fns, fn)
+		}
+	}
+
+	return func(ev StreamEvent) {
+		if ev.Endpoint == "" {
+			ev.Endpoint = endpoint
+		}
+		if ev.Time.IsZero() {
+			ev.Time = time.Now()
+		}
+		for _, fn := range fns {
+			fn(ev)
+		}
+	}
+}
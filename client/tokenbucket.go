@@ -0,0 +1,376 @@
+package client
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/internal/backoff"
+)
+
+const (
+	// DefaultRateLimitBurst is how many requests TokenBucketLimiter lets
+	// through at once before spacing them out.
+	DefaultRateLimitBurst = 5
+
+	// DefaultRateLimitBackoff is how long TokenBucketLimiter pauses all
+	// requests after a 429 without Retry-After. Consecutive 429s double it.
+	DefaultRateLimitBackoff = time.Second
+
+	// DefaultMaxRateLimitBackoff caps the pause after a 429.
+	DefaultMaxRateLimitBackoff = 30 * time.Second
+)
+
+// EndpointClass groups endpoints that share a rate limit budget.
+type EndpointClass string
+
+const (
+	EndpointClassTrading    EndpointClass = "trading"     // Orders, stop losses, fees and order validation
+	EndpointClassMarketData EndpointClass = "market-data" // Market guide, price charts, order books and search
+	EndpointClassAccount    EndpointClass = "account"     // Accounts, positions, transactions and everything else
+)
+
+var (
+	tradingPrefixes = []string{
+		"/_api/trading-critical/rest/order/",
+		"/_api/trading-critical/rest/accounts",
+		"/_api/trading/",
+	}
+	marketDataPrefixes = []string{
+		"/_api/market-guide/",
+		"/_api/price-chart/",
+		"/_api/search/",
+		"/_api/trading-critical/rest/orderbook/",
+		"/_api/trading-critical/rest/marketdata/",
+		"/_push/",
+	}
+)
+
+// ClassifyEndpoint returns the class of endpoint. It is the default
+// TokenBucketConfig.Classify.
+func ClassifyEndpoint(_, endpoint string) EndpointClass {
+	for _, prefix := range tradingPrefixes {
+		if strings.HasPrefix(endpoint, prefix) {
+			return EndpointClassTrading
+		}
+	}
+	for _, prefix := range marketDataPrefixes {
+		if strings.HasPrefix(endpoint, prefix) {
+			return EndpointClassMarketData
+		}
+	}
+	return EndpointClassAccount
+}
+
+// OrderRequests reports whether a request places, modifies or cancels an
+// order or stop loss. It is the default TokenBucketConfig.Priority.
+func OrderRequests(method, endpoint string) bool {
+	path, _, _ := strings.Cut(endpoint, "?")
+	switch path {
+	case placeOrderEndpoint,
+		"/_api/trading-critical/rest/order/modify",
+		"/_api/trading-critical/rest/order/delete",
+		"/_api/trading/stoploss/new",
+		"/_api/trading/stoploss/modify":
+		return true
+	}
+	return method == http.MethodDelete && strings.HasPrefix(path, "/_api/trading/stoploss/")
+}
+
+// Budget is a token bucket: up to Burst requests go at once, after which one
+// more is allowed per Interval.
+type Budget struct {
+	Interval time.Duration
+	Burst    int
+}
+
+// TokenBucketConfig configures a TokenBucketLimiter.
+type TokenBucketConfig struct {
+	// Global limits all requests together. Defaults to one request per
+	// DefaultRateLimitInterval with a burst of DefaultRateLimitBurst.
+	Global Budget
+
+	// Classes limits each endpoint class on top of Global. Classes without a
+	// budget are limited by Global only.
+	Classes map[EndpointClass]Budget
+
+	// Classify returns a request's class. Defaults to ClassifyEndpoint.
+	Classify func(method, endpoint string) EndpointClass
+
+	// Priority reports whether a request goes ahead of queued requests that
+	// are not. Defaults to OrderRequests.
+	Priority func(method, endpoint string) bool
+
+	// Backoff is how long all requests pause after a 429 without Retry-After.
+	// Consecutive 429s double it. Defaults to DefaultRateLimitBackoff.
+	Backoff time.Duration
+
+	// MaxBackoff caps the pause after a 429, including one asked for with
+	// Retry-After. Defaults to DefaultMaxRateLimitBackoff.
+	MaxBackoff time.Duration
+}
+
+// DefaultTokenBucketConfig returns a config with separate budgets for
+// trading, market data and account requests, so a burst of market data
+// lookups cannot use up the room needed for orders.
+func DefaultTokenBucketConfig() *TokenBucketConfig {
+	return &TokenBucketConfig{
+		Global: Budget{Interval: DefaultRateLimitInterval, Burst: DefaultRateLimitBurst},
+		Classes: map[EndpointClass]Budget{
+			EndpointClassTrading:    {Interval: DefaultRateLimitInterval, Burst: DefaultRateLimitBurst},
+			EndpointClassMarketData: {Interval: 250 * time.Millisecond, Burst: DefaultRateLimitBurst},
+			EndpointClassAccount:    {Interval: 250 * time.Millisecond, Burst: DefaultRateLimitBurst},
+		},
+	}
+}
+
+// TokenBucketLimiter is an AdaptiveRateLimiter with a global budget and one
+// budget per endpoint class. Waiting requests are served in order, except
+// that priority requests (by default placing, modifying and cancelling
+// orders) go ahead of the others. After a 429 all requests pause, for as long
+// as Retry-After asks or with exponential backoff. It is safe for concurrent use.
+//
+//	limiter := client.NewTokenBucketLimiter(nil)
+//	c := client.NewClient(client.WithRateLimiter(limiter))
+type TokenBucketLimiter struct {
+	classify   func(method, endpoint string) EndpointClass
+	priority   func(method, endpoint string) bool
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	mu         sync.Mutex
+	global     *bucket
+	classes    map[EndpointClass]*bucket
+	queue      []*bucketWaiter
+	timer      *time.Timer
+	pauseUntil time.Time
+	throttled  int // consecutive 429s
+}
+
+// NewTokenBucketLimiter creates a TokenBucketLimiter. A nil cfg uses
+// DefaultTokenBucketConfig.
+func NewTokenBucketLimiter(cfg *TokenBucketConfig) *TokenBucketLimiter {
+	if cfg == nil {
+		cfg = DefaultTokenBucketConfig()
+	}
+
+	now := time.Now()
+	global := cfg.Global
+	if global.Interval <= 0 {
+		global.Interval = DefaultRateLimitInterval
+	}
+	if global.Burst <= 0 {
+		global.Burst = DefaultRateLimitBurst
+	}
+
+	l := &TokenBucketLimiter{
+		classify:   cfg.Classify,
+		priority:   cfg.Priority,
+		backoff:    cfg.Backoff,
+		maxBackoff: cfg.MaxBackoff,
+		global:     newBucket(global, now),
+		classes:    make(map[EndpointClass]*bucket, len(cfg.Classes)),
+	}
+	if l.classify == nil {
+		l.classify = ClassifyEndpoint
+	}
+	if l.priority == nil {
+		l.priority = OrderRequests
+	}
+	if l.backoff <= 0 {
+		l.backoff = DefaultRateLimitBackoff
+	}
+	if l.maxBackoff <= 0 {
+		l.maxBackoff = DefaultMaxRateLimitBackoff
+	}
+	for class, budget := range cfg.Classes {
+		if budget.Interval > 0 {
+			l.classes[class] = newBucket(budget, now)
+		}
+	}
+	return l
+}
+
+type bucketWaiter struct {
+	class    EndpointClass
+	priority bool
+	ready    chan struct{}
+	granted  bool
+}
+
+// Wait blocks until the request described by ctx's RequestInfo may go.
+// Requests without RequestInfo count as account requests.
+func (l *TokenBucketLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	info, _ := RequestInfoFromContext(ctx)
+	w := &bucketWaiter{
+		class:    l.classify(info.Method, info.Endpoint),
+		priority: l.priority(info.Method, info.Endpoint),
+		ready:    make(chan struct{}),
+	}
+
+	l.mu.Lock()
+	l.enqueueLocked(w)
+	l.dispatchLocked()
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !w.granted {
+			l.removeLocked(w)
+		}
+		return ctx.Err()
+	}
+}
+
+// Observe pauses all requests after a 429, and resets the backoff after a
+// successful response.
+func (l *TokenBucketLimiter) Observe(_ RequestInfo, resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		if resp.StatusCode < 400 {
+			l.throttled = 0
+		}
+		return
+	}
+
+	now := time.Now()
+	pause := backoff.Exponential(l.backoff, l.throttled, l.maxBackoff)
+	if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), now); retryAfter > 0 {
+		pause = min(retryAfter, l.maxBackoff)
+	}
+	l.throttled++
+
+	if until := now.Add(pause); until.After(l.pauseUntil) {
+		l.pauseUntil = until
+	}
+	l.dispatchLocked()
+}
+
+// enqueueLocked adds w behind the waiters of the same or higher priority.
+func (l *TokenBucketLimiter) enqueueLocked(w *bucketWaiter) {
+	if !w.priority {
+		l.queue = append(l.queue, w)
+		return
+	}
+	i := 0
+	for i < len(l.queue) && l.queue[i].priority {
+		i++
+	}
+	l.queue = append(l.queue, nil)
+	copy(l.queue[i+1:], l.queue[i:])
+	l.queue[i] = w
+}
+
+func (l *TokenBucketLimiter) removeLocked(w *bucketWaiter) {
+	for i, q := range l.queue {
+		if q == w {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+func (l *TokenBucketLimiter) dispatch() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.dispatchLocked()
+}
+
+// dispatchLocked lets through every queued request that has tokens, in queue
+// order, and schedules the next dispatch for when more tokens are due.
+func (l *TokenBucketLimiter) dispatchLocked() {
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	if len(l.queue) == 0 {
+		return
+	}
+
+	now := time.Now()
+	if now.Before(l.pauseUntil) {
+		l.timer = time.AfterFunc(l.pauseUntil.Sub(now), l.dispatch)
+		return
+	}
+
+	l.global.refill(now)
+	for _, b := range l.classes {
+		b.refill(now)
+	}
+
+	next := time.Duration(-1)
+	remaining := l.queue[:0]
+	for _, w := range l.queue {
+		class := l.classes[w.class]
+		wait := l.global.wait()
+		if class != nil {
+			wait = max(wait, class.wait())
+		}
+		if wait == 0 {
+			l.global.take()
+			if class != nil {
+				class.take()
+			}
+			w.granted = true
+			close(w.ready)
+			continue
+		}
+		remaining = append(remaining, w)
+		if next < 0 || wait < next {
+			next = wait
+		}
+	}
+	clear(l.queue[len(remaining):])
+	l.queue = remaining
+
+	if len(l.queue) > 0 {
+		l.timer = time.AfterFunc(next, l.dispatch)
+	}
+}
+
+// bucket holds up to burst tokens and gains one per interval.
+type bucket struct {
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+func newBucket(b Budget, now time.Time) *bucket {
+	burst := float64(max(b.Burst, 1))
+	return &bucket{interval: b.Interval, burst: burst, tokens: burst, last: now}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.burst, b.tokens+float64(elapsed)/float64(b.interval))
+		b.last = now
+	}
+}
+
+// wait returns how long until a token is available, as of the last refill.
+func (b *bucket) wait() time.Duration {
+	// Allow for rounding, or the last nanosecond could take an extra timer.
+	const epsilon = 1e-9
+	if b.tokens >= 1-epsilon {
+		return 0
+	}
+	return time.Duration(math.Ceil((1 - b.tokens) * float64(b.interval)))
+}
+
+func (b *bucket) take() {
+	b.tokens--
+}
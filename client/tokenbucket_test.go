@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func requestCtx(method, endpoint string) context.Context {
+	return context.WithValue(context.Background(), requestInfoKey{}, RequestInfo{Method: method, Endpoint: endpoint, Attempt: 1})
+}
+
+func TestClassifyEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     EndpointClass
+	}{
+		{"/_api/trading-critical/rest/order/new", EndpointClassTrading},
+		{"/_api/trading/stoploss/new", EndpointClassTrading},
+		{"/_api/trading/rest/orders", EndpointClassTrading},
+		{"/_api/market-guide/stock/5247", EndpointClassMarketData},
+		{"/_api/price-chart/stock/5247?timePeriod=today", EndpointClassMarketData},
+		{"/_api/trading-critical/rest/orderbook/5247", EndpointClassMarketData},
+		{"/_api/search/filtered-search", EndpointClassMarketData},
+		{"/_api/account-overview/overview/categorizedAccounts", EndpointClassAccount},
+		{"/_api/authentication/session/info/session", EndpointClassAccount},
+	}
+	for _, tt := range tests {
+		if got := ClassifyEndpoint(http.MethodGet, tt.endpoint); got != tt.want {
+			t.Errorf("ClassifyEndpoint(%q) = %s, want %s", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestOrderRequests(t *testing.T) {
+	tests := []struct {
+		method   string
+		endpoint string
+		want     bool
+	}{
+		{http.MethodPost, "/_api/trading-critical/rest/order/new", true},
+		{http.MethodPost, "/_api/trading-critical/rest/order/delete", true},
+		{http.MethodPost, "/_api/trading-critical/rest/order/modify", true},
+		{http.MethodPost, "/_api/trading/stoploss/new", true},
+		{http.MethodDelete, "/_api/trading/stoploss/acc/sl1", true},
+		{http.MethodGet, "/_api/trading/stoploss/", false},
+		{http.MethodGet, "/_api/trading/rest/orders", false},
+		{http.MethodGet, "/_api/market-guide/stock/5247", false},
+	}
+	for _, tt := range tests {
+		if got := OrderRequests(tt.method, tt.endpoint); got != tt.want {
+			t.Errorf("OrderRequests(%s %s) = %v, want %v", tt.method, tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestTokenBucketLimiter_Burst(t *testing.T) {
+	l := NewTokenBucketLimiter(&TokenBucketConfig{Global: Budget{Interval: 50 * time.Millisecond, Burst: 3}})
+	ctx := requestCtx(http.MethodGet, "/_api/account-overview/overview/categorizedAccounts")
+
+	start := time.Now()
+	for range 3 {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("burst took %v, want immediate", elapsed)
+	}
+
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("request after burst took %v, want about 50ms", elapsed)
+	}
+}
+
+func TestTokenBucketLimiter_ClassBudgetsAreSeparate(t *testing.T) {
+	l := NewTokenBucketLimiter(&TokenBucketConfig{
+		Global: Budget{Interval: time.Millisecond, Burst: 10},
+		Classes: map[EndpointClass]Budget{
+			EndpointClassMarketData: {Interval: time.Hour, Burst: 1},
+		},
+	})
+	market := requestCtx(http.MethodGet, "/_api/market-guide/stock/5247")
+
+	if err := l.Wait(market); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+
+	blocked, cancel := context.WithTimeout(market, 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(blocked); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second market data request: err = %v, want deadline exceeded", err)
+	}
+
+	start := time.Now()
+	if err := l.Wait(requestCtx(http.MethodPost, "/_api/trading-critical/rest/order/new")); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("order waited %v behind market data budget", elapsed)
+	}
+}
+
+func TestTokenBucketLimiter_PriorityJumpsQueue(t *testing.T) {
+	l := NewTokenBucketLimiter(&TokenBucketConfig{Global: Budget{Interval: 30 * time.Millisecond, Burst: 1}})
+	if err := l.Wait(requestCtx(http.MethodGet, "/_api/market-guide/stock/1")); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	wait := func(name, method, endpoint string) {
+		defer wg.Done()
+		if err := l.Wait(requestCtx(method, endpoint)); err != nil {
+			t.Errorf("Wait %s: %v", name, err)
+			return
+		}
+		mu.Lock()
+		order = append(order, name)
+		mu.Unlock()
+	}
+
+	for _, name := range []string{"market1", "market2", "market3"} {
+		wg.Add(1)
+		go wait(name, http.MethodGet, "/_api/market-guide/stock/5247")
+	}
+	time.Sleep(5 * time.Millisecond)
+	wg.Add(1)
+	go wait("delete", http.MethodPost, "/_api/trading-critical/rest/order/delete")
+	wg.Wait()
+
+	if len(order) != 4 || order[0] != "delete" {
+		t.Errorf("order = %v, want delete first", order)
+	}
+}
+
+func TestTokenBucketLimiter_BacksOffAfter429(t *testing.T) {
+	l := NewTokenBucketLimiter(&TokenBucketConfig{
+		Global:  Budget{Interval: time.Millisecond, Burst: 10},
+		Backoff: 50 * time.Millisecond,
+	})
+	ctx := requestCtx(http.MethodGet, "/_api/trading/rest/orders")
+	info, _ := RequestInfoFromContext(ctx)
+
+	l.Observe(info, &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	start := time.Now()
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("waited %v after 429, want about 50ms", elapsed)
+	}
+
+	// A second 429 in a row doubles the pause.
+	l.Observe(info, &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	start = time.Now()
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("waited %v after second 429, want about 100ms", elapsed)
+	}
+
+	// Success resets the backoff.
+	l.Observe(info, &http.Response{StatusCode: http.StatusOK})
+	l.Observe(info, &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	start = time.Now()
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 90*time.Millisecond {
+		t.Errorf("waited %v after reset, want about 50ms", elapsed)
+	}
+}
+
+func TestTokenBucketLimiter_CancelledWaiterLeavesQueue(t *testing.T) {
+	l := NewTokenBucketLimiter(&TokenBucketConfig{Global: Budget{Interval: time.Hour, Burst: 1}})
+	ctx := requestCtx(http.MethodGet, "/_api/trading/rest/orders")
+	if err := l.Wait(ctx); err != nil {
+		t.Fatalf("Wait: %v", err)
+	}
+
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(cancelled); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.queue) != 0 {
+		t.Errorf("queue has %d waiters, want 0", len(l.queue))
+	}
+}
+
+func TestClient_AdaptiveRateLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	l := NewTokenBucketLimiter(&TokenBucketConfig{MaxBackoff: 40 * time.Millisecond})
+	c := NewClient(WithBaseURL(server.URL), WithRateLimiter(l))
+
+	resp, err := c.Get(context.Background(), "/_api/market-guide/stock/5247")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+
+	// Retry-After asked for 1s; MaxBackoff caps the pause at 40ms.
+	start := time.Now()
+	resp, err = c.Get(context.Background(), "/_api/market-guide/stock/5247")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("second request waited %v, want about 40ms", elapsed)
+	}
+}
@@ -0,0 +1,312 @@
+// Package decimal provides an exact decimal number for prices, amounts and
+// fees. Avanza sends these as JSON numbers, and in some responses as strings,
+// with a fixed number of decimals. A float64 cannot hold most of them exactly,
+// so sums drift and prices land between ticks. A Decimal keeps the digits as
+// sent: 0.1 + 0.2 is 0.3, and 361.000000 marshals back as 361.000000.
+//
+//	price := decimal.MustParse("245.50")
+//	total := price.Mul(decimal.NewFromInt(10)).Add(fee) // 2455.00 + fee
+//
+// The zero value is 0. Compare values with Cmp or Equal rather than ==, which
+// tells 1.0 and 1.00 apart.
+package decimal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDigits bounds the exponents Parse accepts, so a hostile "1e999999999"
+// cannot exhaust memory.
+const maxDigits = 1000
+
+// Decimal is an exact decimal number with a scale, the number of digits after
+// the decimal point.
+type Decimal struct {
+	s string // plain notation without exponent; "" is 0
+}
+
+// New returns unscaled × 10^-scale, e.g. New(24550, 2) is 245.50.
+func New(unscaled int64, scale int32) Decimal {
+	return fromBig(big.NewInt(unscaled), scale)
+}
+
+// NewFromInt returns i with scale 0.
+func NewFromInt(i int64) Decimal {
+	return Decimal{s: strconv.FormatInt(i, 10)}
+}
+
+// NewFromFloat returns the shortest decimal that rounds to f, e.g. 245.5 for
+// 245.5 rather than 245.49999999999999. It panics if f is NaN or infinite.
+func NewFromFloat(f float64) Decimal {
+	d, err := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		panic(fmt.Sprintf("decimal: %v is not a finite number", f))
+	}
+	return d
+}
+
+// Parse parses s in decimal or scientific notation, e.g. "245.50", "-3" or
+// "1.5e-3". The scale is kept, so "245.50" has scale 2.
+func Parse(s string) (Decimal, error) {
+	mantissa, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("decimal: invalid exponent in %q", s)
+		}
+		mantissa, exp = s[:i], e
+	}
+
+	neg := false
+	switch {
+	case strings.HasPrefix(mantissa, "-"):
+		neg, mantissa = true, mantissa[1:]
+	case strings.HasPrefix(mantissa, "+"):
+		mantissa = mantissa[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	if intPart == "" && fracPart == "" || !digits(intPart) || !digits(fracPart) {
+		return Decimal{}, fmt.Errorf("decimal: invalid number %q", s)
+	}
+
+	unscaled, _ := new(big.Int).SetString("0"+intPart+fracPart, 10)
+	if neg {
+		unscaled.Neg(unscaled)
+	}
+	scale := int64(len(fracPart)) - exp
+	if scale > maxDigits || scale < -maxDigits {
+		return Decimal{}, fmt.Errorf("decimal: %q is out of range", s)
+	}
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+	return fromBig(unscaled, int32(scale)), nil
+}
+
+// MustParse is like Parse but panics if s is not a number. It is meant for
+// constants.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// String returns d in plain notation with its scale, e.g. "245.50".
+func (d Decimal) String() string {
+	if d.s == "" {
+		return "0"
+	}
+	return d.s
+}
+
+// Float64 returns the float64 nearest to d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	_, frac, _ := strings.Cut(d.s, ".")
+	return int32(len(frac))
+}
+
+// Sign returns -1, 0 or 1 as d is negative, zero or positive.
+func (d Decimal) Sign() int {
+	v, _ := d.big()
+	return v.Sign()
+}
+
+// IsZero reports whether d is 0, at any scale.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp returns -1, 0 or 1 as d is less than, equal to or greater than e.
+func (d Decimal) Cmp(e Decimal) int {
+	a, b, _ := align(d, e)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and e are the same number, so 1.0 equals 1.00.
+func (d Decimal) Equal(e Decimal) bool {
+	return d.Cmp(e) == 0
+}
+
+// Add returns d + e, with the larger of their scales.
+func (d Decimal) Add(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return fromBig(a.Add(a, b), scale)
+}
+
+// Sub returns d - e, with the larger of their scales.
+func (d Decimal) Sub(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return fromBig(a.Sub(a, b), scale)
+}
+
+// Mul returns d × e, with the sum of their scales.
+func (d Decimal) Mul(e Decimal) Decimal {
+	a, sa := d.big()
+	b, sb := e.big()
+	return fromBig(a.Mul(a, b), sa+sb)
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	v, scale := d.big()
+	return fromBig(v.Neg(v), scale)
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	v, scale := d.big()
+	return fromBig(v.Abs(v), scale)
+}
+
+// Mod returns the remainder of d / e with the sign of d, e.g. 245.55 mod 0.1
+// is 0.05. It panics if e is zero.
+func (d Decimal) Mod(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	if b.Sign() == 0 {
+		panic("decimal: modulo by zero")
+	}
+	return fromBig(a.Rem(a, b), scale)
+}
+
+// Div returns d / e rounded to places decimals, halves away from zero, e.g.
+// 491.05 / 2 to 2 places is 245.53. It panics if e is zero.
+func (d Decimal) Div(e Decimal, places int32) Decimal {
+	a, sa := d.big()
+	b, sb := e.big()
+	if b.Sign() == 0 {
+		panic("decimal: division by zero")
+	}
+	// a/10^sa / (b/10^sb) * 10^places = a * 10^(places+sb-sa) / b
+	if shift := int64(places) + int64(sb) - int64(sa); shift >= 0 {
+		a.Mul(a, pow10(shift))
+	} else {
+		b.Mul(b, pow10(-shift))
+	}
+	q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+	if new(big.Int).Lsh(r.Abs(r), 1).Cmp(new(big.Int).Abs(b)) >= 0 {
+		q.Add(q, big.NewInt(int64(a.Sign()*b.Sign())))
+	}
+	return fromBig(q, places)
+}
+
+// Round returns d rounded to places decimals, halves away from zero. A d
+// with fewer decimals is returned with its scale unchanged.
+func (d Decimal) Round(places int32) Decimal {
+	v, scale := d.big()
+	if scale <= places {
+		return d
+	}
+	unit := pow10(int64(scale - places))
+	q, r := new(big.Int).QuoRem(v, unit, new(big.Int))
+	if r.Abs(r).Lsh(r, 1).Cmp(unit) >= 0 {
+		q.Add(q, big.NewInt(int64(v.Sign())))
+	}
+	return fromBig(q, places)
+}
+
+// Truncate returns d with the decimals after places dropped.
+func (d Decimal) Truncate(places int32) Decimal {
+	v, scale := d.big()
+	if scale <= places {
+		return d
+	}
+	return fromBig(v.Quo(v, pow10(int64(scale-places))), places)
+}
+
+// MarshalJSON encodes d as a JSON number with its scale, e.g. 245.50.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number, or a string holding one as in
+// Avanza's fee responses. An empty string decodes as 0 and null leaves d
+// unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		if text == "" {
+			*d = Decimal{}
+			return nil
+		}
+	}
+	v, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// big returns d's unscaled value and scale.
+func (d Decimal) big() (*big.Int, int32) {
+	s := d.String()
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	v, _ := new(big.Int).SetString(intPart+fracPart, 10)
+	return v, int32(len(fracPart))
+}
+
+// fromBig returns v × 10^-scale.
+func fromBig(v *big.Int, scale int32) Decimal {
+	if scale < 0 {
+		v = new(big.Int).Mul(v, pow10(int64(-scale)))
+		scale = 0
+	}
+	str := new(big.Int).Abs(v).String()
+	if scale > 0 {
+		if pad := int(scale) + 1 - len(str); pad > 0 {
+			str = strings.Repeat("0", pad) + str
+		}
+		str = str[:len(str)-int(scale)] + "." + str[len(str)-int(scale):]
+	}
+	if v.Sign() < 0 {
+		str = "-" + str
+	}
+	return Decimal{s: str}
+}
+
+// align returns the unscaled values of d and e at their common scale.
+func align(d, e Decimal) (*big.Int, *big.Int, int32) {
+	a, sa := d.big()
+	b, sb := e.big()
+	switch {
+	case sa < sb:
+		a.Mul(a, pow10(int64(sb-sa)))
+		return a, b, sb
+	case sb < sa:
+		b.Mul(b, pow10(int64(sa-sb)))
+	}
+	return a, b, sa
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package decimal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in, want string
+		scale    int32
+	}{
+		{"245.50", "245.50", 2},
+		{"361.000000", "361.000000", 6},
+		{"-3", "-3", 0},
+		{"+7", "7", 0},
+		{"0.1", "0.1", 1},
+		{".5", "0.5", 1},
+		{"1.", "1", 0},
+		{"007.10", "7.10", 2},
+		{"-0.00", "0.00", 2},
+		{"1.5e-3", "0.0015", 4},
+		{"1.5E+3", "1500", 0},
+		{"2e2", "200", 0},
+	}
+	for _, tt := range tests {
+		d, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if d.String() != tt.want || d.Scale() != tt.scale {
+			t.Errorf("Parse(%q) = %s (scale %d), want %s (scale %d)", tt.in, d, d.Scale(), tt.want, tt.scale)
+		}
+	}
+
+	for _, in := range []string{"", "-", ".", "abc", "1.2.3", "1e", "1e5x", "0x10", "1,5", "1e99999"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q): want an error", in)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	d := MustParse
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{"float sum is exact", d("0.1").Add(d("0.2")), "0.3"},
+		{"add keeps larger scale", d("245.5").Add(d("0.05")), "245.55"},
+		{"sub", d("100000").Sub(d("2395.00")), "97605.00"},
+		{"sub below zero", d("1.5").Sub(d("2")), "-0.5"},
+		{"mul adds scales", d("245.50").Mul(NewFromInt(10)), "2455.00"},
+		{"mul negative", d("-0.5").Mul(d("0.5")), "-0.25"},
+		{"neg", d("1.20").Neg(), "-1.20"},
+		{"abs", d("-1.20").Abs(), "1.20"},
+		{"mod", d("245.55").Mod(d("0.1")), "0.05"},
+		{"mod on tick", d("245.50").Mod(d("0.5")), "0.00"},
+		{"mod negative", d("-7").Mod(d("2")), "-1"},
+		{"div", d("491.05").Div(d("2"), 2), "245.53"},
+		{"div exact", d("2455.00").Div(d("10"), 2), "245.50"},
+		{"div by fraction", d("1").Div(d("0.03"), 3), "33.333"},
+		{"div negative half", d("-0.5").Div(d("4"), 2), "-0.13"},
+		{"round half up", d("2.345").Round(2), "2.35"},
+		{"round half away from zero", d("-2.345").Round(2), "-2.35"},
+		{"round down", d("2.344").Round(2), "2.34"},
+		{"round to integer", d("0.5").Round(0), "1"},
+		{"round keeps shorter scale", d("2.5").Round(2), "2.5"},
+		{"truncate", d("-2.349").Truncate(2), "-2.34"},
+		{"new", New(24550, 2), "245.50"},
+		{"new negative scale", New(5, -2), "500"},
+		{"new small", New(-5, 3), "-0.005"},
+		{"from float", NewFromFloat(245.5), "245.5"},
+		{"from float tenth", NewFromFloat(0.1), "0.1"},
+		{"zero value", Decimal{}, "0"},
+		{"zero value sum", Decimal{}.Add(d("1.50")), "1.50"},
+	}
+	for _, tt := range tests {
+		if tt.got.String() != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	d := MustParse
+	if !d("1.0").Equal(d("1.00")) {
+		t.Error("1.0 should equal 1.00")
+	}
+	if d("1.0") == d("1.00") {
+		t.Error("== should tell 1.0 and 1.00 apart")
+	}
+	if d("-1").Cmp(d("0.5")) != -1 || d("10").Cmp(d("9.99")) != 1 || d("2.50").Cmp(d("2.5")) != 0 {
+		t.Error("Cmp orders numbers wrongly")
+	}
+	if !(Decimal{}).IsZero() || !d("0.00").IsZero() || d("-0.01").Sign() != -1 {
+		t.Error("Sign or IsZero is wrong")
+	}
+	if f := d("245.50").Float64(); f != 245.5 {
+		t.Errorf("Float64 = %v, want 245.5", f)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Price  Decimal  `json:"price"`
+		Fee    Decimal  `json:"fee"`
+		Empty  Decimal  `json:"empty"`
+		Null   Decimal  `json:"null"`
+		Ptr    *Decimal `json:"ptr"`
+		Absent Decimal  `json:"absent"`
+	}
+	raw := `{"price":361.000000,"fee":"39.00","empty":"","null":null,"ptr":1e-2}`
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if v.Price.String() != "361.000000" || v.Fee.String() != "39.00" || !v.Empty.IsZero() || !v.Null.IsZero() || v.Ptr.String() != "0.01" {
+		t.Errorf("decoded %+v", v)
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `{"price":361.000000,"fee":39.00,"empty":0,"null":0,"ptr":0.01,"absent":0}`
+	if string(out) != want {
+		t.Errorf("Marshal = %s, want %s", out, want)
+	}
+
+	for _, bad := range []string{`{"price":"abc"}`, `{"price":true}`, `{"price":{}}`} {
+		if err := json.Unmarshal([]byte(bad), &v); err == nil {
+			t.Errorf("Unmarshal(%s): want an error", bad)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/vmorsell/avanza-sdk-go"
 	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 	"github.com/vmorsell/avanza-sdk-go/trading"
 )
 
@@ -109,7 +110,7 @@ func ExampleAvanza_Trading_placeOrder() {
 		OrderbookID: "5247",
 		Side:        trading.OrderSideBuy,
 		Condition:   trading.OrderConditionNormal,
-		Price:       245.50,
+		Price:       decimal.MustParse("245.50"),
 		Volume:      10,
 	})
 	if err != nil {
@@ -131,7 +132,7 @@ func ExampleAvanza_Trading_validateOrder() {
 		OrderbookID: "5247",
 		Side:        trading.OrderSideBuy,
 		Condition:   trading.OrderConditionNormal,
-		Price:       245.50,
+		Price:       decimal.MustParse("245.50"),
 		Volume:      10,
 		ISIN:        "SE0000108656",
 		Currency:    "SEK",
@@ -205,7 +206,7 @@ func ExampleAvanza_Market_subscribeToOrderDepth() {
 		case event := <-sub.Events():
 			if event.Event == "ORDER_DEPTH" {
 				for i, lvl := range event.Data.Levels {
-					fmt.Printf("%d: %.0f @ %.2f / %.0f @ %.2f\n",
+					fmt.Printf("%d: %.0f @ %s / %.0f @ %s\n",
 						i, lvl.BuyVolume, lvl.BuyPrice, lvl.SellVolume, lvl.SellPrice)
 				}
 			}
@@ -20,7 +20,7 @@ func main() {
 
 	fmt.Printf("\nAccounts (%d):\n", len(overview.Accounts))
 	for _, a := range overview.Accounts {
-		fmt.Printf("  %s (%s): %.2f %s\n",
+		fmt.Printf("  %s (%s): %s %s\n",
 			a.Name.UserDefinedName, a.Type,
 			a.TotalValue.Value, a.TotalValue.Unit)
 	}
@@ -33,7 +33,7 @@ func main() {
 
 	fmt.Printf("\nTrading accounts (%d):\n", len(tradingAccounts))
 	for _, a := range tradingAccounts {
-		fmt.Printf("  %s (%s): %.2f SEK available\n",
+		fmt.Printf("  %s (%s): %s SEK available\n",
 			a.Name, a.AccountTypeName, a.AvailableForPurchase)
 	}
 
@@ -50,11 +50,11 @@ func main() {
 
 	fmt.Printf("\nPositions in %s:\n", first.Name)
 	for _, p := range positions.WithOrderbook {
-		fmt.Printf("  %s: %.0f shares, value %.2f %s\n",
+		fmt.Printf("  %s: %s shares, value %s %s\n",
 			p.Instrument.Name, p.Volume.Value,
 			p.Value.Value, p.Value.Unit)
 	}
 	for _, c := range positions.CashPositions {
-		fmt.Printf("  Cash: %.2f %s\n", c.TotalBalance.Value, c.TotalBalance.Unit)
+		fmt.Printf("  Cash: %s %s\n", c.TotalBalance.Value, c.TotalBalance.Unit)
 	}
 }
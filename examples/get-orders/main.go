@@ -21,7 +21,7 @@ func main() {
 		len(orders.Orders), len(orders.FundOrders), len(orders.CancelledOrders))
 
 	for _, o := range orders.Orders {
-		fmt.Printf("  %s %s %d @ %.2f %s — %s (%s)\n",
+		fmt.Printf("  %s %s %d @ %s %s — %s (%s)\n",
 			o.OrderID, o.Side, o.Volume, o.Price, o.Orderbook.Currency,
 			o.Orderbook.Name, o.StateText)
 	}
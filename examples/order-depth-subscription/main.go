@@ -49,7 +49,7 @@ func main() {
 					if i == event.Data.MarketMakerLevelInAsk {
 						sellMM = "*"
 					}
-					fmt.Printf("%d: Buy %.0f @ %.2f%s | Sell %.0f @ %.2f%s\n",
+					fmt.Printf("%d: Buy %.0f @ %s%s | Sell %.0f @ %s%s\n",
 						i, lvl.BuyVolume, lvl.BuyPrice, buyMM,
 						lvl.SellVolume, lvl.SellPrice, sellMM)
 				}
@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/google/uuid"
 	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 	"github.com/vmorsell/avanza-sdk-go/examples/internal/auth"
 	"github.com/vmorsell/avanza-sdk-go/trading"
 )
@@ -16,21 +16,20 @@ func main() {
 	az := auth.Authenticate()
 	ctx := context.Background()
 	account := auth.FirstTradingAccount(ctx, az)
-	fmt.Printf("Using account: %s (%.2f SEK available)\n", account.Name, account.AvailableForPurchase)
+	fmt.Printf("Using account: %s (%s SEK available)\n", account.Name, account.AvailableForPurchase)
+
+	ob, err := az.Market.GetOrderbook(ctx, "5247") // Investor B
+	if err != nil {
+		log.Fatalf("Failed to get orderbook: %v", err)
+	}
 
 	// Place a buy order with price far out of range so it won't fill.
-	req := &trading.PlaceOrderRequest{
-		RequestID:   uuid.New().String(),
-		Price:       2.0,
-		Volume:      1,
-		AccountID:   account.AccountID,
-		Side:        trading.OrderSideBuy,
-		OrderbookID: "5247", // Investor B
-		Condition:   trading.OrderConditionNormal,
-		Metadata: trading.OrderMetadata{
-			OrderEntryMode:  "ADVANCED",
-			HasTouchedPrice: "true",
-		},
+	req, err := trading.NewLimitOrder(account.AccountID, ob).
+		Buy(1).
+		At(decimal.MustParse("2.00")).
+		Build()
+	if err != nil {
+		log.Fatalf("Invalid order: %v", err)
 	}
 
 	resp, err := az.Trading.PlaceOrder(ctx, req)
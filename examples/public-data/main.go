@@ -36,7 +36,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("GetStock failed: %v", err)
 	}
-	fmt.Printf("\n%s (%s): %.2f %s (%.2f%%)\n",
+	fmt.Printf("\n%s (%s): %s %s (%.2f%%)\n",
 		stock.Name, stock.Listing.TickerSymbol,
 		stock.Quote.Last, stock.Listing.Currency, stock.Quote.ChangePercent)
 
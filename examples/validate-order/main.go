@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 	"github.com/vmorsell/avanza-sdk-go/examples/internal/auth"
 	"github.com/vmorsell/avanza-sdk-go/trading"
 )
@@ -15,7 +16,7 @@ func main() {
 	account := auth.FirstTradingAccount(ctx, client)
 
 	req := &trading.ValidateOrderRequest{
-		Price:       1.9998,
+		Price:       decimal.MustParse("1.9998"),
 		Volume:      2,
 		AccountID:   account.AccountID,
 		Side:        trading.OrderSideBuy,
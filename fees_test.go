@@ -60,21 +60,19 @@ func TestGetPreliminaryFee_Success(t *testing.T) {
 			t.Errorf("req.Side = %v, want %v", got, want)
 		}
 
+		// The API sends amounts as strings.
 		w.WriteHeader(http.StatusOK)
-		_ = json.NewEncoder(w).Encode(trading.PreliminaryFeeResponse{
-			Commission:          testCommission,
-			MarketFees:          testMarketFees,
-			TotalFees:           testTotalFees,
-			TotalSum:            testTotalSum,
-			TotalSumWithoutFees: testTotalSumWithoutFees,
-			OrderbookCurrency:   testOrderbookCurrency,
-			TransactionTax:      nil,
-			CurrencyExchangeFee: trading.CurrencyExchangeFee{
-				Rate: "",
-				Sum:  "",
-			},
-			Campaign: nil,
-		})
+		_, _ = fmt.Fprintf(w, `{
+			"commission": %q,
+			"marketFees": %q,
+			"totalFees": %q,
+			"totalSum": %q,
+			"totalSumWithoutFees": %q,
+			"orderbookCurrency": %q,
+			"transactionTax": null,
+			"currencyExchangeFee": {"rate": "", "sum": ""},
+			"campaign": null
+		}`, testCommission, testMarketFees, testTotalFees, testTotalSum, testTotalSumWithoutFees, testOrderbookCurrency)
 	}))
 	defer server.Close()
 
@@ -93,21 +91,25 @@ func TestGetPreliminaryFee_Success(t *testing.T) {
 		t.Fatalf("GetPreliminaryFee failed: %v", err)
 	}
 
-	if got, want := resp.Commission, testCommission; got != want {
+	if got, want := resp.Commission.String(), testCommission; got != want {
 		t.Errorf("resp.Commission = %v, want %v", got, want)
 	}
 
-	if got, want := resp.TotalFees, testTotalFees; got != want {
+	if got, want := resp.TotalFees.String(), testTotalFees; got != want {
 		t.Errorf("resp.TotalFees = %v, want %v", got, want)
 	}
 
-	if got, want := resp.TotalSum, testTotalSum; got != want {
+	if got, want := resp.TotalSum.String(), testTotalSum; got != want {
 		t.Errorf("resp.TotalSum = %v, want %v", got, want)
 	}
 
 	if got, want := resp.OrderbookCurrency, testOrderbookCurrency; got != want {
 		t.Errorf("resp.OrderbookCurrency = %v, want %v", got, want)
 	}
+
+	if !resp.CurrencyExchangeFee.Sum.IsZero() {
+		t.Errorf("resp.CurrencyExchangeFee.Sum = %v, want 0", resp.CurrencyExchangeFee.Sum)
+	}
 }
 
 func TestGetPreliminaryFee_HTTPError(t *testing.T) {
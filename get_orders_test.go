@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 	"github.com/vmorsell/avanza-sdk-go/trading"
 )
 
@@ -25,7 +26,7 @@ func TestGetOrders_Success(t *testing.T) {
 				{
 					OrderID:     "order-1",
 					Volume:      100,
-					Price:       10.50,
+					Price:       decimal.MustParse("10.50"),
 					Side:        trading.OrderSideBuy,
 					OrderbookID: "5247",
 					State:       "ACTIVE",
@@ -40,7 +41,7 @@ func TestGetOrders_Success(t *testing.T) {
 				{
 					OrderID:     "order-2",
 					Volume:      50,
-					Price:       200.00,
+					Price:       decimal.MustParse("200.00"),
 					Side:        trading.OrderSideSell,
 					OrderbookID: "1234",
 					State:       "ACTIVE",
@@ -0,0 +1,37 @@
+// Package backoff holds the retry timing and classification shared by the
+// HTTP client and the SSE subscriptions.
+package backoff
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// Exponential returns base * 2^min(attempt, 5), capped at maxWait.
+func Exponential(base time.Duration, attempt int, maxWait time.Duration) time.Duration {
+	wait := base << min(max(attempt, 0), 5)
+	return min(wait, maxWait)
+}
+
+// Jitter returns a random duration in [d/2, d], so that clients failing
+// together do not retry together.
+func Jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + rand.N(d-half+1) //nolint:gosec // timing jitter, not security sensitive
+}
+
+// RecoverableStatus reports whether a response with this status code is
+// transient and worth retrying: 408, 429 and 5xx.
+func RecoverableStatus(code int) bool {
+	switch {
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests:
+		return true
+	case code >= 500:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{-1, time.Second},
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second},
+		{100, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := Exponential(time.Second, tt.attempt, 10*time.Second); got != tt.want {
+			t.Errorf("Exponential(1s, %d, 10s) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	d := 100 * time.Millisecond
+	for range 1000 {
+		got := Jitter(d)
+		if got < d/2 || got > d {
+			t.Fatalf("Jitter(%v) = %v, want within [%v, %v]", d, got, d/2, d)
+		}
+	}
+	if got := Jitter(0); got != 0 {
+		t.Errorf("Jitter(0) = %v, want 0", got)
+	}
+}
+
+func TestRecoverableStatus(t *testing.T) {
+	tests := []struct {
+		code int
+		want bool
+	}{
+		{200, false},
+		{400, false},
+		{401, false},
+		{404, false},
+		{408, true},
+		{429, true},
+		{500, true},
+		{502, true},
+		{503, true},
+	}
+
+	for _, tt := range tests {
+		if got := RecoverableStatus(tt.code); got != tt.want {
+			t.Errorf("RecoverableStatus(%d) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Package drifttest checks golden JSON fixtures against the Go types that
+// model them, for the schema drift tests of the service packages.
+package drifttest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vmorsell/avanza-sdk-go/schema"
+)
+
+// Run checks each fixture in dir against the type its function returns, one
+// subtest per fixture. known is passed on to AssertNoDrift for every fixture.
+func Run(t *testing.T, dir string, fixtures map[string]func() any, known ...schema.Issue) {
+	t.Helper()
+	for name, newTarget := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatalf("read fixture: %v", err)
+			}
+			AssertNoDrift(t, name, raw, newTarget(), known...)
+		})
+	}
+}
+
+// AssertNoDrift verifies that raw decodes into target with no unmapped
+// fields, type mismatches or unexpected nulls. Fields absent from raw are
+// logged rather than failed, since Avanza leaves out some fields in some
+// states (e.g. the stop loss stream omits stoplossMarketMakerQuote), and so
+// are the known issues, such as a null that the Go type documents as decoding
+// to its zero value. label identifies the payload in failure messages, e.g. a
+// fixture name or a URL.
+func AssertNoDrift(t testing.TB, label string, raw []byte, target any, known ...schema.Issue) {
+	t.Helper()
+
+	if err := json.Unmarshal(raw, target); err != nil {
+		t.Fatalf("decode %s into type: %v\n%s", label, err, snippet(raw))
+	}
+
+	issues, err := schema.Check(raw, target)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	var drift []string
+	for _, issue := range issues {
+		if issue.Kind == schema.Missing || slices.Contains(known, issue) {
+			t.Logf("%s: %s", label, issue)
+			continue
+		}
+		drift = append(drift, issue.String())
+	}
+	if len(drift) > 0 {
+		t.Errorf("%s does not match the Go type — update the type:\n  %s", label, strings.Join(drift, "\n  "))
+	}
+}
+
+func snippet(raw []byte) string {
+	const max = 500
+	if len(raw) > max {
+		return string(raw[:max]) + "…"
+	}
+	return string(raw)
+}
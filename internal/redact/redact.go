@@ -0,0 +1,115 @@
+// Package redact removes secrets and personal data from values before they
+// are logged.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces redacted values.
+const Mask = "[REDACTED]"
+
+// sensitiveHeaders are replaced entirely. Cookie and Set-Cookie keep their
+// cookie names.
+var sensitiveHeaders = []string{"X-SecurityToken", "Authorization"}
+
+// sensitiveKeys are JSON object keys whose values are replaced, compared
+// case-insensitively.
+var sensitiveKeys = map[string]bool{
+	"qrtoken":              true,
+	"autostarttoken":       true,
+	"identificationnumber": true,
+	"personnummer":         true,
+	"securitytoken":        true,
+	"csid":                 true,
+	"cstoken":              true,
+	"azacsrf":              true,
+}
+
+// personalNumber matches Swedish personal identity numbers, with or without
+// century and separator, e.g. 19900101-1234 or 9001011234.
+var personalNumber = regexp.MustCompile(`\b(?:19|20)?\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])[-+]?\d{4}\b`)
+
+// String masks personal identity numbers in s.
+func String(s string) string {
+	return personalNumber.ReplaceAllString(s, Mask)
+}
+
+// Header returns a copy of h with the security token and authorization
+// replaced, and all cookie values masked.
+func Header(h http.Header) http.Header {
+	out := h.Clone()
+	if out == nil {
+		return nil
+	}
+	for _, name := range sensitiveHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, Mask)
+		}
+	}
+	for i, v := range out.Values("Cookie") {
+		out["Cookie"][i] = cookies(v)
+	}
+	for i, v := range out.Values("Set-Cookie") {
+		name, _, _ := strings.Cut(v, "=")
+		out["Set-Cookie"][i] = name + "=" + Mask
+	}
+	return out
+}
+
+// cookies masks the values in a Cookie header.
+func cookies(header string) string {
+	parts := strings.Split(header, ";")
+	for i, part := range parts {
+		name, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+		parts[i] = name + "=" + Mask
+	}
+	return strings.Join(parts, "; ")
+}
+
+// JSON returns data with the values of sensitive keys masked and personal
+// identity numbers removed from all strings. Data that is not JSON is
+// treated as a string.
+func JSON(data []byte) []byte {
+	if len(data) == 0 {
+		return data
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return []byte(String(string(data)))
+	}
+	out, err := json.Marshal(value(v))
+	if err != nil {
+		return []byte(Mask)
+	}
+	return out
+}
+
+func value(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, inner := range v {
+			if sensitiveKeys[strings.ToLower(k)] {
+				v[k] = Mask
+				continue
+			}
+			v[k] = value(inner)
+		}
+		return v
+	case []any:
+		for i, inner := range v {
+			v[i] = value(inner)
+		}
+		return v
+	case string:
+		return String(v)
+	default:
+		return v
+	}
+}
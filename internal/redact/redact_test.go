@@ -0,0 +1,75 @@
+package redact
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"user 19900101-1234 logged in", "user [REDACTED] logged in"},
+		{"pnr=199001011234", "pnr=[REDACTED]"},
+		{"9001011234", "[REDACTED]"},
+		{"900101+1234", "[REDACTED]"},
+		{"orderbook 5247", "orderbook 5247"},
+		{"order 1234567890123", "order 1234567890123"},
+		{"price 1999991234", "price 1999991234"}, // month 99 is not a date
+	}
+	for _, tt := range tests {
+		if got := String(tt.in); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("Cookie", "csid=secret1; cstoken=secret2; AZACSRF=secret3")
+	h.Set("X-SecurityToken", "secret3")
+	h.Add("Set-Cookie", "csid=secret4; Path=/; HttpOnly")
+	h.Set("Accept", "application/json")
+
+	got := Header(h)
+
+	if want := "csid=[REDACTED]; cstoken=[REDACTED]; AZACSRF=[REDACTED]"; got.Get("Cookie") != want {
+		t.Errorf("Cookie = %q, want %q", got.Get("Cookie"), want)
+	}
+	if got.Get("X-SecurityToken") != Mask {
+		t.Errorf("X-SecurityToken = %q, want masked", got.Get("X-SecurityToken"))
+	}
+	if got.Get("Set-Cookie") != "csid="+Mask {
+		t.Errorf("Set-Cookie = %q", got.Get("Set-Cookie"))
+	}
+	if got.Get("Accept") != "application/json" {
+		t.Errorf("Accept = %q, want unchanged", got.Get("Accept"))
+	}
+	if h.Get("X-SecurityToken") != "secret3" {
+		t.Error("Header modified its input")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	in := `{"qrToken":"bankid.abc","autostartToken":"tok","logins":[{"customerId":"123","identificationNumber":"199001011234"}],"note":"pnr 19900101-1234","orderbookId":12345678901234567}`
+	got := string(JSON([]byte(in)))
+
+	for _, secret := range []string{"bankid.abc", "tok\"", "199001011234", "19900101-1234"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("output contains %q: %s", secret, got)
+		}
+	}
+	for _, keep := range []string{`"customerId":"123"`, `"orderbookId":12345678901234567`} {
+		if !strings.Contains(got, keep) {
+			t.Errorf("output lost %s: %s", keep, got)
+		}
+	}
+}
+
+func TestJSON_NotJSON(t *testing.T) {
+	if got := string(JSON([]byte("ssn 199001011234"))); got != "ssn [REDACTED]" {
+		t.Errorf("JSON(text) = %q", got)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/internal/backoff"
 )
 
 const (
@@ -47,20 +48,24 @@ type Subscription struct {
 	cancel        context.CancelFunc
 	events        chan RawEvent
 	errors        chan error
+	connected     chan struct{}
+	connectOnce   sync.Once
 	wg            sync.WaitGroup
 	lastEventID   string
 	retryInterval time.Duration
+	observe       func(client.StreamEvent)
 }
 
 // New creates and starts a Subscription.
 func New(ctx context.Context, cfg Config) *Subscription {
 	subCtx, cancel := context.WithCancel(ctx)
 	s := &Subscription{
-		cfg:    cfg,
-		ctx:    subCtx,
-		cancel: cancel,
-		events: make(chan RawEvent, 100),
-		errors: make(chan error, 10),
+		cfg:       cfg,
+		ctx:       subCtx,
+		cancel:    cancel,
+		events:    make(chan RawEvent, 100),
+		errors:    make(chan error, 10),
+		connected: make(chan struct{}),
 	}
 	s.wg.Add(1)
 	go s.start()
@@ -77,6 +82,12 @@ func (s *Subscription) Errors() <-chan error {
 	return s.errors
 }
 
+// Connected returns a channel that is closed once the first connection is
+// established. Events sent after that are received.
+func (s *Subscription) Connected() <-chan struct{} {
+	return s.connected
+}
+
 // Close stops the subscription and cleans up resources.
 func (s *Subscription) Close() {
 	s.cancel()
@@ -104,9 +115,16 @@ func (s *Subscription) start() {
 	defer close(s.events)
 	defer close(s.errors)
 
+	s.observe = s.cfg.Client.ObserveStream(s.ctx, s.cfg.Endpoint)
+	var closeErr error
+	defer func() {
+		s.observe(client.StreamEvent{Kind: client.StreamClose, Err: closeErr})
+	}()
+
 	defer func() {
 		if r := recover(); r != nil {
-			s.trySendError(fmt.Errorf("subscription panic: %v", r))
+			closeErr = fmt.Errorf("subscription panic: %v", r)
+			s.trySendError(closeErr)
 		}
 	}()
 
@@ -118,7 +136,11 @@ func (s *Subscription) start() {
 		if s.ctx.Err() != nil {
 			return
 		}
+		if connected {
+			s.observe(client.StreamEvent{Kind: client.StreamDisconnect, Err: err})
+		}
 		if err != nil && !IsRecoverable(err) {
+			closeErr = err
 			s.trySendError(err)
 			return
 		}
@@ -130,6 +152,7 @@ func (s *Subscription) start() {
 		if attempt > 0 {
 			wait = ExponentialBackoff(s.retryInterval, attempt)
 		}
+		s.observe(client.StreamEvent{Kind: client.StreamReconnect, Attempt: attempt + 1, Wait: wait, Err: err})
 
 		select {
 		case <-s.ctx.Done():
@@ -140,61 +163,55 @@ func (s *Subscription) start() {
 }
 
 func (s *Subscription) connectAndStream() (bool, error) {
-	req, err := http.NewRequestWithContext(s.ctx, "GET", s.cfg.Client.BaseURL()+s.cfg.Endpoint, nil)
-	if err != nil {
-		return false, fmt.Errorf("create request: %w", err)
-	}
-
-	s.setSSEHeaders(req)
-
-	baseClient := s.cfg.Client.HTTPClient()
-	httpClient := &http.Client{
-		Transport: baseClient.Transport,
-		Timeout:   0,
-	}
-
-	resp, err := httpClient.Do(req)
+	resp, err := s.cfg.Client.Stream(s.ctx, s.cfg.Endpoint, s.sseHeaders())
 	if err != nil {
-		return false, fmt.Errorf("request failed: %w", err)
+		return false, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		return false, client.NewHTTPError(resp)
 	}
+	s.observe(client.StreamEvent{Kind: client.StreamConnect})
+	if s.connected != nil {
+		s.connectOnce.Do(func() { close(s.connected) })
+	}
 
 	err = s.processSSEStream(resp)
 	return true, err
 }
 
-func (s *Subscription) setSSEHeaders(req *http.Request) {
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.6")
-	req.Header.Set("aza-do-not-touch-session", "true")
-	req.Header.Set("Cache-Control", "no-cache")
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Pragma", "no-cache")
-	req.Header.Set("Priority", "u=1, i")
-	req.Header.Set("Referer", s.cfg.Referer)
-	req.Header.Set("Sec-Ch-Ua", `"Not)A;Brand";v="8", "Chromium";v="138", "Google Chrome";v="138"`)
-	req.Header.Set("Sec-Ch-Ua-Mobile", "?0")
-	req.Header.Set("Sec-Ch-Ua-Platform", `"macOS"`)
-	req.Header.Set("Sec-Fetch-Dest", "empty")
-	req.Header.Set("Sec-Fetch-Mode", "cors")
-	req.Header.Set("Sec-Fetch-Site", "same-origin")
-	req.Header.Set("User-Agent", s.cfg.Client.UserAgent())
+func (s *Subscription) sseHeaders() http.Header {
+	h := make(http.Header)
+	h.Set("Accept", "text/event-stream")
+	h.Set("Accept-Language", "en-US,en;q=0.6")
+	h.Set("aza-do-not-touch-session", "true")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Content-Type", "application/json")
+	h.Set("Pragma", "no-cache")
+	h.Set("Priority", "u=1, i")
+	h.Set("Referer", s.cfg.Referer)
+	h.Set("Sec-Ch-Ua", `"Not)A;Brand";v="8", "Chromium";v="138", "Google Chrome";v="138"`)
+	h.Set("Sec-Ch-Ua-Mobile", "?0")
+	h.Set("Sec-Ch-Ua-Platform", `"macOS"`)
+	h.Set("Sec-Fetch-Dest", "empty")
+	h.Set("Sec-Fetch-Mode", "cors")
+	h.Set("Sec-Fetch-Site", "same-origin")
+	h.Set("User-Agent", s.cfg.Client.UserAgent())
 
 	if s.lastEventID != "" {
-		req.Header.Set("Last-Event-ID", s.lastEventID)
+		h.Set("Last-Event-ID", s.lastEventID)
 	}
 
 	if token := s.cfg.Client.SecurityToken(); token != "" {
-		req.Header.Set("X-SecurityToken", token)
+		h.Set("X-SecurityToken", token)
 	}
 
 	if cookie := s.cfg.Client.CookieHeader(); cookie != "" {
-		req.Header.Set("Cookie", cookie)
+		h.Set("Cookie", cookie)
 	}
+
+	return h
 }
 
 func (s *Subscription) processSSEStream(resp *http.Response) error {
@@ -266,16 +283,8 @@ func IsRecoverable(err error) bool {
 	}
 
 	var httpErr *client.HTTPError
-	if errors.As(err, &httpErr) {
-		switch {
-		case httpErr.StatusCode == http.StatusRequestTimeout,
-			httpErr.StatusCode == http.StatusTooManyRequests:
-			return true
-		case httpErr.StatusCode >= 400 && httpErr.StatusCode < 500:
-			return false
-		case httpErr.StatusCode >= 500:
-			return true
-		}
+	if errors.As(err, &httpErr) && httpErr.StatusCode >= 400 {
+		return backoff.RecoverableStatus(httpErr.StatusCode)
 	}
 
 	if errors.Is(err, io.ErrUnexpectedEOF) {
@@ -288,6 +297,5 @@ func IsRecoverable(err error) bool {
 // ExponentialBackoff returns a wait duration using exponential backoff.
 // The formula is base * 2^min(attempt, 5), capped at maxRetryInterval.
 func ExponentialBackoff(base time.Duration, attempt int) time.Duration {
-	wait := base << min(max(attempt, 0), 5)
-	return min(wait, maxRetryInterval)
+	return backoff.Exponential(base, attempt, maxRetryInterval)
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -61,6 +62,33 @@ func TestReceivesEvents(t *testing.T) {
 	sub.Close()
 }
 
+func TestConnectedClosesOnConnect(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	sub := New(context.Background(), Config{Client: newTestClient(srv.URL), Endpoint: "/events"})
+	defer sub.Close()
+
+	select {
+	case <-sub.Connected():
+		t.Fatal("Connected closed before the server answered")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case <-sub.Connected():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Connected")
+	}
+}
+
 func TestReconnectsAfterStreamDrop(t *testing.T) {
 	var connCount atomic.Int32
 
@@ -572,3 +600,90 @@ func TestIsRecoverable(t *testing.T) {
 		})
 	}
 }
+
+func TestConnectGoesThroughClientMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		writeSSEEvent(w, "e1", `{}`)
+	}))
+	defer srv.Close()
+
+	var streams atomic.Int32
+	mw := func(next client.Handler) client.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if info, ok := client.RequestInfoFromContext(req.Context()); ok && info.Stream && info.Endpoint == "/events" {
+				if req.Header.Get("X-SecurityToken") != "c" {
+					t.Errorf("X-SecurityToken = %q, want c", req.Header.Get("X-SecurityToken"))
+				}
+				streams.Add(1)
+			}
+			return next(req)
+		}
+	}
+
+	c := client.NewClient(client.WithBaseURL(srv.URL), client.WithMiddleware(mw))
+	c.SetMockCookies(map[string]string{"csid": "a", "cstoken": "b", "AZACSRF": "c"})
+
+	sub := New(context.Background(), Config{Client: c, Endpoint: "/events", Referer: "https://example.com"})
+	defer sub.Close()
+
+	select {
+	case <-sub.Events():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	if streams.Load() == 0 {
+		t.Error("expected middleware to see the stream connect")
+	}
+}
+
+func TestReportsStreamEventsToObserver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "retry: 10\n")
+		writeSSEEvent(w, "e1", `{}`)
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var kinds []client.StreamEventKind
+	observer := func(_ context.Context, endpoint string) func(client.StreamEvent) {
+		if endpoint != "/events" {
+			t.Errorf("endpoint = %q, want /events", endpoint)
+		}
+		return func(ev client.StreamEvent) {
+			mu.Lock()
+			kinds = append(kinds, ev.Kind)
+			mu.Unlock()
+		}
+	}
+
+	c := client.NewClient(client.WithBaseURL(srv.URL), client.WithStreamObserver(observer))
+	sub := New(context.Background(), Config{Client: c, Endpoint: "/events", Referer: "https://example.com"})
+
+	for range 2 {
+		select {
+		case <-sub.Events():
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+	sub.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []client.StreamEventKind{client.StreamConnect, client.StreamDisconnect, client.StreamReconnect, client.StreamConnect}
+	if len(kinds) < len(want)+1 {
+		t.Fatalf("kinds = %v, want prefix %v and a final close", kinds, want)
+	}
+	for i, k := range want {
+		if kinds[i] != k {
+			t.Errorf("kinds[%d] = %s, want %s", i, kinds[i], k)
+		}
+	}
+	if last := kinds[len(kinds)-1]; last != client.StreamClose {
+		t.Errorf("last kind = %s, want close", last)
+	}
+}
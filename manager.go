@@ -0,0 +1,241 @@
+package avanza
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/vmorsell/avanza-sdk-go/auth"
+	"github.com/vmorsell/avanza-sdk-go/client"
+)
+
+// ErrUnknownUser is returned by Manager methods for a user ID that has not been added.
+var ErrUnknownUser = errors.New("unknown user")
+
+// Manager holds one Avanza client per user, for services that act on behalf
+// of several people. Each user has their own cookies, login and session
+// monitor; all users share one HTTP client and one rate limiter, so the
+// process as a whole stays within Avanza's limits. It is safe for concurrent use.
+//
+//	m := avanza.NewManager()
+//	defer m.Close()
+//
+//	anna, _ := m.Add("anna", avanza.WithSessionStore(auth.NewFileSessionStore("anna.session", key)))
+//	if _, err := anna.Auth.RestoreSession(ctx); err != nil {
+//		// run the BankID flow for anna
+//	}
+//	_ = m.Monitor(ctx, "anna", nil)
+//	overview, err := anna.Accounts.GetOverview(ctx)
+type Manager struct {
+	opts        []Option
+	httpClient  *http.Client
+	rateLimiter client.RateLimiter
+
+	mu       sync.Mutex
+	users    map[string]*Avanza
+	monitors map[string]*auth.SessionMonitor
+	closed   bool
+
+	events chan UserSessionEvent
+	errors chan error
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// UserSessionEvent is a session lifecycle change for one of a Manager's users.
+type UserSessionEvent struct {
+	UserID string
+	auth.SessionEvent
+}
+
+// UserError is a transient session check failure for one of a Manager's users.
+type UserError struct {
+	UserID string
+	Err    error
+}
+
+func (e *UserError) Error() string {
+	return fmt.Sprintf("user %s: %v", e.UserID, e.Err)
+}
+
+func (e *UserError) Unwrap() error {
+	return e.Err
+}
+
+// NewManager creates a Manager. opts apply to every user's client. The HTTP
+// client and rate limiter they configure (or the defaults) are shared by all
+// users.
+func NewManager(opts ...Option) *Manager {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	shared := client.NewClient(cfg.clientOpts...)
+
+	return &Manager{
+		opts:        opts,
+		httpClient:  shared.HTTPClient(),
+		rateLimiter: shared.RateLimiter(),
+		users:       make(map[string]*Avanza),
+		monitors:    make(map[string]*auth.SessionMonitor),
+		events:      make(chan UserSessionEvent, 10),
+		errors:      make(chan error, 10),
+		done:        make(chan struct{}),
+	}
+}
+
+// Add creates the client for userID. opts apply to this user only and come
+// after the Manager's options, e.g. a per-user WithSessionStore. Options that
+// replace the HTTP client or rate limiter are ignored so that they stay shared.
+func (m *Manager) Add(userID string, opts ...Option) (*Avanza, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return nil, fmt.Errorf("add user %s: manager is closed", userID)
+	}
+	if _, ok := m.users[userID]; ok {
+		return nil, fmt.Errorf("add user %s: already exists", userID)
+	}
+
+	all := make([]Option, 0, len(m.opts)+len(opts)+2)
+	all = append(all, m.opts...)
+	all = append(all, opts...)
+	all = append(all, WithHTTPClient(m.httpClient), WithRateLimiter(m.rateLimiter))
+
+	a := New(all...)
+	m.users[userID] = a
+	return a, nil
+}
+
+// User returns the client for userID.
+func (m *Manager) User(userID string) (*Avanza, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.users[userID]
+	return a, ok
+}
+
+// Users returns the IDs of all users, sorted.
+func (m *Manager) Users() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, 0, len(m.users))
+	for id := range m.users {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Monitor starts a session monitor for userID (see auth.AuthService.MonitorSession)
+// and forwards its events to Events and its errors to Errors. A running
+// monitor for the user is replaced. When the user's session expires, their
+// monitor stops; call Monitor again after they log in.
+func (m *Manager) Monitor(ctx context.Context, userID string, cfg *auth.SessionMonitorConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return fmt.Errorf("monitor user %s: manager is closed", userID)
+	}
+	a, ok := m.users[userID]
+	if !ok {
+		return fmt.Errorf("monitor user %s: %w", userID, ErrUnknownUser)
+	}
+	if old, ok := m.monitors[userID]; ok {
+		old.Close()
+	}
+
+	mon := a.Auth.MonitorSession(ctx, cfg)
+	m.monitors[userID] = mon
+
+	m.wg.Add(1)
+	go m.forward(userID, mon)
+	return nil
+}
+
+// forward copies a monitor's events and errors to the Manager's channels
+// until the monitor stops.
+func (m *Manager) forward(userID string, mon *auth.SessionMonitor) {
+	defer m.wg.Done()
+
+	events, errs := mon.Events(), mon.Errors()
+	for events != nil || errs != nil {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			select {
+			case m.events <- UserSessionEvent{UserID: userID, SessionEvent: ev}:
+			case <-m.done:
+				return
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			select {
+			case m.errors <- &UserError{UserID: userID, Err: err}:
+			default:
+			}
+		}
+	}
+}
+
+// Events returns a channel that receives session lifecycle changes for all
+// monitored users. It is closed by Close. Read it while monitors run;
+// otherwise they block once the buffer is full.
+func (m *Manager) Events() <-chan UserSessionEvent {
+	return m.events
+}
+
+// Errors returns a channel that receives *UserError values for transient
+// session check failures. It is closed by Close.
+func (m *Manager) Errors() <-chan error {
+	return m.errors
+}
+
+// Remove stops the user's monitor and drops their client. The user's saved
+// session, if any, is kept; call Auth.ClearSession first to log them out for good.
+func (m *Manager) Remove(userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[userID]; !ok {
+		return fmt.Errorf("remove user %s: %w", userID, ErrUnknownUser)
+	}
+	if mon, ok := m.monitors[userID]; ok {
+		mon.Close()
+		delete(m.monitors, userID)
+	}
+	delete(m.users, userID)
+	return nil
+}
+
+// Close stops all monitors and closes Events and Errors. The users' clients
+// remain usable.
+func (m *Manager) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	close(m.done)
+	for id, mon := range m.monitors {
+		mon.Close()
+		delete(m.monitors, id)
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+	close(m.events)
+	close(m.errors)
+}
@@ -0,0 +1,147 @@
+package avanza
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/auth"
+	"github.com/vmorsell/avanza-sdk-go/client"
+)
+
+func TestManager_SharesTransportAndLimiter(t *testing.T) {
+	limiter := &client.SimpleRateLimiter{Interval: time.Millisecond}
+	m := NewManager(WithBaseURL("http://localhost:9999"), WithRateLimiter(limiter))
+	defer m.Close()
+
+	anna, err := m.Add("anna")
+	if err != nil {
+		t.Fatalf("Add anna: %v", err)
+	}
+	bo, err := m.Add("bo", WithHTTPClient(&http.Client{}))
+	if err != nil {
+		t.Fatalf("Add bo: %v", err)
+	}
+
+	if anna.client.HTTPClient() != bo.client.HTTPClient() {
+		t.Error("expected users to share the HTTP client")
+	}
+	if anna.client.RateLimiter() != limiter || bo.client.RateLimiter() != limiter {
+		t.Error("expected users to share the configured rate limiter")
+	}
+	if anna.client.BaseURL() != "http://localhost:9999" {
+		t.Errorf("BaseURL = %q, want manager option applied", anna.client.BaseURL())
+	}
+
+	anna.client.SetCookies(map[string]string{"csid": "anna"})
+	if len(bo.client.Cookies()) != 0 {
+		t.Error("expected cookies to be per user")
+	}
+
+	if _, err := m.Add("anna"); err == nil {
+		t.Error("expected error adding an existing user")
+	}
+	if got := m.Users(); len(got) != 2 || got[0] != "anna" || got[1] != "bo" {
+		t.Errorf("Users() = %v, want [anna bo]", got)
+	}
+}
+
+func TestManager_MonitorPerUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, _ := r.Cookie("csid")
+		if cookie == nil || cookie.Value != "anna" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(auth.SessionInfo{User: auth.User{LoggedIn: true}})
+	}))
+	defer server.Close()
+
+	m := NewManager(WithBaseURL(server.URL), WithRateLimiter(nil))
+	defer m.Close()
+
+	anna, _ := m.Add("anna")
+	anna.client.SetCookies(map[string]string{"csid": "anna"})
+	bo, _ := m.Add("bo")
+	bo.client.SetCookies(map[string]string{"csid": "stale"})
+
+	ctx := context.Background()
+	cfg := &auth.SessionMonitorConfig{Interval: time.Hour}
+	if err := m.Monitor(ctx, "anna", cfg); err != nil {
+		t.Fatalf("Monitor anna: %v", err)
+	}
+	if err := m.Monitor(ctx, "bo", cfg); err != nil {
+		t.Fatalf("Monitor bo: %v", err)
+	}
+
+	got := map[string]auth.SessionState{}
+	for len(got) < 2 {
+		select {
+		case ev := <-m.Events():
+			got[ev.UserID] = ev.State
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out, got %v", got)
+		}
+	}
+
+	if got["anna"] != auth.SessionStateActive {
+		t.Errorf("anna = %s, want %s", got["anna"], auth.SessionStateActive)
+	}
+	if got["bo"] != auth.SessionStateExpired {
+		t.Errorf("bo = %s, want %s", got["bo"], auth.SessionStateExpired)
+	}
+	if len(anna.client.Cookies()) == 0 {
+		t.Error("anna's session should be untouched by bo's expiry")
+	}
+}
+
+func TestManager_UnknownUser(t *testing.T) {
+	m := NewManager()
+	defer m.Close()
+
+	if err := m.Monitor(context.Background(), "nobody", nil); !errors.Is(err, ErrUnknownUser) {
+		t.Errorf("Monitor err = %v, want ErrUnknownUser", err)
+	}
+	if err := m.Remove("nobody"); !errors.Is(err, ErrUnknownUser) {
+		t.Errorf("Remove err = %v, want ErrUnknownUser", err)
+	}
+	if _, ok := m.User("nobody"); ok {
+		t.Error("User() found a user that was never added")
+	}
+}
+
+func TestManager_CloseClosesChannels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(auth.SessionInfo{User: auth.User{LoggedIn: true}})
+	}))
+	defer server.Close()
+
+	m := NewManager(WithBaseURL(server.URL))
+	if _, err := m.Add("anna"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := m.Monitor(context.Background(), "anna", &auth.SessionMonitorConfig{Interval: time.Hour}); err != nil {
+		t.Fatalf("Monitor: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return")
+	}
+
+	for range m.Events() {
+	}
+	if _, err := m.Add("bo"); err == nil {
+		t.Error("expected error adding a user after Close")
+	}
+}
@@ -0,0 +1,109 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/cache"
+	"github.com/vmorsell/avanza-sdk-go/client"
+)
+
+// CachedEndpoint names a Service method whose responses WithCache caches.
+type CachedEndpoint string
+
+const (
+	CacheOrderbook          CachedEndpoint = "orderbook"           // GetOrderbook: tick sizes, trading rules, feature support
+	CacheStockDetails       CachedEndpoint = "stock-details"       // GetStockDetails
+	CacheCertificateDetails CachedEndpoint = "certificate-details" // GetCertificateDetails
+	CacheWarrantDetails     CachedEndpoint = "warrant-details"     // GetWarrantDetails
+)
+
+// DefaultCacheTTLs is how long WithCache keeps each endpoint's responses when
+// no TTL is given for it.
+var DefaultCacheTTLs = map[CachedEndpoint]time.Duration{
+	CacheOrderbook:          time.Hour,
+	CacheStockDetails:       6 * time.Hour,
+	CacheCertificateDetails: 6 * time.Hour,
+	CacheWarrantDetails:     6 * time.Hour,
+}
+
+// Option is a functional option for configuring the Service.
+type Option func(*Service)
+
+// WithCache caches the responses of GetOrderbook and the Get*Details methods
+// in store, and collapses concurrent identical calls into one request. ttls
+// overrides DefaultCacheTTLs per endpoint; a TTL of zero turns caching off for
+// that endpoint.
+//
+//	svc := market.NewService(c, market.WithCache(cache.NewLRU(1000), map[market.CachedEndpoint]time.Duration{
+//		market.CacheOrderbook: 15 * time.Minute,
+//	}))
+func WithCache(store cache.Store, ttls map[CachedEndpoint]time.Duration) Option {
+	return func(s *Service) {
+		s.cache = cache.New(store)
+		s.cacheTTLs = maps.Clone(DefaultCacheTTLs)
+		maps.Copy(s.cacheTTLs, ttls)
+	}
+}
+
+// InvalidateCache drops the cached responses for orderbookID, e.g. after a
+// corporate action changed its trading rules.
+func (s *Service) InvalidateCache(orderbookID string) {
+	if s.cache == nil {
+		return
+	}
+	id := url.PathEscape(orderbookID)
+	s.cache.Invalidate(
+		s.cacheKey(fmt.Sprintf("/_api/trading-critical/rest/orderbook/%s", id)),
+		s.cacheKey(fmt.Sprintf("/_api/market-guide/stock/%s/details", id)),
+		s.cacheKey(fmt.Sprintf("/_api/market-guide/certificate/%s/details", id)),
+		s.cacheKey(fmt.Sprintf("/_api/market-guide/warrant/%s/details", id)),
+	)
+}
+
+// ClearCache drops all cached responses.
+func (s *Service) ClearCache() {
+	if s.cache != nil {
+		s.cache.Clear()
+	}
+}
+
+// cacheKey includes the base URL, so a store shared with a test server never
+// serves its responses to a production client.
+func (s *Service) cacheKey(endpoint string) string {
+	return s.client.BaseURL() + endpoint
+}
+
+// getCached returns the body of a GET to endpoint, from the cache if one is
+// configured.
+func (s *Service) getCached(ctx context.Context, kind CachedEndpoint, endpoint string) ([]byte, error) {
+	if s.cache == nil {
+		return s.getBody(ctx, endpoint)
+	}
+	return s.cache.Fetch(ctx, s.cacheKey(endpoint), s.cacheTTLs[kind], func(ctx context.Context) ([]byte, error) {
+		return s.getBody(ctx, endpoint)
+	})
+}
+
+func (s *Service) getBody(ctx context.Context, endpoint string) ([]byte, error) {
+	httpResp, err := s.client.Get(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, client.NewHTTPError(httpResp)
+	}
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,127 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/cache"
+	"github.com/vmorsell/avanza-sdk-go/client"
+)
+
+func TestWithCache_CachesOrderbookAndDetails(t *testing.T) {
+	var hits sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := hits.LoadOrStore(r.URL.Path, new(atomic.Int32))
+		n.(*atomic.Int32).Add(1)
+		_, _ = w.Write([]byte(`{"id":"5246","name":"Investor A"}`))
+	}))
+	defer server.Close()
+
+	svc := NewService(newTestClient(server.URL), WithCache(cache.NewLRU(10), nil))
+	ctx := context.Background()
+
+	for range 3 {
+		ob, err := svc.GetOrderbook(ctx, "5246")
+		if err != nil {
+			t.Fatalf("GetOrderbook: %v", err)
+		}
+		if ob.Name != "Investor A" {
+			t.Errorf("Name = %q, want Investor A", ob.Name)
+		}
+		if _, err := svc.GetStockDetails(ctx, "5246"); err != nil {
+			t.Fatalf("GetStockDetails: %v", err)
+		}
+	}
+
+	for _, path := range []string{"/_api/trading-critical/rest/orderbook/5246", "/_api/market-guide/stock/5246/details"} {
+		n, _ := hits.Load(path)
+		if got := n.(*atomic.Int32).Load(); got != 1 {
+			t.Errorf("%s fetched %d times, want 1", path, got)
+		}
+	}
+
+	svc.InvalidateCache("5246")
+	if _, err := svc.GetOrderbook(ctx, "5246"); err != nil {
+		t.Fatalf("GetOrderbook: %v", err)
+	}
+	n, _ := hits.Load("/_api/trading-critical/rest/orderbook/5246")
+	if got := n.(*atomic.Int32).Load(); got != 2 {
+		t.Errorf("orderbook fetched %d times after InvalidateCache, want 2", got)
+	}
+}
+
+func TestWithCache_CollapsesConcurrentCalls(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"id":"5246"}`))
+	}))
+	defer server.Close()
+
+	c := client.NewClient(client.WithBaseURL(server.URL), client.WithRateLimiter(nil))
+	svc := NewService(c, WithCache(cache.NewLRU(10), nil))
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.GetOrderbook(context.Background(), "5246"); err != nil {
+				t.Errorf("GetOrderbook: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if hits.Load() != 1 {
+		t.Errorf("server hit %d times, want 1", hits.Load())
+	}
+}
+
+func TestWithCache_ZeroTTLDisablesEndpoint(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	svc := NewService(newTestClient(server.URL), WithCache(cache.NewLRU(10), map[CachedEndpoint]time.Duration{
+		CacheWarrantDetails: 0,
+	}))
+
+	for range 2 {
+		if _, err := svc.GetWarrantDetails(context.Background(), "1"); err != nil {
+			t.Fatalf("GetWarrantDetails: %v", err)
+		}
+	}
+	if hits.Load() != 2 {
+		t.Errorf("server hit %d times, want 2", hits.Load())
+	}
+}
+
+func TestWithCache_ErrorsAreNotCached(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer server.Close()
+
+	svc := NewService(newTestClient(server.URL), WithCache(cache.NewLRU(10), nil))
+	if _, err := svc.GetCertificateDetails(context.Background(), "1"); err == nil {
+		t.Fatal("expected error on first call")
+	}
+	if _, err := svc.GetCertificateDetails(context.Background(), "1"); err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+}
@@ -4,10 +4,11 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
-	"strings"
+	"reflect"
 	"testing"
 
-	"github.com/vmorsell/avanza-sdk-go/internal/schema"
+	"github.com/vmorsell/avanza-sdk-go/internal/drifttest"
+	"github.com/vmorsell/avanza-sdk-go/schema"
 )
 
 // driftFixtures maps each golden capture in testdata/ to the type it must
@@ -40,43 +41,42 @@ var driftFixtures = map[string]func() any{
 	"warrant_details.json":     func() any { return &WarrantDetails{} },
 }
 
-func TestSchemaDrift(t *testing.T) {
-	for name, newTarget := range driftFixtures {
-		t.Run(name, func(t *testing.T) {
-			raw, err := os.ReadFile(filepath.Join("testdata", name))
-			if err != nil {
-				t.Fatalf("read fixture: %v", err)
-			}
-			assertNoDrift(t, name, raw, newTarget())
-		})
-	}
+// knownDrift are the issues the fixtures are known to have. Outside an
+// off-hours session Avanza sends a null status, which OffHoursPrice.Status
+// decodes to "" as documented.
+var knownDrift = []schema.Issue{
+	{Kind: schema.Null, Path: "status", Want: "string"},
 }
 
-// assertNoDrift verifies that raw decodes into target with no unmapped fields.
-// A plain decode catches type-level drift (a field whose JSON type changed);
-// UnknownFields catches the more common structural drift of new or renamed keys.
-// label identifies the payload in failure messages (a fixture name or a URL).
-func assertNoDrift(t *testing.T, label string, raw []byte, target any) {
-	t.Helper()
+func TestSchemaDrift(t *testing.T) {
+	drifttest.Run(t, "testdata", driftFixtures, knownDrift...)
+}
 
-	if err := json.Unmarshal(raw, target); err != nil {
-		t.Fatalf("decode %s into type: %v\n%s", label, err, snippet(raw))
+func TestSchemaDriftReportsBadPrice(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "stock_quote.json"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var quote map[string]any
+	if err := json.Unmarshal(raw, &quote); err != nil {
+		t.Fatal(err)
+	}
+	quote["buy"] = map[string]any{"v": 1}
+	quote["sell"] = nil
+	raw, err = json.Marshal(quote)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	unknown, err := schema.UnknownFields(raw, target)
+	issues, err := schema.Check(raw, &Quote{})
 	if err != nil {
-		t.Fatalf("UnknownFields: %v", err)
+		t.Fatalf("Check: %v", err)
 	}
-	if len(unknown) > 0 {
-		t.Errorf("%s returns fields not modelled by the Go type — add them:\n  %s",
-			label, strings.Join(unknown, "\n  "))
+	want := []schema.Issue{
+		{Kind: schema.Mismatch, Path: "buy", Want: "number", Got: "object"},
+		{Kind: schema.Null, Path: "sell", Want: "number"},
 	}
-}
-
-func snippet(raw []byte) string {
-	const max = 500
-	if len(raw) > max {
-		return string(raw[:max]) + "…"
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("Check = %v, want %v", issues, want)
 	}
-	return string(raw)
 }
@@ -7,22 +7,30 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/vmorsell/avanza-sdk-go/cache"
 	"github.com/vmorsell/avanza-sdk-go/client"
 	"github.com/vmorsell/avanza-sdk-go/internal/sse"
 )
 
 // Service handles market data and real-time subscriptions.
 type Service struct {
-	client *client.Client
+	client    *client.Client
+	cache     *cache.Cache
+	cacheTTLs map[CachedEndpoint]time.Duration
 }
 
 // NewService creates a new market service.
-func NewService(client *client.Client) *Service {
-	return &Service{
+func NewService(client *client.Client, opts ...Option) *Service {
+	s := &Service{
 		client: client,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Search searches for instruments by name, ticker, or other text.
@@ -67,7 +75,7 @@ func (s *Service) Search(ctx context.Context, req *SearchRequest) (*SearchRespon
 	}
 
 	var resp SearchResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -95,7 +103,7 @@ func (s *Service) GetStock(ctx context.Context, orderbookID string) (*Stock, err
 	}
 
 	var resp Stock
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -121,7 +129,7 @@ func (s *Service) GetCertificate(ctx context.Context, orderbookID string) (*Cert
 	}
 
 	var resp Certificate
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -147,7 +155,7 @@ func (s *Service) GetWarrant(ctx context.Context, orderbookID string) (*Warrant,
 	}
 
 	var resp Warrant
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -155,6 +163,8 @@ func (s *Service) GetWarrant(ctx context.Context, orderbookID string) (*Warrant,
 }
 
 // GetOrderbook returns trading parameters for an instrument (tick sizes, feature support, validity dates).
+//
+// Responses are cached when the Service is created WithCache.
 func (s *Service) GetOrderbook(ctx context.Context, orderbookID string) (*Orderbook, error) {
 	if orderbookID == "" {
 		return nil, fmt.Errorf("orderbookID is required")
@@ -162,20 +172,16 @@ func (s *Service) GetOrderbook(ctx context.Context, orderbookID string) (*Orderb
 
 	endpoint := fmt.Sprintf("/_api/trading-critical/rest/orderbook/%s", url.PathEscape(orderbookID))
 
-	httpResp, err := s.client.Get(ctx, endpoint)
+	body, err := s.getCached(ctx, CacheOrderbook, endpoint)
 	if err != nil {
 		return nil, err
 	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, client.NewHTTPError(httpResp)
-	}
 
 	var resp Orderbook
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
+	s.client.CheckDrift(endpoint, body, &resp)
 
 	return &resp, nil
 }
@@ -199,7 +205,7 @@ func (s *Service) GetMarketData(ctx context.Context, orderbookID string) (*Marke
 	}
 
 	var resp MarketData
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -231,7 +237,7 @@ func (s *Service) GetMarketMakerPriceChart(ctx context.Context, orderbookID stri
 	}
 
 	var resp MarketMakerPriceChart
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -242,6 +248,8 @@ func (s *Service) GetMarketMakerPriceChart(ctx context.Context, orderbookID stri
 // ownership, corporate events, dividends, holdings, fund/ETF exposure, and more.
 //
 // This returns public market data and does not require an authenticated session.
+//
+// Responses are cached when the Service is created WithCache.
 func (s *Service) GetStockDetails(ctx context.Context, orderbookID string) (*StockDetails, error) {
 	if orderbookID == "" {
 		return nil, fmt.Errorf("orderbookID is required")
@@ -249,20 +257,16 @@ func (s *Service) GetStockDetails(ctx context.Context, orderbookID string) (*Sto
 
 	endpoint := fmt.Sprintf("/_api/market-guide/stock/%s/details", url.PathEscape(orderbookID))
 
-	httpResp, err := s.client.Get(ctx, endpoint)
+	body, err := s.getCached(ctx, CacheStockDetails, endpoint)
 	if err != nil {
 		return nil, err
 	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, client.NewHTTPError(httpResp)
-	}
 
 	var resp StockDetails
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
+	s.client.CheckDrift(endpoint, body, &resp)
 
 	return &resp, nil
 }
@@ -290,7 +294,7 @@ func (s *Service) GetStockQuote(ctx context.Context, orderbookID string) (*Quote
 	}
 
 	var resp Quote
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -319,7 +323,7 @@ func (s *Service) GetStockOrderDepth(ctx context.Context, orderbookID string) (*
 	}
 
 	var resp MarketDataOrderDepth
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -348,7 +352,7 @@ func (s *Service) GetStockMarketPlace(ctx context.Context, orderbookID string) (
 	}
 
 	var resp MarketPlace
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -378,7 +382,7 @@ func (s *Service) GetOffHoursPrice(ctx context.Context, orderbookID string) (*Of
 	}
 
 	var resp OffHoursPrice
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -389,6 +393,8 @@ func (s *Service) GetOffHoursPrice(ctx context.Context, orderbookID string) (*Of
 // regulatory documents, order book, and collateral terms.
 //
 // This returns public market data and does not require an authenticated session.
+//
+// Responses are cached when the Service is created WithCache.
 func (s *Service) GetCertificateDetails(ctx context.Context, orderbookID string) (*CertificateDetails, error) {
 	if orderbookID == "" {
 		return nil, fmt.Errorf("orderbookID is required")
@@ -396,20 +402,16 @@ func (s *Service) GetCertificateDetails(ctx context.Context, orderbookID string)
 
 	endpoint := fmt.Sprintf("/_api/market-guide/certificate/%s/details", url.PathEscape(orderbookID))
 
-	httpResp, err := s.client.Get(ctx, endpoint)
+	body, err := s.getCached(ctx, CacheCertificateDetails, endpoint)
 	if err != nil {
 		return nil, err
 	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, client.NewHTTPError(httpResp)
-	}
 
 	var resp CertificateDetails
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
+	s.client.CheckDrift(endpoint, body, &resp)
 
 	return &resp, nil
 }
@@ -418,6 +420,8 @@ func (s *Service) GetCertificateDetails(ctx context.Context, orderbookID string)
 // documents, order book, and trading terms.
 //
 // This returns public market data and does not require an authenticated session.
+//
+// Responses are cached when the Service is created WithCache.
 func (s *Service) GetWarrantDetails(ctx context.Context, orderbookID string) (*WarrantDetails, error) {
 	if orderbookID == "" {
 		return nil, fmt.Errorf("orderbookID is required")
@@ -425,20 +429,16 @@ func (s *Service) GetWarrantDetails(ctx context.Context, orderbookID string) (*W
 
 	endpoint := fmt.Sprintf("/_api/market-guide/warrant/%s/details", url.PathEscape(orderbookID))
 
-	httpResp, err := s.client.Get(ctx, endpoint)
+	body, err := s.getCached(ctx, CacheWarrantDetails, endpoint)
 	if err != nil {
 		return nil, err
 	}
-	defer httpResp.Body.Close()
-
-	if httpResp.StatusCode != http.StatusOK {
-		return nil, client.NewHTTPError(httpResp)
-	}
 
 	var resp WarrantDetails
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
+	s.client.CheckDrift(endpoint, body, &resp)
 
 	return &resp, nil
 }
@@ -470,7 +470,7 @@ func (s *Service) GetStockPriceChart(ctx context.Context, orderbookID string, ti
 	}
 
 	var resp StockPriceChart
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -507,7 +507,7 @@ func (s *Service) GetStockPriceChartComparison(ctx context.Context, orderbookID,
 	}
 
 	var resp StockPriceChart
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -536,7 +536,7 @@ func (s *Service) GetNews(ctx context.Context, orderbookID string) (*News, error
 	}
 
 	var resp News
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -564,7 +564,7 @@ func (s *Service) GetForum(ctx context.Context, orderbookID string) (*Forum, err
 	}
 
 	var resp Forum
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -590,11 +590,12 @@ func (s *Service) SubscribeToOrderDepth(ctx context.Context, orderbookID string)
 	}
 
 	escapedID := url.PathEscape(orderbookID)
+	endpoint := fmt.Sprintf("/_push/order-depth-web-push/%s", escapedID)
 	sub := sse.New(ctx, sse.Config{
 		Client:   s.client,
-		Endpoint: fmt.Sprintf("/_push/order-depth-web-push/%s", escapedID),
+		Endpoint: endpoint,
 		Referer:  fmt.Sprintf("https://www.avanza.se/handla/order.html/kop/%s", escapedID),
 	})
 
-	return newOrderDepthSubscription(sub), nil
+	return newOrderDepthSubscription(sub, s.client, endpoint), nil
 }
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 )
 
 func newTestClient(baseURL string) *client.Client {
@@ -388,8 +389,8 @@ func TestGetStock_Success(t *testing.T) {
 	if resp.KeyIndicators.Dividend == nil {
 		t.Fatal("KeyIndicators.Dividend = nil, want non-nil")
 	}
-	if resp.KeyIndicators.Dividend.Amount != 4 {
-		t.Errorf("KeyIndicators.Dividend.Amount = %f, want 4", resp.KeyIndicators.Dividend.Amount)
+	if !resp.KeyIndicators.Dividend.Amount.Equal(decimal.MustParse("4")) {
+		t.Errorf("KeyIndicators.Dividend.Amount = %s, want 4", resp.KeyIndicators.Dividend.Amount)
 	}
 	if resp.KeyIndicators.NextReport == nil || resp.KeyIndicators.NextReport.ReportType != "INTERIM" {
 		t.Errorf("KeyIndicators.NextReport = %v, want INTERIM report", resp.KeyIndicators.NextReport)
@@ -400,8 +401,8 @@ func TestGetStock_Success(t *testing.T) {
 	if resp.KeyIndicators.PreviousReport == nil || !resp.KeyIndicators.PreviousReport.IsConfirmed {
 		t.Errorf("KeyIndicators.PreviousReport = %v, want confirmed report", resp.KeyIndicators.PreviousReport)
 	}
-	if resp.Quote.Last != 346.85 {
-		t.Errorf("Quote.Last = %f, want 346.85", resp.Quote.Last)
+	if !resp.Quote.Last.Equal(decimal.MustParse("346.85")) {
+		t.Errorf("Quote.Last = %s, want 346.85", resp.Quote.Last)
 	}
 }
 
@@ -539,8 +540,8 @@ func TestGetCertificate_Success(t *testing.T) {
 	if resp.Underlying.InstrumentType != "INDEX" {
 		t.Errorf("Underlying.InstrumentType = %q, want %q", resp.Underlying.InstrumentType, "INDEX")
 	}
-	if resp.Underlying.PreviousClosingPrice != 2863.92 {
-		t.Errorf("Underlying.PreviousClosingPrice = %f, want 2863.92", resp.Underlying.PreviousClosingPrice)
+	if !resp.Underlying.PreviousClosingPrice.Equal(decimal.MustParse("2863.92")) {
+		t.Errorf("Underlying.PreviousClosingPrice = %s, want 2863.92", resp.Underlying.PreviousClosingPrice)
 	}
 	if resp.AssetCategory != "Aktier" {
 		t.Errorf("AssetCategory = %q, want %q", resp.AssetCategory, "Aktier")
@@ -876,8 +877,8 @@ func TestGetStockQuote_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetStockQuote failed: %v", err)
 	}
-	if resp.Last != 194.83 {
-		t.Errorf("Last = %f, want 194.83", resp.Last)
+	if !resp.Last.Equal(decimal.MustParse("194.83")) {
+		t.Errorf("Last = %s, want 194.83", resp.Last)
 	}
 	if resp.TimeOfLast != 1783026000429 {
 		t.Errorf("TimeOfLast = %d, want 1783026000429", resp.TimeOfLast)
@@ -1116,11 +1117,11 @@ func TestGetMarketData_Success(t *testing.T) {
 		t.Fatalf("GetMarketData failed: %v", err)
 	}
 
-	if resp.Quote.Buy != 99.50 {
-		t.Errorf("Quote.Buy = %f, want 99.50", resp.Quote.Buy)
+	if !resp.Quote.Buy.Equal(decimal.MustParse("99.50")) {
+		t.Errorf("Quote.Buy = %s, want 99.50", resp.Quote.Buy)
 	}
-	if resp.Quote.Last != 99.75 {
-		t.Errorf("Quote.Last = %f, want 99.75", resp.Quote.Last)
+	if !resp.Quote.Last.Equal(decimal.MustParse("99.75")) {
+		t.Errorf("Quote.Last = %s, want 99.75", resp.Quote.Last)
 	}
 	if resp.Quote.ChangePercent != 1.27 {
 		t.Errorf("Quote.ChangePercent = %f, want 1.27", resp.Quote.ChangePercent)
@@ -1131,21 +1132,21 @@ func TestGetMarketData_Success(t *testing.T) {
 	if resp.Quote.TotalVolumeTraded != 50000 {
 		t.Errorf("Quote.TotalVolumeTraded = %d, want 50000", resp.Quote.TotalVolumeTraded)
 	}
-	if resp.Quote.VolumeWeightedAveragePrice != 99.80 {
-		t.Errorf("Quote.VolumeWeightedAveragePrice = %f, want 99.80", resp.Quote.VolumeWeightedAveragePrice)
+	if !resp.Quote.VolumeWeightedAveragePrice.Equal(decimal.MustParse("99.80")) {
+		t.Errorf("Quote.VolumeWeightedAveragePrice = %s, want 99.80", resp.Quote.VolumeWeightedAveragePrice)
 	}
 	if len(resp.OrderDepth.Levels) != 1 {
 		t.Fatalf("len(OrderDepth.Levels) = %d, want 1", len(resp.OrderDepth.Levels))
 	}
 	level := resp.OrderDepth.Levels[0]
-	if level.BuySide.Price != 99.50 {
-		t.Errorf("BuySide.Price = %f, want 99.50", level.BuySide.Price)
+	if !level.BuySide.Price.Equal(decimal.MustParse("99.50")) {
+		t.Errorf("BuySide.Price = %s, want 99.50", level.BuySide.Price)
 	}
 	if level.BuySide.Volume != 200 {
 		t.Errorf("BuySide.Volume = %v, want 200", level.BuySide.Volume)
 	}
-	if level.SellSide.Price != 100.00 {
-		t.Errorf("SellSide.Price = %f, want 100.00", level.SellSide.Price)
+	if !level.SellSide.Price.Equal(decimal.MustParse("100.00")) {
+		t.Errorf("SellSide.Price = %s, want 100.00", level.SellSide.Price)
 	}
 	if level.SellSide.PriceString != "100.00" {
 		t.Errorf("SellSide.PriceString = %q, want %q", level.SellSide.PriceString, "100.00")
@@ -1269,8 +1270,8 @@ func TestGetOrderbook_Success(t *testing.T) {
 		t.Fatalf("len(TickSizeEntries) = %d, want 2", len(resp.TickSizeList.TickSizeEntries))
 	}
 	entry := resp.TickSizeList.TickSizeEntries[0]
-	if entry.Min != 0.0 || entry.Max != 0.4999 || entry.Tick != 0.0001 {
-		t.Errorf("TickSizeEntries[0] = {%f, %f, %f}, want {0, 0.4999, 0.0001}", entry.Min, entry.Max, entry.Tick)
+	if !entry.Min.Equal(decimal.MustParse("0.0")) || !entry.Max.Equal(decimal.MustParse("0.4999")) || !entry.Tick.Equal(decimal.MustParse("0.0001")) {
+		t.Errorf("TickSizeEntries[0] = {%s, %s, %s}, want {0, 0.4999, 0.0001}", entry.Min, entry.Max, entry.Tick)
 	}
 	if !resp.FeatureSupport.StopLoss {
 		t.Error("FeatureSupport.StopLoss = false, want true")
@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/vmorsell/avanza-sdk-go/client"
 	"github.com/vmorsell/avanza-sdk-go/internal/sse"
 )
 
 // OrderDepthSubscription represents an active order depth subscription.
 type OrderDepthSubscription struct {
 	sub       *sse.Subscription
+	client    *client.Client
+	endpoint  string
 	events    chan OrderDepthEvent
 	errors    chan error
 	done      chan struct{}
@@ -37,12 +40,14 @@ func (s *OrderDepthSubscription) Close() {
 	s.wg.Wait()
 }
 
-func newOrderDepthSubscription(sub *sse.Subscription) *OrderDepthSubscription {
+func newOrderDepthSubscription(sub *sse.Subscription, c *client.Client, endpoint string) *OrderDepthSubscription {
 	s := &OrderDepthSubscription{
-		sub:    sub,
-		events: make(chan OrderDepthEvent, 100),
-		errors: make(chan error, 10),
-		done:   make(chan struct{}),
+		sub:      sub,
+		client:   c,
+		endpoint: endpoint,
+		events:   make(chan OrderDepthEvent, 100),
+		errors:   make(chan error, 10),
+		done:     make(chan struct{}),
 	}
 	s.wg.Add(1)
 	go s.run()
@@ -92,6 +97,7 @@ func (s *OrderDepthSubscription) forwardEvent(raw sse.RawEvent) {
 		}
 		return
 	}
+	s.client.CheckDrift(s.endpoint, raw.Data, &data)
 
 	s.trySendEvent(OrderDepthEvent{
 		Event: raw.Event,
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 )
 
 func writeSSEEvent(w http.ResponseWriter, id, event, data string) {
@@ -49,8 +50,8 @@ func TestOrderDepthSubscription_ReceivesEvents(t *testing.T) {
 		if len(e.Data.Levels) != 1 {
 			t.Fatalf("levels count = %d, want 1", len(e.Data.Levels))
 		}
-		if e.Data.Levels[0].BuyPrice != 100.5 {
-			t.Errorf("buy price = %f, want 100.5", e.Data.Levels[0].BuyPrice)
+		if !e.Data.Levels[0].BuyPrice.Equal(decimal.MustParse("100.5")) {
+			t.Errorf("buy price = %s, want 100.5", e.Data.Levels[0].BuyPrice)
 		}
 	case <-time.After(5 * time.Second):
 		t.Fatal("timed out waiting for event")
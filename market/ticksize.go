@@ -0,0 +1,100 @@
+package market
+
+import (
+	"fmt"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+)
+
+// Tick returns the tick size of the price band that price falls in. A price
+// between two bands, such as 0.49995 between 0–0.4999 and 0.5–0.9998, is in
+// the lower band.
+func (l TickSizeList) Tick(price decimal.Decimal) (decimal.Decimal, error) {
+	i, err := l.band(price)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	return l.TickSizeEntries[i].Tick, nil
+}
+
+// IsValid reports whether price is a multiple of its band's tick size, i.e.
+// whether Avanza accepts it as an order price.
+func (l TickSizeList) IsValid(price decimal.Decimal) bool {
+	i, err := l.band(price)
+	if err != nil {
+		return false
+	}
+	e := l.TickSizeEntries[i]
+	return price.Cmp(e.Max) <= 0 && price.Mod(e.Tick).IsZero()
+}
+
+// RoundDown returns the highest valid price at or below price, with no more
+// decimals than the tick size.
+func (l TickSizeList) RoundDown(price decimal.Decimal) (decimal.Decimal, error) {
+	i, err := l.band(price)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	e := l.TickSizeEntries[i]
+	if price.Cmp(e.Max) > 0 {
+		return e.Max, nil
+	}
+	rem := price.Mod(e.Tick)
+	if rem.IsZero() {
+		return price, nil
+	}
+	return price.Sub(rem).Round(e.Tick.Scale()), nil
+}
+
+// RoundUp returns the lowest valid price at or above price. A price past the
+// last tick of its band rounds up to the first price of the next band.
+func (l TickSizeList) RoundUp(price decimal.Decimal) (decimal.Decimal, error) {
+	i, err := l.band(price)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	e := l.TickSizeEntries[i]
+	rem := price.Mod(e.Tick)
+	if rem.IsZero() && price.Cmp(e.Max) <= 0 {
+		return price, nil
+	}
+	up := price.Sub(rem).Add(e.Tick).Round(e.Tick.Scale())
+	if up.Cmp(e.Max) > 0 && i+1 < len(l.TickSizeEntries) {
+		up = l.TickSizeEntries[i+1].Min
+	}
+	return up, nil
+}
+
+// RoundNearest returns the valid price closest to price. A price halfway
+// between two valid prices rounds up.
+func (l TickSizeList) RoundNearest(price decimal.Decimal) (decimal.Decimal, error) {
+	down, err := l.RoundDown(price)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	up, err := l.RoundUp(price)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if price.Sub(down).Cmp(up.Sub(price)) < 0 {
+		return down, nil
+	}
+	return up, nil
+}
+
+// band returns the index of the last entry whose Min is at or below price.
+func (l TickSizeList) band(price decimal.Decimal) (int, error) {
+	if price.Sign() <= 0 {
+		return 0, fmt.Errorf("price must be greater than 0")
+	}
+	i := -1
+	for j, e := range l.TickSizeEntries {
+		if e.Min.Cmp(price) <= 0 && e.Tick.Sign() > 0 {
+			i = j
+		}
+	}
+	if i < 0 {
+		return 0, fmt.Errorf("no tick size for price %s", price)
+	}
+	return i, nil
+}
@@ -0,0 +1,92 @@
+package market
+
+import (
+	"testing"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+)
+
+var testTickSizes = TickSizeList{TickSizeEntries: []TickSizeEntry{
+	{Min: decimal.MustParse("0.0"), Max: decimal.MustParse("0.4999"), Tick: decimal.MustParse("0.0001")},
+	{Min: decimal.MustParse("0.5"), Max: decimal.MustParse("0.9998"), Tick: decimal.MustParse("0.0002")},
+	{Min: decimal.MustParse("1"), Max: decimal.MustParse("99.99"), Tick: decimal.MustParse("0.01")},
+	{Min: decimal.MustParse("100"), Max: decimal.MustParse("499.95"), Tick: decimal.MustParse("0.05")},
+	{Min: decimal.MustParse("500"), Max: decimal.MustParse("999999"), Tick: decimal.MustParse("0.5")},
+}}
+
+func TestTickSizeList_Round(t *testing.T) {
+	tests := []struct {
+		price, down, up, nearest string
+	}{
+		{"245.50", "245.50", "245.50", "245.50"},
+		{"245.51", "245.50", "245.55", "245.50"},
+		{"245.525", "245.50", "245.55", "245.55"},
+		{"245.54", "245.50", "245.55", "245.55"},
+		{"0.9999", "0.9998", "1", "1"},
+		{"0.49995", "0.4999", "0.5", "0.5"},
+		{"99.995", "99.99", "100", "100"},
+		{"0.0001", "0.0001", "0.0001", "0.0001"},
+		{"612.3", "612.0", "612.5", "612.5"},
+		{"245", "245", "245", "245"},
+	}
+	for _, tt := range tests {
+		price := decimal.MustParse(tt.price)
+		for _, r := range []struct {
+			name string
+			fn   func(decimal.Decimal) (decimal.Decimal, error)
+			want string
+		}{
+			{"RoundDown", testTickSizes.RoundDown, tt.down},
+			{"RoundUp", testTickSizes.RoundUp, tt.up},
+			{"RoundNearest", testTickSizes.RoundNearest, tt.nearest},
+		} {
+			got, err := r.fn(price)
+			if err != nil {
+				t.Errorf("%s(%s): %v", r.name, tt.price, err)
+				continue
+			}
+			if got.String() != r.want {
+				t.Errorf("%s(%s) = %s, want %s", r.name, tt.price, got, r.want)
+			}
+		}
+	}
+}
+
+func TestTickSizeList_IsValid(t *testing.T) {
+	tests := []struct {
+		price string
+		want  bool
+	}{
+		{"245.55", true},
+		{"245.56", false},
+		{"0.5002", true},
+		{"0.5001", false},
+		{"0.49995", false},
+		{"612.5", true},
+		{"0", false},
+		{"-1", false},
+	}
+	for _, tt := range tests {
+		if got := testTickSizes.IsValid(decimal.MustParse(tt.price)); got != tt.want {
+			t.Errorf("IsValid(%s) = %v, want %v", tt.price, got, tt.want)
+		}
+	}
+
+	tick, err := testTickSizes.Tick(decimal.MustParse("245.5"))
+	if err != nil || !tick.Equal(decimal.MustParse("0.05")) {
+		t.Errorf("Tick(245.5) = %s, %v, want 0.05", tick, err)
+	}
+}
+
+func TestTickSizeList_Errors(t *testing.T) {
+	if _, err := (TickSizeList{}).RoundNearest(decimal.MustParse("10")); err == nil {
+		t.Error("empty table: want an error")
+	}
+	if _, err := testTickSizes.RoundUp(decimal.MustParse("0")); err == nil {
+		t.Error("zero price: want an error")
+	}
+	above := TickSizeList{TickSizeEntries: testTickSizes.TickSizeEntries[2:]}
+	if _, err := above.RoundDown(decimal.MustParse("0.5")); err == nil {
+		t.Error("price below the table: want an error")
+	}
+}
@@ -1,14 +1,18 @@
 // Package market provides market data functionality for the Avanza API.
 package market
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+)
 
 // OrderDepthLevel contains bid/ask prices and volumes at a single price level.
 type OrderDepthLevel struct {
-	BuyPrice   float64 `json:"buyPrice"`
-	BuyVolume  float64 `json:"buyVolume"`
-	SellPrice  float64 `json:"sellPrice"`
-	SellVolume float64 `json:"sellVolume"`
+	BuyPrice   decimal.Decimal `json:"buyPrice"`
+	BuyVolume  float64         `json:"buyVolume"`
+	SellPrice  decimal.Decimal `json:"sellPrice"`
+	SellVolume float64         `json:"sellVolume"`
 }
 
 // OrderDepthData contains the complete order book snapshot.
@@ -151,20 +155,20 @@ type Listing struct {
 
 // Quote contains real-time price and trading data.
 type Quote struct {
-	Buy                        float64 `json:"buy"`
-	Sell                       float64 `json:"sell"`
-	Last                       float64 `json:"last"`
-	Highest                    float64 `json:"highest"`
-	Lowest                     float64 `json:"lowest"`
-	Change                     float64 `json:"change"`
-	ChangePercent              float64 `json:"changePercent"`
-	Spread                     float64 `json:"spread"`
-	TimeOfLast                 int64   `json:"timeOfLast"`
-	TotalValueTraded           float64 `json:"totalValueTraded"`
-	TotalVolumeTraded          float64 `json:"totalVolumeTraded"`
-	Updated                    int64   `json:"updated"`
-	VolumeWeightedAveragePrice float64 `json:"volumeWeightedAveragePrice"`
-	IsRealTime                 bool    `json:"isRealTime"`
+	Buy                        decimal.Decimal `json:"buy"`
+	Sell                       decimal.Decimal `json:"sell"`
+	Last                       decimal.Decimal `json:"last"`
+	Highest                    decimal.Decimal `json:"highest"`
+	Lowest                     decimal.Decimal `json:"lowest"`
+	Change                     decimal.Decimal `json:"change"`
+	ChangePercent              float64         `json:"changePercent"`
+	Spread                     decimal.Decimal `json:"spread"`
+	TimeOfLast                 int64           `json:"timeOfLast"`
+	TotalValueTraded           decimal.Decimal `json:"totalValueTraded"`
+	TotalVolumeTraded          float64         `json:"totalVolumeTraded"`
+	Updated                    int64           `json:"updated"`
+	VolumeWeightedAveragePrice decimal.Decimal `json:"volumeWeightedAveragePrice"`
+	IsRealTime                 bool            `json:"isRealTime"`
 }
 
 // HistoricalClosingPrices contains closing prices across various time periods.
@@ -186,14 +190,14 @@ type HistoricalClosingPrices struct {
 
 // Underlying describes the underlying instrument for derivatives.
 type Underlying struct {
-	OrderbookID          string  `json:"orderbookId"`
-	Name                 string  `json:"name"`
-	InstrumentType       string  `json:"instrumentType"`
-	InstrumentSubType    string  `json:"instrumentSubType"`
-	Quote                Quote   `json:"quote"`
-	Listing              Listing `json:"listing"`
-	PreviousClosingPrice float64 `json:"previousClosingPrice"`
-	Reference            bool    `json:"reference"`
+	OrderbookID          string          `json:"orderbookId"`
+	Name                 string          `json:"name"`
+	InstrumentType       string          `json:"instrumentType"`
+	InstrumentSubType    string          `json:"instrumentSubType"`
+	Quote                Quote           `json:"quote"`
+	Listing              Listing         `json:"listing"`
+	PreviousClosingPrice decimal.Decimal `json:"previousClosingPrice"`
+	Reference            bool            `json:"reference"`
 }
 
 // --- Stock ---
@@ -275,17 +279,17 @@ type StockKeyIndicators struct {
 
 // MonetaryValue is a value with its currency.
 type MonetaryValue struct {
-	Value    float64 `json:"value"`
-	Currency string  `json:"currency"`
+	Value    decimal.Decimal `json:"value"`
+	Currency string          `json:"currency"`
 }
 
 // Dividend describes an upcoming or past dividend payment.
 type Dividend struct {
-	ExDate       string  `json:"exDate"`
-	PaymentDate  string  `json:"paymentDate"`
-	Amount       float64 `json:"amount"`
-	CurrencyCode string  `json:"currencyCode"`
-	ExDateStatus string  `json:"exDateStatus"`
+	ExDate       string          `json:"exDate"`
+	PaymentDate  string          `json:"paymentDate"`
+	Amount       decimal.Decimal `json:"amount"`
+	CurrencyCode string          `json:"currencyCode"`
+	ExDateStatus string          `json:"exDateStatus"`
 }
 
 // Report describes a financial report date.
@@ -408,15 +412,15 @@ type OffHoursPrice struct {
 // are ISO 8601 strings rather than epoch milliseconds, and it carries no spread,
 // traded-volume, or VWAP fields.
 type OffHoursQuote struct {
-	Buy           float64 `json:"buy"`
-	Sell          float64 `json:"sell"`
-	Last          float64 `json:"last"`
-	Highest       float64 `json:"highest"`
-	Lowest        float64 `json:"lowest"`
-	Change        float64 `json:"change"`
-	ChangePercent float64 `json:"changePercent"`
-	Updated       string  `json:"updated"`
-	TimeOfLast    string  `json:"timeOfLast"`
+	Buy           decimal.Decimal `json:"buy"`
+	Sell          decimal.Decimal `json:"sell"`
+	Last          decimal.Decimal `json:"last"`
+	Highest       decimal.Decimal `json:"highest"`
+	Lowest        decimal.Decimal `json:"lowest"`
+	Change        decimal.Decimal `json:"change"`
+	ChangePercent float64         `json:"changePercent"`
+	Updated       string          `json:"updated"`
+	TimeOfLast    string          `json:"timeOfLast"`
 }
 
 // --- Orderbook (trading-critical) ---
@@ -452,9 +456,9 @@ type TickSizeList struct {
 
 // TickSizeEntry defines the tick size for a price range.
 type TickSizeEntry struct {
-	Min  float64 `json:"min"`
-	Max  float64 `json:"max"`
-	Tick float64 `json:"tick"`
+	Min  decimal.Decimal `json:"min"`
+	Max  decimal.Decimal `json:"max"`
+	Tick decimal.Decimal `json:"tick"`
 }
 
 // FeatureSupport describes which trading features are available for an instrument.
@@ -481,18 +485,18 @@ type MarketData struct {
 
 // MarketDataQuote contains real-time price data from the trading-critical endpoint.
 type MarketDataQuote struct {
-	Buy                        float64 `json:"buy"`
-	Sell                       float64 `json:"sell"`
-	Last                       float64 `json:"last"`
-	Highest                    float64 `json:"highest"`
-	Lowest                     float64 `json:"lowest"`
-	Change                     float64 `json:"change"`
-	ChangePercent              float64 `json:"changePercent"`
-	TimeOfLast                 string  `json:"timeOfLast"`
-	TotalValueTraded           float64 `json:"totalValueTraded"`
-	TotalVolumeTraded          int     `json:"totalVolumeTraded"`
-	Updated                    string  `json:"updated"`
-	VolumeWeightedAveragePrice float64 `json:"volumeWeightedAveragePrice"`
+	Buy                        decimal.Decimal `json:"buy"`
+	Sell                       decimal.Decimal `json:"sell"`
+	Last                       decimal.Decimal `json:"last"`
+	Highest                    decimal.Decimal `json:"highest"`
+	Lowest                     decimal.Decimal `json:"lowest"`
+	Change                     decimal.Decimal `json:"change"`
+	ChangePercent              float64         `json:"changePercent"`
+	TimeOfLast                 string          `json:"timeOfLast"`
+	TotalValueTraded           decimal.Decimal `json:"totalValueTraded"`
+	TotalVolumeTraded          int             `json:"totalVolumeTraded"`
+	Updated                    string          `json:"updated"`
+	VolumeWeightedAveragePrice decimal.Decimal `json:"volumeWeightedAveragePrice"`
 }
 
 // MarketDataOrderDepth contains an order book snapshot, as returned by both the
@@ -514,9 +518,9 @@ type MarketDataOrderDepthLevel struct {
 // Volume is float64 because the API formats empty sides as "0.00" — a decimal
 // literal that will not decode into an integer.
 type MarketDataOrderSide struct {
-	Price       float64 `json:"price"`
-	Volume      float64 `json:"volume"`
-	PriceString string  `json:"priceString"`
+	Price       decimal.Decimal `json:"price"`
+	Volume      float64         `json:"volume"`
+	PriceString string          `json:"priceString"`
 }
 
 // --- Price chart ---
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 	"github.com/vmorsell/avanza-sdk-go/market"
 )
 
@@ -114,8 +115,8 @@ func TestOrderDepthSubscription(t *testing.T) {
 			t.Error("Expected at least one price level")
 		} else {
 			level := orderDepthEvent.Data.Levels[0]
-			if level.BuyPrice != 10.00 {
-				t.Errorf("Expected buy price 10.00, got %f", level.BuyPrice)
+			if !level.BuyPrice.Equal(decimal.MustParse("10.00")) {
+				t.Errorf("Expected buy price 10.00, got %s", level.BuyPrice)
 			}
 			if level.BuyVolume != 500 {
 				t.Errorf("Expected buy volume 500, got %f", level.BuyVolume)
@@ -169,14 +170,14 @@ func TestOrderDepthDataUnmarshal(t *testing.T) {
 
 	// Check first level
 	level := data.Levels[0]
-	if level.BuyPrice != 10.00 {
-		t.Errorf("Expected buy price 10.00, got %f", level.BuyPrice)
+	if !level.BuyPrice.Equal(decimal.MustParse("10.00")) {
+		t.Errorf("Expected buy price 10.00, got %s", level.BuyPrice)
 	}
 	if level.BuyVolume != 500 {
 		t.Errorf("Expected buy volume 500, got %f", level.BuyVolume)
 	}
-	if level.SellPrice != 10.15 {
-		t.Errorf("Expected sell price 10.15, got %f", level.SellPrice)
+	if !level.SellPrice.Equal(decimal.MustParse("10.15")) {
+		t.Errorf("Expected sell price 10.15, got %s", level.SellPrice)
 	}
 	if level.SellVolume != 25000 {
 		t.Errorf("Expected sell volume 25000, got %f", level.SellVolume)
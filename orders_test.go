@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 	"github.com/vmorsell/avanza-sdk-go/trading"
 )
 
@@ -43,7 +45,7 @@ func TestPlaceOrder_Success(t *testing.T) {
 			t.Errorf("req.OrderbookID = %v, want %v", got, want)
 		}
 
-		if got, want := req.Price, testPrice; got != want {
+		if got, want := req.Price, decimal.NewFromFloat(testPrice); !got.Equal(want) {
 			t.Errorf("req.Price = %v, want %v", got, want)
 		}
 
@@ -66,14 +68,14 @@ func TestPlaceOrder_Success(t *testing.T) {
 	req := &trading.PlaceOrderRequest{
 		IsDividendReinvestment: false,
 		RequestID:              testRequestID,
-		Price:                  testPrice,
+		Price:                  decimal.NewFromFloat(testPrice),
 		Volume:                 testVolume,
 		AccountID:              testAccountID,
 		Side:                   trading.OrderSideBuy,
 		OrderbookID:            testOrderbookID,
 		Metadata: trading.OrderMetadata{
-			OrderEntryMode:  "ADVANCED",
-			HasTouchedPrice: "true",
+			OrderEntryMode:  trading.OrderEntryModeAdvanced,
+			HasTouchedPrice: true,
 		},
 		Condition: trading.OrderConditionNormal,
 	}
@@ -116,7 +118,7 @@ func TestPlaceOrder_FailedStatus(t *testing.T) {
 
 	req := &trading.PlaceOrderRequest{
 		RequestID:   testRequestID,
-		Price:       testPrice,
+		Price:       decimal.NewFromFloat(testPrice),
 		Volume:      testVolume,
 		AccountID:   testAccountID,
 		Side:        trading.OrderSideBuy,
@@ -157,7 +159,7 @@ func TestPlaceOrder_HTTPError(t *testing.T) {
 
 	req := &trading.PlaceOrderRequest{
 		RequestID:   testRequestID,
-		Price:       testPrice,
+		Price:       decimal.NewFromFloat(testPrice),
 		Volume:      testVolume,
 		AccountID:   testAccountID,
 		Side:        trading.OrderSideBuy,
@@ -203,7 +205,7 @@ func TestPlaceOrder_SellOrder(t *testing.T) {
 
 	req := &trading.PlaceOrderRequest{
 		RequestID:   testRequestID,
-		Price:       testPrice,
+		Price:       decimal.NewFromFloat(testPrice),
 		Volume:      testVolume,
 		AccountID:   testAccountID,
 		Side:        trading.OrderSideSell,
@@ -243,7 +245,7 @@ func TestPlaceOrder_ContextCancellation(t *testing.T) {
 
 	req := &trading.PlaceOrderRequest{
 		RequestID:   testRequestID,
-		Price:       testPrice,
+		Price:       decimal.NewFromFloat(testPrice),
 		Volume:      testVolume,
 		AccountID:   testAccountID,
 		Side:        trading.OrderSideBuy,
@@ -395,7 +397,7 @@ func TestModifyOrder_Success(t *testing.T) {
 			t.Errorf("req.AccountID = %v, want %v", got, want)
 		}
 
-		if got, want := req.Price, testPrice; got != want {
+		if got, want := req.Price, decimal.NewFromFloat(testPrice); !got.Equal(want) {
 			t.Errorf("req.Price = %v, want %v", got, want)
 		}
 
@@ -418,9 +420,9 @@ func TestModifyOrder_Success(t *testing.T) {
 	req := &trading.ModifyOrderRequest{
 		OrderID:    testOrderID,
 		AccountID:  testAccountID,
-		Price:      testPrice,
+		Price:      decimal.NewFromFloat(testPrice),
 		Volume:     testVolume,
-		ValidUntil: "2026-01-29",
+		ValidUntil: &trading.Date{Year: 2026, Month: time.January, Day: 29},
 	}
 
 	resp, err := avanza.Trading.ModifyOrder(context.Background(), req)
@@ -454,7 +456,7 @@ func TestModifyOrder_FailedStatus(t *testing.T) {
 	req := &trading.ModifyOrderRequest{
 		OrderID:   "order456",
 		AccountID: "account123",
-		Price:     100.0,
+		Price:     decimal.MustParse("100.0"),
 		Volume:    10,
 	}
 
@@ -480,7 +482,7 @@ func TestModifyOrder_HTTPError(t *testing.T) {
 	req := &trading.ModifyOrderRequest{
 		OrderID:   "order456",
 		AccountID: "account123",
-		Price:     100.0,
+		Price:     decimal.MustParse("100.0"),
 		Volume:    10,
 	}
 
@@ -0,0 +1,246 @@
+// Package otelavanza instruments the Avanza client with OpenTelemetry.
+//
+// Middleware records a span and metrics for every API request, and
+// StreamObserver records a span per SSE subscription with an event for every
+// connect, disconnect and reconnect:
+//
+//	client := avanza.New(
+//		avanza.WithMiddleware(otelavanza.Middleware()),
+//		avanza.WithStreamObserver(otelavanza.StreamObserver()),
+//	)
+//
+// Spans are children of the span in the request's context. The global
+// TracerProvider and MeterProvider are used unless set with options. No trace
+// headers are sent to Avanza.
+package otelavanza
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vmorsell/avanza-sdk-go/client"
+)
+
+// ScopeName is the instrumentation scope of the spans and metrics.
+const ScopeName = "github.com/vmorsell/avanza-sdk-go/otelavanza"
+
+// Attribute keys set on spans and metrics, in addition to the standard
+// http.request.method, http.response.status_code, url.template and error.type.
+const (
+	AttemptKey       = attribute.Key("avanza.request.attempt")
+	StreamKey        = attribute.Key("avanza.request.stream")
+	RateLimitWaitKey = attribute.Key("avanza.rate_limit.wait")
+	ReconnectWaitKey = attribute.Key("avanza.stream.reconnect.wait")
+)
+
+const (
+	methodKey     = attribute.Key("http.request.method")
+	statusCodeKey = attribute.Key("http.response.status_code")
+	templateKey   = attribute.Key("url.template")
+	errorTypeKey  = attribute.Key("error.type")
+)
+
+// Option configures Middleware and StreamObserver.
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// WithTracerProvider sets the TracerProvider. Defaults to otel.GetTracerProvider().
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the MeterProvider. Defaults to otel.GetMeterProvider().
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+	return cfg
+}
+
+// instruments are the metrics recorded by Middleware and StreamObserver.
+type instruments struct {
+	duration      metric.Float64Histogram
+	rateLimitWait metric.Float64Histogram
+	errors        metric.Int64Counter
+	reconnects    metric.Int64Counter
+}
+
+func newInstruments(mp metric.MeterProvider) *instruments {
+	meter := mp.Meter(ScopeName)
+	fallback := noop.Meter{}
+	inst := &instruments{}
+	var err error
+
+	inst.duration, err = meter.Float64Histogram("avanza.client.request.duration",
+		metric.WithDescription("Duration of Avanza API requests, excluding rate limiter wait."),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+		inst.duration, _ = fallback.Float64Histogram("")
+	}
+
+	inst.rateLimitWait, err = meter.Float64Histogram("avanza.client.rate_limit.wait",
+		metric.WithDescription("Time Avanza API requests waited for the rate limiter."),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+		inst.rateLimitWait, _ = fallback.Float64Histogram("")
+	}
+
+	inst.errors, err = meter.Int64Counter("avanza.client.request.errors",
+		metric.WithDescription("Avanza API requests that failed or got an error status."),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		otel.Handle(err)
+		inst.errors, _ = fallback.Int64Counter("")
+	}
+
+	inst.reconnects, err = meter.Int64Counter("avanza.client.stream.reconnects",
+		metric.WithDescription("SSE subscription reconnect attempts."),
+		metric.WithUnit("{reconnect}"))
+	if err != nil {
+		otel.Handle(err)
+		inst.reconnects, _ = fallback.Int64Counter("")
+	}
+
+	return inst
+}
+
+// Middleware returns client middleware that records a span per request
+// attempt, named after the method and endpoint template, e.g.
+// "GET /_api/market-guide/stock/{id}". The span starts when the request
+// starts waiting for the rate limiter.
+func Middleware(opts ...Option) client.Middleware {
+	cfg := newConfig(opts)
+	tracer := cfg.tracerProvider.Tracer(ScopeName)
+	inst := newInstruments(cfg.meterProvider)
+
+	return func(next client.Handler) client.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			info, ok := client.RequestInfoFromContext(req.Context())
+			if !ok {
+				info = client.RequestInfo{Method: req.Method, Endpoint: req.URL.Path, Attempt: 1}
+			}
+			template := client.EndpointTemplate(info.Endpoint)
+
+			attrs := []attribute.KeyValue{
+				methodKey.String(info.Method),
+				templateKey.String(template),
+			}
+			start := time.Now()
+
+			ctx, span := tracer.Start(req.Context(), info.Method+" "+template,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithTimestamp(start.Add(-info.RateLimitWait)),
+				trace.WithAttributes(attrs...),
+				trace.WithAttributes(
+					AttemptKey.Int(info.Attempt),
+					StreamKey.Bool(info.Stream),
+					RateLimitWaitKey.Float64(info.RateLimitWait.Seconds()),
+				))
+			defer span.End()
+
+			resp, err := next(req.WithContext(ctx))
+
+			ctx = context.WithoutCancel(ctx)
+			inst.rateLimitWait.Record(ctx, info.RateLimitWait.Seconds(), metric.WithAttributes(attrs...))
+
+			var errType string
+			if err != nil {
+				errType = fmt.Sprintf("%T", err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else {
+				attrs = append(attrs, statusCodeKey.Int(resp.StatusCode))
+				span.SetAttributes(statusCodeKey.Int(resp.StatusCode))
+				if resp.StatusCode >= 400 {
+					errType = strconv.Itoa(resp.StatusCode)
+					span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+				}
+			}
+			if errType != "" {
+				attrs = append(attrs, errorTypeKey.String(errType))
+				span.SetAttributes(errorTypeKey.String(errType))
+			}
+
+			set := metric.WithAttributes(attrs...)
+			inst.duration.Record(ctx, time.Since(start).Seconds(), set)
+			if errType != "" {
+				inst.errors.Add(ctx, 1, set)
+			}
+			return resp, err
+		}
+	}
+}
+
+// StreamObserver returns a stream observer that records a span per SSE
+// subscription, from start until it is closed, with span events for each
+// connect, disconnect and reconnect.
+func StreamObserver(opts ...Option) client.StreamObserver {
+	cfg := newConfig(opts)
+	tracer := cfg.tracerProvider.Tracer(ScopeName)
+	inst := newInstruments(cfg.meterProvider)
+
+	return func(ctx context.Context, endpoint string) func(client.StreamEvent) {
+		template := client.EndpointTemplate(endpoint)
+		attrs := metric.WithAttributes(templateKey.String(template))
+
+		ctx, span := tracer.Start(ctx, "SSE "+template,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(templateKey.String(template)))
+		ctx = context.WithoutCancel(ctx)
+
+		return func(ev client.StreamEvent) {
+			var evAttrs []attribute.KeyValue
+			if ev.Err != nil {
+				evAttrs = append(evAttrs, attribute.String("exception.message", ev.Err.Error()))
+			}
+
+			switch ev.Kind {
+			case client.StreamConnect, client.StreamDisconnect:
+				span.AddEvent(string(ev.Kind), trace.WithTimestamp(ev.Time), trace.WithAttributes(evAttrs...))
+			case client.StreamReconnect:
+				evAttrs = append(evAttrs,
+					AttemptKey.Int(ev.Attempt),
+					ReconnectWaitKey.Float64(ev.Wait.Seconds()))
+				span.AddEvent(string(ev.Kind), trace.WithTimestamp(ev.Time), trace.WithAttributes(evAttrs...))
+				inst.reconnects.Add(ctx, 1, attrs)
+			case client.StreamClose:
+				if ev.Err != nil {
+					span.RecordError(ev.Err)
+					span.SetStatus(codes.Error, ev.Err.Error())
+				}
+				span.End(trace.WithTimestamp(ev.Time))
+			}
+		}
+	}
+}
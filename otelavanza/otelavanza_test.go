@@ -0,0 +1,208 @@
+package otelavanza
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/vmorsell/avanza-sdk-go/client"
+)
+
+func newProviders() (*tracetest.SpanRecorder, *sdktrace.TracerProvider, *sdkmetric.ManualReader, *sdkmetric.MeterProvider) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	return recorder, tp, reader, mp
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func findMetric(t *testing.T, reader *sdkmetric.ManualReader, name string) metricdata.Metrics {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	t.Fatalf("metric %s not recorded", name)
+	return metricdata.Metrics{}
+}
+
+func TestMiddleware_RecordsSpanAndMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_api/market-guide/stock/404" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder, tp, reader, mp := newProviders()
+	c := client.NewClient(
+		client.WithBaseURL(server.URL),
+		client.WithRateLimiter(&client.SimpleRateLimiter{Interval: 50 * time.Millisecond}),
+		client.WithMiddleware(Middleware(WithTracerProvider(tp), WithMeterProvider(mp))),
+	)
+
+	for _, id := range []string{"5247", "404"} {
+		resp, err := c.Get(context.Background(), "/_api/market-guide/stock/"+id)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	for _, span := range spans {
+		if span.Name() != "GET /_api/market-guide/stock/{id}" {
+			t.Errorf("span name = %q", span.Name())
+		}
+		if v, _ := spanAttr(span, templateKey); v.AsString() != "/_api/market-guide/stock/{id}" {
+			t.Errorf("url.template = %q", v.AsString())
+		}
+		if _, ok := spanAttr(span, RateLimitWaitKey); !ok {
+			t.Error("missing rate limit wait attribute")
+		}
+	}
+
+	if v, _ := spanAttr(spans[0], statusCodeKey); v.AsInt64() != 200 {
+		t.Errorf("first status = %d, want 200", v.AsInt64())
+	}
+	if spans[0].Status().Code == codes.Error {
+		t.Error("first span should not be an error")
+	}
+
+	// The second request waited for the rate limiter; its span covers the wait.
+	wait, _ := spanAttr(spans[1], RateLimitWaitKey)
+	if wait.AsFloat64() < 0.02 {
+		t.Errorf("rate limit wait = %v, want at least 20ms", wait.AsFloat64())
+	}
+	if d := spans[1].EndTime().Sub(spans[1].StartTime()); d.Seconds() < wait.AsFloat64() {
+		t.Errorf("span duration %v shorter than rate limit wait %v", d, wait.AsFloat64())
+	}
+	if spans[1].Status().Code != codes.Error {
+		t.Error("404 span should be an error")
+	}
+	if v, _ := spanAttr(spans[1], errorTypeKey); v.AsString() != "404" {
+		t.Errorf("error.type = %q, want 404", v.AsString())
+	}
+
+	duration := findMetric(t, reader, "avanza.client.request.duration")
+	hist, ok := duration.Data.(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("duration data is %T", duration.Data)
+	}
+	var count uint64
+	for _, dp := range hist.DataPoints {
+		count += dp.Count
+	}
+	if count != 2 {
+		t.Errorf("duration count = %d, want 2", count)
+	}
+
+	errs := findMetric(t, reader, "avanza.client.request.errors")
+	sum, ok := errs.Data.(metricdata.Sum[int64])
+	if !ok || len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 1 {
+		t.Errorf("errors = %+v, want one data point with value 1", errs.Data)
+	}
+}
+
+func TestMiddleware_TransportError(t *testing.T) {
+	recorder, tp, _, mp := newProviders()
+	failing := func(next client.Handler) client.Handler {
+		return func(*http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}
+	}
+	c := client.NewClient(
+		client.WithBaseURL("http://unused"),
+		client.WithRateLimiter(nil),
+		client.WithMiddleware(Middleware(WithTracerProvider(tp), WithMeterProvider(mp)), failing),
+	)
+
+	if _, err := c.Get(context.Background(), "/_api/trading/rest/orders"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Error("span should be an error")
+	}
+	if _, ok := spanAttr(spans[0], errorTypeKey); !ok {
+		t.Error("missing error.type")
+	}
+}
+
+func TestStreamObserver_RecordsSpanEvents(t *testing.T) {
+	recorder, tp, reader, mp := newProviders()
+	observe := StreamObserver(WithTracerProvider(tp), WithMeterProvider(mp))(context.Background(), "/_push/order-depth-web-push/5247")
+
+	observe(client.StreamEvent{Kind: client.StreamConnect, Time: time.Now()})
+	observe(client.StreamEvent{Kind: client.StreamDisconnect, Err: errors.New("stream error"), Time: time.Now()})
+	observe(client.StreamEvent{Kind: client.StreamReconnect, Attempt: 1, Wait: time.Second, Time: time.Now()})
+	observe(client.StreamEvent{Kind: client.StreamConnect, Time: time.Now()})
+
+	if len(recorder.Ended()) != 0 {
+		t.Fatal("span ended before close")
+	}
+	observe(client.StreamEvent{Kind: client.StreamClose, Time: time.Now()})
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Name() != "SSE /_push/order-depth-web-push/{id}" {
+		t.Errorf("span name = %q", spans[0].Name())
+	}
+	var names []string
+	for _, ev := range spans[0].Events() {
+		names = append(names, ev.Name)
+	}
+	want := []string{"connect", "disconnect", "reconnect", "connect"}
+	if len(names) != len(want) {
+		t.Fatalf("events = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("events = %v, want %v", names, want)
+			break
+		}
+	}
+
+	reconnects := findMetric(t, reader, "avanza.client.stream.reconnects")
+	sum, ok := reconnects.Data.(metricdata.Sum[int64])
+	if !ok || len(sum.DataPoints) != 1 || sum.DataPoints[0].Value != 1 {
+		t.Errorf("reconnects = %+v, want one data point with value 1", reconnects.Data)
+	}
+}
@@ -0,0 +1,342 @@
+// Package schema detects drift between live JSON API responses and the Go
+// structs that model them. It reports JSON keys present in a response that have
+// no matching struct field, struct fields the response lacks, values whose JSON
+// type the Go field cannot hold (e.g. a number that turned into a string), and
+// nulls in fields that cannot be nil. New, renamed and retyped upstream fields
+// are then discovered without hand-diffing payloads.
+//
+//	issues, err := schema.Check(raw, &trading.Order{})
+//	for _, issue := range issues {
+//		log.Println(issue) // e.g. "mismatch orderbook.volume: want number, got string"
+//	}
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	jsonNumberType      = reflect.TypeFor[json.Number]()
+	decimalType         = reflect.TypeFor[decimal.Decimal]()
+)
+
+// Kind is the kind of difference an Issue reports.
+type Kind string
+
+const (
+	// Unknown is a JSON key with no matching struct field.
+	Unknown Kind = "unknown"
+
+	// Missing is a struct field whose key is absent from a JSON object.
+	// Fields tagged omitempty or omitzero are optional and never missing.
+	Missing Kind = "missing"
+
+	// Mismatch is a JSON value of a type the Go field cannot decode,
+	// e.g. a string in a float64 field.
+	Mismatch Kind = "mismatch"
+
+	// Null is a JSON null in a field that cannot be nil, which decodes
+	// silently to the zero value.
+	Null Kind = "null"
+)
+
+// Issue is one difference between a JSON document and its Go type.
+type Issue struct {
+	Kind Kind
+
+	// Path is the dotted path of the value, with "[]" for array elements,
+	// e.g. "orders[].orderbook.name".
+	Path string
+
+	// Want is the JSON type the Go field decodes from, for Mismatch and Null.
+	Want string
+
+	// Got is the JSON type of the value, for Mismatch.
+	Got string
+}
+
+// String describes the issue, e.g. "mismatch volume: want number, got string".
+func (i Issue) String() string {
+	switch i.Kind {
+	case Mismatch:
+		return fmt.Sprintf("%s %s: want %s, got %s", i.Kind, i.Path, i.Want, i.Got)
+	case Null:
+		return fmt.Sprintf("%s %s: want %s", i.Kind, i.Path, i.Want)
+	default:
+		return fmt.Sprintf("%s %s", i.Kind, i.Path)
+	}
+}
+
+// Check returns every difference between raw and target's type, sorted by
+// path. target must be a pointer to the struct the response is expected to
+// decode into; it is not modified.
+//
+// Array elements are checked one by one and their issues deduplicated, so a
+// field missing from several elements is reported once. Fields whose type
+// decodes itself via a custom json.Unmarshaler (e.g. json.RawMessage) are
+// opaque: their contents are never walked. decimal.Decimal is the exception:
+// it is checked as a number, which may also be sent as a numeric string. Keys
+// are matched case-insensitively, as encoding/json does.
+func Check(raw []byte, target any) ([]Issue, error) {
+	var data any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("unmarshal raw: %w", err)
+	}
+	t := reflect.TypeOf(target)
+	if t == nil {
+		return nil, fmt.Errorf("target must be a non-nil pointer to a struct")
+	}
+
+	set := map[Issue]struct{}{}
+	walk(data, t, "", false, set)
+
+	out := make([]Issue, 0, len(set))
+	for issue := range set {
+		out = append(out, issue)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Kind < out[j].Kind
+	})
+	return out, nil
+}
+
+// UnknownFields returns the dotted paths of every JSON key in raw that has no
+// matching field in target's type: the Unknown issues of Check.
+func UnknownFields(raw []byte, target any) ([]string, error) {
+	issues, err := Check(raw, target)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, issue := range issues {
+		if issue.Kind == Unknown {
+			out = append(out, issue.Path)
+		}
+	}
+	return out, nil
+}
+
+// walk records the issues of data decoded into a value of type t. quoted is
+// set for fields tagged ",string", whose scalars are encoded as strings.
+func walk(data any, t reflect.Type, path string, quoted bool, set map[Issue]struct{}) {
+	if isOpaque(t) {
+		return // decodes itself; its shape isn't modelled by struct fields
+	}
+	if data == nil {
+		if !nillable(t) {
+			set[Issue{Kind: Null, Path: path, Want: jsonType(t, quoted)}] = struct{}{}
+		}
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if isOpaque(t) {
+			return
+		}
+	}
+
+	if want, got := jsonType(t, quoted), valueType(data); !accepts(want, got, t) || !numeric(data, t) {
+		set[Issue{Kind: Mismatch, Path: path, Want: want, Got: got}] = struct{}{}
+		return
+	}
+
+	switch d := data.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			keys := make(map[string]string, len(d))
+			for key := range d {
+				keys[strings.ToLower(key)] = key
+			}
+			for name, f := range structFields(t) {
+				key, ok := keys[name]
+				if !ok {
+					if !optional(f) {
+						set[Issue{Kind: Missing, Path: join(path, jsonName(f))}] = struct{}{}
+					}
+					continue
+				}
+				delete(keys, name)
+				walk(d[key], f.Type, join(path, key), quotedField(f), set)
+			}
+			for _, key := range keys {
+				set[Issue{Kind: Unknown, Path: join(path, key)}] = struct{}{}
+			}
+		case reflect.Map:
+			// Keys are dynamic and therefore all "known"; recurse into values.
+			for key, val := range d {
+				walk(val, t.Elem(), join(path, key), false, set)
+			}
+		}
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for _, elem := range d {
+				walk(elem, t.Elem(), path+"[]", false, set)
+			}
+		}
+	}
+}
+
+// jsonType is the JSON type a value of type t decodes from: "object",
+// "array", "string", "number", "boolean", or "any".
+func jsonType(t reflect.Type, quoted bool) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == jsonNumberType || t == decimalType {
+		return "number"
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string" // base64
+		}
+		return "array"
+	case reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		if quoted {
+			return "string"
+		}
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if quoted {
+			return "string"
+		}
+		return "number"
+	default:
+		return "any"
+	}
+}
+
+// valueType is the JSON type of a value decoded into any.
+func valueType(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+// accepts reports whether a field of type t, expecting want, decodes a got.
+// json.Number and decimal.Decimal also take numbers quoted as strings.
+func accepts(want, got string, t reflect.Type) bool {
+	return want == "any" || want == got || ((t == jsonNumberType || t == decimalType) && got == "string")
+}
+
+// numeric reports whether a string decoded into a decimal.Decimal holds a
+// number, or is empty, which decodes as 0. Other values are left to accepts.
+func numeric(data any, t reflect.Type) bool {
+	s, ok := data.(string)
+	if !ok || t != decimalType || s == "" {
+		return true
+	}
+	_, err := decimal.Parse(s)
+	return err == nil
+}
+
+// nillable reports whether a null decodes into t as a nil value rather than
+// being dropped.
+func nillable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// structFields maps the lowercased JSON name of every exported, serialized
+// field to its StructField. Lowercasing mirrors encoding/json's case-insensitive
+// key matching so casing differences aren't mistaken for drift.
+func structFields(t reflect.Type) map[string]reflect.StructField {
+	m := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		name := jsonName(f)
+		if name == "-" {
+			continue // explicitly not serialized
+		}
+		m[strings.ToLower(name)] = f
+	}
+	return m
+}
+
+// isOpaque reports whether a field decodes itself via a custom json.Unmarshaler
+// (json.RawMessage, time.Time, and the like). Such a field's contents are not
+// modelled by reflectable struct fields, so the walker must not descend into it.
+// The pointer check mirrors encoding/json, which uses the addressable (pointer)
+// value — json.RawMessage's UnmarshalJSON has a pointer receiver. A
+// decimal.Decimal, or a pointer to one, is not opaque: it is checked as a
+// number.
+func isOpaque(t reflect.Type) bool {
+	if t == decimalType || (t.Kind() == reflect.Pointer && t.Elem() == decimalType) {
+		return false
+	}
+	return t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType)
+}
+
+func jsonName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	if tag == "" {
+		return f.Name
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return f.Name
+}
+
+// optional reports whether f is tagged omitempty or omitzero.
+func optional(f reflect.StructField) bool {
+	return hasTagOption(f, "omitempty") || hasTagOption(f, "omitzero")
+}
+
+// quotedField reports whether f is tagged ",string".
+func quotedField(f reflect.StructField) bool {
+	return hasTagOption(f, "string")
+}
+
+func hasTagOption(f reflect.StructField, option string) bool {
+	opts := strings.Split(f.Tag.Get("json"), ",")
+	for _, o := range opts[1:] {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,285 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+)
+
+type inner struct {
+	Known string `json:"known"`
+}
+
+type sample struct {
+	Name     string          `json:"name"`
+	Nested   inner           `json:"nested"`
+	Ptr      *inner          `json:"ptr"`
+	List     []inner         `json:"list"`
+	Raw      json.RawMessage `json:"raw"`
+	Ignored  string          `json:"-"`
+	unexp    string          //nolint:unused // exercises unexported skip
+	Untagged string
+}
+
+func TestUnknownFields(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{
+			name: "all known",
+			raw:  `{"name":"x","nested":{"known":"y"},"untagged":"z"}`,
+			want: nil,
+		},
+		{
+			name: "top-level unknown",
+			raw:  `{"name":"x","surprise":1}`,
+			want: []string{"surprise"},
+		},
+		{
+			name: "nested unknown",
+			raw:  `{"nested":{"known":"y","extra":1}}`,
+			want: []string{"nested.extra"},
+		},
+		{
+			name: "unknown through pointer field",
+			raw:  `{"ptr":{"known":"y","extra":1}}`,
+			want: []string{"ptr.extra"},
+		},
+		{
+			name: "unknown inside array element, deduped",
+			raw:  `{"list":[{"known":"a","extra":1},{"known":"b","extra":2}]}`,
+			want: []string{"list[].extra"},
+		},
+		{
+			name: "raw message contents are opaque",
+			raw:  `{"raw":{"anything":{"deeply":"nested"}}}`,
+			want: nil,
+		},
+		{
+			name: "json:\"-\" field cannot be matched by its go name",
+			raw:  `{"ignored":"present"}`,
+			want: []string{"ignored"},
+		},
+		{
+			name: "untagged field matched case-insensitively",
+			raw:  `{"UNTAGGED":"z"}`,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnknownFields([]byte(tt.raw), &sample{})
+			if err != nil {
+				t.Fatalf("UnknownFields: %v", err)
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnknownFields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownFieldsInvalidJSON(t *testing.T) {
+	if _, err := UnknownFields([]byte(`{not json`), &sample{}); err == nil {
+		t.Fatal("expected error for invalid JSON, got nil")
+	}
+}
+
+type typed struct {
+	Name    string      `json:"name"`
+	Volume  float64     `json:"volume"`
+	Count   int         `json:"count,string"`
+	Active  bool        `json:"active"`
+	Nested  inner       `json:"nested"`
+	Ptr     *inner      `json:"ptr"`
+	List    []inner     `json:"list"`
+	Number  json.Number `json:"number"`
+	Any     any         `json:"any"`
+	Note    string      `json:"note,omitempty"`
+	Dynamic map[string]float64
+}
+
+func TestCheck(t *testing.T) {
+	const full = `"name":"x","volume":1.5,"count":"3","active":true,"nested":{"known":"y"},"ptr":null,"list":[],"number":"2.5","any":{"a":1},"dynamic":{}`
+
+	tests := []struct {
+		name string
+		raw  string
+		want []Issue
+	}{
+		{
+			name: "matches",
+			raw:  `{` + full + `}`,
+			want: []Issue{},
+		},
+		{
+			name: "missing fields, optional ones excepted",
+			raw:  `{"name":"x","nested":{}}`,
+			want: []Issue{
+				{Kind: Missing, Path: "Dynamic"},
+				{Kind: Missing, Path: "active"},
+				{Kind: Missing, Path: "any"},
+				{Kind: Missing, Path: "count"},
+				{Kind: Missing, Path: "list"},
+				{Kind: Missing, Path: "nested.known"},
+				{Kind: Missing, Path: "number"},
+				{Kind: Missing, Path: "ptr"},
+				{Kind: Missing, Path: "volume"},
+			},
+		},
+		{
+			name: "number turned into a string",
+			raw:  `{` + full + `,"volume":"0.00"}`,
+			want: []Issue{{Kind: Mismatch, Path: "volume", Want: "number", Got: "string"}},
+		},
+		{
+			name: "scalar turned into an object",
+			raw:  `{` + full + `,"name":{"first":"x"}}`,
+			want: []Issue{{Kind: Mismatch, Path: "name", Want: "string", Got: "object"}},
+		},
+		{
+			name: "object turned into an array",
+			raw:  `{` + full + `,"nested":[]}`,
+			want: []Issue{{Kind: Mismatch, Path: "nested", Want: "object", Got: "array"}},
+		},
+		{
+			name: "quoted field holding a bare number",
+			raw:  `{` + full + `,"count":3}`,
+			want: []Issue{{Kind: Mismatch, Path: "count", Want: "string", Got: "number"}},
+		},
+		{
+			name: "mismatch inside array elements, deduped",
+			raw:  `{` + full + `,"list":[{"known":1},{"known":2},{"known":"ok"}]}`,
+			want: []Issue{{Kind: Mismatch, Path: "list[].known", Want: "string", Got: "number"}},
+		},
+		{
+			name: "mismatch in map values",
+			raw:  `{` + full + `,"dynamic":{"a":"1"}}`,
+			want: []Issue{{Kind: Mismatch, Path: "dynamic.a", Want: "number", Got: "string"}},
+		},
+		{
+			name: "null in non-pointer fields",
+			raw:  `{` + full + `,"volume":null,"nested":null,"active":null}`,
+			want: []Issue{
+				{Kind: Null, Path: "active", Want: "boolean"},
+				{Kind: Null, Path: "nested", Want: "object"},
+				{Kind: Null, Path: "volume", Want: "number"},
+			},
+		},
+		{
+			name: "null in pointer, slice, map and interface fields",
+			raw:  `{` + full + `,"ptr":null,"list":null,"dynamic":null,"any":null}`,
+			want: []Issue{},
+		},
+		{
+			name: "all kinds together",
+			raw:  `{"name":"x","volume":"1","count":"3","active":true,"nested":null,"ptr":{"known":"y","extra":1},"list":[],"number":1,"any":null,"dynamic":{}}`,
+			want: []Issue{
+				{Kind: Null, Path: "nested", Want: "object"},
+				{Kind: Unknown, Path: "ptr.extra"},
+				{Kind: Mismatch, Path: "volume", Want: "number", Got: "string"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Check([]byte(tt.raw), &typed{})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check =\n  %v\nwant\n  %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueString(t *testing.T) {
+	tests := []struct {
+		issue Issue
+		want  string
+	}{
+		{Issue{Kind: Unknown, Path: "a.b"}, "unknown a.b"},
+		{Issue{Kind: Missing, Path: "a"}, "missing a"},
+		{Issue{Kind: Mismatch, Path: "volume", Want: "number", Got: "string"}, "mismatch volume: want number, got string"},
+		{Issue{Kind: Null, Path: "volume", Want: "number"}, "null volume: want number"},
+	}
+	for _, tt := range tests {
+		if got := tt.issue.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+type priced struct {
+	Price decimal.Decimal  `json:"price"`
+	Fee   *decimal.Decimal `json:"fee"`
+}
+
+func TestCheckDecimal(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []Issue
+	}{
+		{
+			name: "numbers",
+			raw:  `{"price":245.50,"fee":1}`,
+			want: []Issue{},
+		},
+		{
+			name: "numeric and empty strings",
+			raw:  `{"price":"245.50","fee":""}`,
+			want: []Issue{},
+		},
+		{
+			name: "null in pointer field",
+			raw:  `{"price":1,"fee":null}`,
+			want: []Issue{},
+		},
+		{
+			name: "null price",
+			raw:  `{"price":null,"fee":1}`,
+			want: []Issue{{Kind: Null, Path: "price", Want: "number"}},
+		},
+		{
+			name: "object and array",
+			raw:  `{"price":{"v":1},"fee":[1]}`,
+			want: []Issue{
+				{Kind: Mismatch, Path: "fee", Want: "number", Got: "array"},
+				{Kind: Mismatch, Path: "price", Want: "number", Got: "object"},
+			},
+		},
+		{
+			name: "boolean",
+			raw:  `{"price":true,"fee":1}`,
+			want: []Issue{{Kind: Mismatch, Path: "price", Want: "number", Got: "boolean"}},
+		},
+		{
+			name: "non-numeric string",
+			raw:  `{"price":"n/a","fee":1}`,
+			want: []Issue{{Kind: Mismatch, Path: "price", Want: "number", Got: "string"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Check([]byte(tt.raw), &priced{})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check =\n  %v\nwant\n  %v", got, tt.want)
+			}
+		})
+	}
+}
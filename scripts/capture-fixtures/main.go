@@ -0,0 +1,320 @@
+// Command capture-fixtures records authenticated Avanza responses as the
+// schema drift fixtures of the trading and accounts packages.
+//
+//	go run ./scripts/capture-fixtures -listen 2m
+//
+// It logs in with BankID, or resumes a session saved with -session, and reads
+// accounts, positions, transactions, orders, stop losses, order validation
+// and fees. With -listen it also follows the order and stop loss streams, so
+// that placing or cancelling an order in the app during that time captures
+// their events. It never places, modifies or deletes anything.
+//
+// Fixtures are redacted before they are written: the cassette recorder masks
+// tokens, cookies and personal identity numbers, and account numbers and URL
+// parameter IDs are replaced with placeholders. Review the diff before
+// committing, as names and amounts are kept.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go"
+	"github.com/vmorsell/avanza-sdk-go/accounts"
+	"github.com/vmorsell/avanza-sdk-go/auth"
+	"github.com/vmorsell/avanza-sdk-go/cassette"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/trading"
+)
+
+// fixture is where the response to an endpoint is written.
+type fixture struct {
+	method string
+	path   string // exact, or a prefix when it ends with "/"
+	file   string // relative to the repository root
+}
+
+// fixtures is checked in order, so exact paths go before prefixes.
+var fixtures = []fixture{
+	{"GET", "/_api/account-overview/overview/categorizedAccounts", "accounts/testdata/overview.json"},
+	{"GET", "/_api/trading-critical/rest/accounts", "accounts/testdata/trading_accounts.json"},
+	{"GET", "/_api/position-data/positions/", "accounts/testdata/positions.json"},
+	{"GET", "/_api/transactions/list", "accounts/testdata/transactions.json"},
+	{"POST", "/_api/account-performance/aggregatedAccountsValues", "accounts/testdata/aggregated_values.json"},
+
+	{"GET", "/_api/trading/rest/orders", "trading/testdata/orders.json"},
+	{"GET", "/_api/trading-critical/rest/order/find", "trading/testdata/order.json"},
+	{"POST", "/_api/trading-critical/rest/order/validation/validate", "trading/testdata/validate_order.json"},
+	{"POST", "/_api/trading/preliminary-fee/preliminaryfee", "trading/testdata/preliminary_fee.json"},
+	{"GET", "/_api/trading/stoploss", "trading/testdata/stoploss_orders.json"},
+	{"GET", "/_api/trading/stoploss/", "trading/testdata/stoploss_order.json"},
+}
+
+func main() {
+	out := flag.String("out", ".", "repository root to write fixtures under")
+	sessionPath := flag.String("session", "", "file to resume and save the session in, encrypted with $AVANZA_SESSION_PASSPHRASE")
+	listen := flag.Duration("listen", 0, "how long to record the order and stop loss streams")
+	days := flag.Int("days", 30, "days of transactions to fetch")
+	flag.Parse()
+
+	rec := cassette.NewRecorder(nil)
+	opts := []avanza.Option{avanza.WithHTTPClient(&http.Client{Transport: rec, Timeout: 30 * time.Second})}
+	if *sessionPath != "" {
+		store := auth.NewFileSessionStore(*sessionPath, os.Getenv("AVANZA_SESSION_PASSPHRASE"))
+		opts = append(opts, avanza.WithSessionStore(store))
+	}
+	c := avanza.New(opts...)
+	ctx := context.Background()
+
+	if err := login(ctx, c, *sessionPath != ""); err != nil {
+		log.Fatalf("Login failed: %v", err)
+	}
+
+	accts, err := c.Accounts.GetTradingAccounts(ctx)
+	if err != nil {
+		log.Fatalf("Failed to get trading accounts: %v", err)
+	}
+	if len(accts) == 0 {
+		log.Fatal("No trading accounts found")
+	}
+	fetch(ctx, c, accts[0], *days)
+
+	if *listen > 0 {
+		record(ctx, c, *listen)
+	}
+
+	scrub := newScrubber(accts)
+	written := 0
+	for _, in := range rec.Cassette().Interactions {
+		n, err := writeFixtures(*out, in, scrub)
+		if err != nil {
+			log.Fatalf("Failed to write fixture: %v", err)
+		}
+		written += n
+	}
+	fmt.Printf("Wrote %d fixtures. Review them before committing.\n", written)
+}
+
+// login resumes the stored session if there is one, and runs the BankID
+// flow otherwise.
+func login(ctx context.Context, c *avanza.Avanza, stored bool) error {
+	if stored {
+		if _, err := c.Auth.RestoreSession(ctx); err == nil {
+			return nil
+		}
+	}
+
+	start, err := c.Auth.StartBankID(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.Auth.DisplayQRCode(start.QRToken); err != nil {
+		return err
+	}
+	collect, err := c.Auth.PollBankIDWithQRUpdates(ctx)
+	if err != nil {
+		return err
+	}
+	return c.Auth.EstablishSession(ctx, collect)
+}
+
+// fetch calls every read-only endpoint with a fixture. Failures are logged
+// and skipped, since an account without stop losses has nothing to fetch.
+func fetch(ctx context.Context, c *avanza.Avanza, account accounts.TradingAccount, days int) {
+	warn := func(what string, err error) {
+		if err != nil {
+			log.Printf("Skipping %s: %v", what, err)
+		}
+	}
+
+	_, err := c.Accounts.GetOverview(ctx)
+	warn("overview", err)
+	_, err = c.Accounts.GetPositions(ctx, account.URLParameterID)
+	warn("positions", err)
+
+	today := time.Now()
+	_, err = c.Accounts.GetTransactions(ctx, &accounts.TransactionsRequest{
+		From: today.AddDate(0, 0, -days).Format(time.DateOnly),
+		To:   today.Format(time.DateOnly),
+	})
+	warn("transactions", err)
+	_, err = c.Accounts.GetAggregatedValues(ctx, &accounts.AggregatedValuesRequest{
+		EncryptedAccountIDs: []string{account.URLParameterID},
+		Dates:               []string{today.AddDate(0, 0, -days).Format(time.DateOnly), today.Format(time.DateOnly)},
+	})
+	warn("aggregated values", err)
+
+	orders, err := c.Trading.GetOrders(ctx)
+	warn("orders", err)
+	if err == nil && len(orders.Orders) > 0 {
+		o := orders.Orders[0]
+		_, err = c.Trading.GetOrder(ctx, &trading.GetOrderRequest{OrderID: o.OrderID, AccountID: o.Account.AccountID})
+		warn("order", err)
+	}
+
+	stopLosses, err := c.Trading.GetStopLossOrders(ctx)
+	warn("stop losses", err)
+	if err == nil && len(stopLosses) > 0 {
+		sl := stopLosses[0]
+		_, err = c.Trading.GetStopLoss(ctx, &trading.GetStopLossRequest{
+			AccountURLParameterID: sl.Account.URLParameterID,
+			StopLossOrderID:       sl.ID,
+		})
+		warn("stop loss", err)
+	}
+
+	// Validation and fees for buying one Investor B; nothing is placed.
+	_, err = c.Trading.ValidateOrder(ctx, &trading.ValidateOrderRequest{
+		Price:       decimal.NewFromInt(350),
+		Volume:      1,
+		AccountID:   account.AccountID,
+		Side:        trading.OrderSideBuy,
+		OrderbookID: "5247",
+		Condition:   trading.OrderConditionNormal,
+		ISIN:        "SE0015811963",
+		Currency:    "SEK",
+		MarketPlace: "XSTO",
+	})
+	warn("order validation", err)
+	_, err = c.Trading.GetPreliminaryFee(ctx, &trading.PreliminaryFeeRequest{
+		AccountID:   account.AccountID,
+		OrderbookID: "5247",
+		Price:       "350.0",
+		Volume:      "1",
+		Side:        trading.OrderSideBuy,
+	})
+	warn("preliminary fee", err)
+}
+
+// record follows the order and stop loss streams for d.
+func record(ctx context.Context, c *avanza.Avanza, d time.Duration) {
+	orders, err := c.Trading.SubscribeToOrders(ctx)
+	if err != nil {
+		log.Printf("Skipping the order stream: %v", err)
+	} else {
+		defer orders.Close()
+	}
+	stopLosses, err := c.Trading.SubscribeToStopLoss(ctx)
+	if err != nil {
+		log.Printf("Skipping the stop loss stream: %v", err)
+	} else {
+		defer stopLosses.Close()
+	}
+
+	fmt.Printf("Recording streams for %v. Place, change or cancel an order or stop loss in the app now.\n", d)
+	time.Sleep(d)
+}
+
+// writeFixtures writes the fixtures in an interaction and returns how many.
+func writeFixtures(root string, in cassette.Interaction, scrub func(string) string) (int, error) {
+	if in.Response.Status != http.StatusOK {
+		return 0, nil
+	}
+	u, err := url.Parse(in.Request.URL)
+	if err != nil {
+		return 0, err
+	}
+
+	if in.Response.Events != nil {
+		return writeEvents(root, in.Response.Events, scrub)
+	}
+	for _, f := range fixtures {
+		if f.method == in.Request.Method && matches(f.path, u.Path) {
+			return 1, writeJSON(filepath.Join(root, f.file), scrub(in.Response.Body))
+		}
+	}
+	return 0, nil
+}
+
+// writeEvents writes the data of the last event per event type and action,
+// e.g. trading/testdata/order_event_new.json.
+func writeEvents(root string, events []string, scrub func(string) string) (int, error) {
+	latest := map[string]string{}
+	for _, ev := range events {
+		var name, data string
+		for _, line := range strings.Split(ev, "\n") {
+			if v, ok := strings.CutPrefix(line, "event:"); ok {
+				name = strings.TrimSpace(v)
+			}
+			if v, ok := strings.CutPrefix(line, "data:"); ok {
+				data = strings.TrimSpace(v)
+			}
+		}
+
+		var action struct {
+			Action     string `json:"action"`
+			PushAction string `json:"pushAction"`
+		}
+		if json.Unmarshal([]byte(data), &action) != nil {
+			continue
+		}
+		switch name {
+		case "ORDER":
+			latest["trading/testdata/order_event_"+strings.ToLower(action.Action)+".json"] = data
+		case "STOPLOSS":
+			latest["trading/testdata/stoploss_event_"+strings.ToLower(action.PushAction)+".json"] = data
+		}
+	}
+
+	for file, data := range latest {
+		if err := writeJSON(filepath.Join(root, file), scrub(data)); err != nil {
+			return 0, err
+		}
+	}
+	return len(latest), nil
+}
+
+func matches(pattern, path string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(path, pattern) && len(path) > len(pattern)
+	}
+	return path == pattern || path == pattern+"/"
+}
+
+func writeJSON(path, body string) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(body), "", "  "); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	buf.WriteByte('\n')
+	fmt.Println("  " + path)
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// newScrubber returns a function replacing the account numbers and URL
+// parameter IDs of accts with placeholders: 1111111, 2222222 and so on, and
+// the base64 of "test-account-" and the placeholder.
+func newScrubber(accts []accounts.TradingAccount) func(string) string {
+	type replacement struct {
+		re   *regexp.Regexp
+		with string
+	}
+	var reps []replacement
+	for i, a := range accts {
+		fake := strings.Repeat(strconv.Itoa(i%9+1), 7)
+		reps = append(reps,
+			// Not inside longer numbers, but in IDs like "<account>_5247".
+			replacement{regexp.MustCompile(`(^|\D)` + regexp.QuoteMeta(a.AccountID) + `(\D|$)`), "${1}" + fake + "${2}"},
+			replacement{regexp.MustCompile(regexp.QuoteMeta(a.URLParameterID)), base64.RawURLEncoding.EncodeToString([]byte("test-account-" + fake))},
+		)
+	}
+	return func(s string) string {
+		for _, r := range reps {
+			s = r.re.ReplaceAllString(s, r.with)
+		}
+		return s
+	}
+}
@@ -3,6 +3,7 @@ package avanza
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -152,8 +153,12 @@ func TestPlaceStopLoss_FailedStatus(t *testing.T) {
 	}
 
 	resp, err := avanza.Trading.PlaceStopLoss(context.Background(), req)
-	if err == nil {
-		t.Fatal("expected error, got nil")
+	var rejected *trading.OrderRejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("expected *trading.OrderRejectedError, got %v", err)
+	}
+	if got, want := rejected.Status, string(trading.StopLossStatusError); got != want {
+		t.Errorf("rejected.Status = %v, want %v", got, want)
 	}
 
 	if got, want := resp.Status, trading.StopLossStatusError; got != want {
@@ -0,0 +1,156 @@
+package trading
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/market"
+)
+
+// OrderBuilder builds a PlaceOrderRequest for a limit order. Create one with
+// NewLimitOrder, chain the setters and call Build, which checks the order
+// against the orderbook before anything is sent.
+//
+//	req, err := trading.NewLimitOrder(accountID, ob).
+//	    Buy(10).
+//	    At(decimal.MustParse("245.50")).
+//	    ValidUntil(trading.NewDate(time.Now().AddDate(0, 0, 7))).
+//	    Build()
+type OrderBuilder struct {
+	orderbook *market.Orderbook
+	req       PlaceOrderRequest
+}
+
+// NewLimitOrder starts a normal, day-long limit order on orderbook for
+// accountID. The orderbook is typically from Market.GetOrderbook.
+func NewLimitOrder(accountID string, orderbook *market.Orderbook) *OrderBuilder {
+	b := &OrderBuilder{
+		orderbook: orderbook,
+		req: PlaceOrderRequest{
+			AccountID: accountID,
+			Condition: OrderConditionNormal,
+			Metadata: OrderMetadata{
+				OrderEntryMode:  OrderEntryModeAdvanced,
+				HasTouchedPrice: true,
+			},
+		},
+	}
+	if orderbook != nil {
+		b.req.OrderbookID = orderbook.ID
+	}
+	return b
+}
+
+// Buy makes the order buy volume shares.
+func (b *OrderBuilder) Buy(volume int) *OrderBuilder {
+	b.req.Side = OrderSideBuy
+	b.req.Volume = volume
+	return b
+}
+
+// Sell makes the order sell volume shares.
+func (b *OrderBuilder) Sell(volume int) *OrderBuilder {
+	b.req.Side = OrderSideSell
+	b.req.Volume = volume
+	return b
+}
+
+// At sets the limit price.
+func (b *OrderBuilder) At(price decimal.Decimal) *OrderBuilder {
+	b.req.Price = price
+	return b
+}
+
+// ValidUntil keeps the order open through date instead of only today. The
+// date must be within the orderbook's MinValidUntil and MaxValidUntil.
+func (b *OrderBuilder) ValidUntil(date Date) *OrderBuilder {
+	b.req.ValidUntil = &date
+	return b
+}
+
+// FillOrKill makes the order fill completely at once or be cancelled. The
+// orderbook must support it.
+func (b *OrderBuilder) FillOrKill() *OrderBuilder {
+	b.req.Condition = OrderConditionFillOrKill
+	return b
+}
+
+// OpenVolume shows only volume shares of the order in the order depth at a
+// time. The orderbook must support it.
+func (b *OrderBuilder) OpenVolume(volume int) *OrderBuilder {
+	b.req.OpenVolume = &volume
+	return b
+}
+
+// Build checks the order and returns it with a new request ID. It fails if
+// the side, volume or price is missing, the price is off tick (as
+// *TickSizeError), the valid-until date is out of range, or the orderbook
+// does not support fill-or-kill or open volume when used. Each call returns a
+// new request with its own request ID.
+func (b *OrderBuilder) Build() (*PlaceOrderRequest, error) {
+	ob := b.orderbook
+	if ob == nil {
+		return nil, fmt.Errorf("build order: orderbook is required")
+	}
+	req := b.req
+	if req.Side == "" {
+		return nil, fmt.Errorf("build order: side is required, call Buy or Sell")
+	}
+	if err := validatePlaceOrder(&req); err != nil {
+		return nil, fmt.Errorf("build order: %w", err)
+	}
+	if err := tickSizeError(ob.ID, ob.TickSizeList, req.Price); err != nil {
+		return nil, err
+	}
+	if req.Condition == OrderConditionFillOrKill && !ob.FeatureSupport.FillAndOrKill {
+		return nil, fmt.Errorf("build order: orderbook %s does not support fill-or-kill", ob.ID)
+	}
+	if req.OpenVolume != nil {
+		if !ob.FeatureSupport.OpenVolume {
+			return nil, fmt.Errorf("build order: orderbook %s does not support open volume", ob.ID)
+		}
+		if *req.OpenVolume <= 0 || *req.OpenVolume > req.Volume {
+			return nil, fmt.Errorf("build order: open volume must be between 1 and the volume %d", req.Volume)
+		}
+		v := *req.OpenVolume
+		req.OpenVolume = &v
+	}
+	if req.ValidUntil != nil {
+		if req.ValidUntil.IsZero() {
+			return nil, fmt.Errorf("build order: valid until date is required, or leave it out for a day order")
+		}
+		if err := checkValidUntil(*req.ValidUntil, ob); err != nil {
+			return nil, fmt.Errorf("build order: %w", err)
+		}
+		d := *req.ValidUntil
+		req.ValidUntil = &d
+	}
+
+	req.RequestID = uuid.New().String()
+	return &req, nil
+}
+
+// checkValidUntil returns an error if date is outside the orderbook's
+// MinValidUntil and MaxValidUntil. A missing bound is not checked.
+func checkValidUntil(date Date, ob *market.Orderbook) error {
+	if ob.MinValidUntil != "" {
+		earliest, err := ParseDate(ob.MinValidUntil)
+		if err != nil {
+			return fmt.Errorf("min valid until: %w", err)
+		}
+		if date.Compare(earliest) < 0 {
+			return fmt.Errorf("valid until %s is before the earliest allowed date %s", date, earliest)
+		}
+	}
+	if ob.MaxValidUntil != "" {
+		latest, err := ParseDate(ob.MaxValidUntil)
+		if err != nil {
+			return fmt.Errorf("max valid until: %w", err)
+		}
+		if date.Compare(latest) > 0 {
+			return fmt.Errorf("valid until %s is after the latest allowed date %s", date, latest)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package trading
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/market"
+)
+
+func testBuilderOrderbook() *market.Orderbook {
+	ob := *testOrderbooks["5247"]
+	ob.MinValidUntil = "2026-04-09"
+	ob.MaxValidUntil = "2026-07-07"
+	ob.FeatureSupport = market.FeatureSupport{FillAndOrKill: true, OpenVolume: true}
+	return &ob
+}
+
+func TestOrderBuilder(t *testing.T) {
+	validUntil, _ := ParseDate("2026-05-15")
+	req, err := NewLimitOrder("1111111", testBuilderOrderbook()).
+		Buy(10).
+		At(decimal.MustParse("245.50")).
+		ValidUntil(validUntil).
+		FillOrKill().
+		Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if _, err := uuid.Parse(req.RequestID); err != nil {
+		t.Errorf("RequestID = %q, want a UUID", req.RequestID)
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := strings.Replace(string(data), req.RequestID, "ID", 1)
+	want := `{"isDividendReinvestment":false,"requestId":"ID","price":245.50,"volume":10,` +
+		`"openVolume":null,"accountId":"1111111","side":"BUY","orderbookId":"5247","validUntil":"2026-05-15",` +
+		`"metadata":{"orderEntryMode":"ADVANCED","hasTouchedPrice":"true"},"condition":"FILL_OR_KILL","orderRequestParameters":null}`
+	if body != want {
+		t.Errorf("body =\n%s\nwant\n%s", body, want)
+	}
+
+	again, err := NewLimitOrder("1111111", testBuilderOrderbook()).Sell(1).At(decimal.MustParse("10")).Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if again.RequestID == req.RequestID {
+		t.Error("two builds share a request ID")
+	}
+	if again.ValidUntil != nil || again.Condition != OrderConditionNormal {
+		t.Errorf("defaults = %v/%s, want a normal day order", again.ValidUntil, again.Condition)
+	}
+}
+
+func TestOrderBuilder_Errors(t *testing.T) {
+	date := func(s string) Date {
+		d, _ := ParseDate(s)
+		return d
+	}
+	price := decimal.MustParse("245.50")
+	noFeatures := testBuilderOrderbook()
+	noFeatures.FeatureSupport = market.FeatureSupport{}
+
+	tests := []struct {
+		name    string
+		builder *OrderBuilder
+		want    string
+	}{
+		{"no orderbook", NewLimitOrder("1", nil).Buy(1).At(price), "orderbook is required"},
+		{"no side", NewLimitOrder("1", testBuilderOrderbook()).At(price), "side is required"},
+		{"no price", NewLimitOrder("1", testBuilderOrderbook()).Buy(1), "price must be greater than 0"},
+		{"no volume", NewLimitOrder("1", testBuilderOrderbook()).Buy(0).At(price), "volume must be greater than 0"},
+		{"zero date", NewLimitOrder("1", testBuilderOrderbook()).Buy(1).At(price).ValidUntil(Date{}), "valid until date is required"},
+		{"too early", NewLimitOrder("1", testBuilderOrderbook()).Buy(1).At(price).ValidUntil(date("2026-04-08")), "before the earliest allowed date 2026-04-09"},
+		{"too late", NewLimitOrder("1", testBuilderOrderbook()).Buy(1).At(price).ValidUntil(date("2026-07-08")), "after the latest allowed date 2026-07-07"},
+		{"fill-or-kill unsupported", NewLimitOrder("1", noFeatures).Buy(1).At(price).FillOrKill(), "does not support fill-or-kill"},
+		{"open volume unsupported", NewLimitOrder("1", noFeatures).Buy(10).At(price).OpenVolume(5), "does not support open volume"},
+		{"open volume above volume", NewLimitOrder("1", testBuilderOrderbook()).Buy(10).At(price).OpenVolume(11), "open volume must be between 1 and the volume 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	_, err := NewLimitOrder("1", testBuilderOrderbook()).Buy(1).At(decimal.MustParse("245.52")).Build()
+	var tickErr *TickSizeError
+	if !errors.As(err, &tickErr) {
+		t.Errorf("off-tick price: err = %v, want *TickSizeError", err)
+	}
+}
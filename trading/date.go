@@ -0,0 +1,95 @@
+package trading
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// dateLayout is how Avanza formats dates such as an order's validUntil.
+const dateLayout = "2006-01-02"
+
+// Date is a calendar date without a time of day, e.g. the last day an order is
+// valid. It is sent as "2006-01-02". The zero value is not a valid date.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate returns the date of t in t's location.
+func NewDate(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate parses a date such as "2026-04-09".
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("parse date: %w", err)
+	}
+	return NewDate(t), nil
+}
+
+// String returns d as "2006-01-02".
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// IsZero reports whether d is the zero Date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Compare returns -1, 0 or 1 as d is before, equal to or after e.
+func (d Date) Compare(e Date) int {
+	switch {
+	case d.Year != e.Year:
+		return cmpInt(d.Year, e.Year)
+	case d.Month != e.Month:
+		return cmpInt(int(d.Month), int(e.Month))
+	default:
+		return cmpInt(d.Day, e.Day)
+	}
+}
+
+// MarshalJSON encodes d as "2006-01-02". The zero Date is an error, so an
+// unset date is never sent; use a nil *Date for null.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return nil, fmt.Errorf("marshal date: zero Date")
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes "2006-01-02". Null and "" leave d unchanged.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+	v, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package trading
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDate_JSON(t *testing.T) {
+	d := NewDate(time.Date(2026, time.April, 9, 23, 30, 0, 0, time.UTC))
+	data, err := json.Marshal(struct {
+		ValidUntil *Date `json:"validUntil"`
+		Unset      *Date `json:"unset"`
+	}{ValidUntil: &d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"validUntil":"2026-04-09","unset":null}`; got != want {
+		t.Errorf("Marshal = %s, want %s", got, want)
+	}
+
+	var got Date
+	if err := json.Unmarshal([]byte(`"2026-04-09"`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != d {
+		t.Errorf("Unmarshal = %v, want %v", got, d)
+	}
+	if err := json.Unmarshal([]byte(`"09/04/2026"`), &got); err == nil {
+		t.Error("Unmarshal of a malformed date: want an error")
+	}
+	if _, err := json.Marshal(ModifyOrderRequest{ValidUntil: &Date{}}); err == nil {
+		t.Error("Marshal of a zero Date: want an error")
+	}
+}
+
+func TestDate_Compare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2026-04-09", "2026-04-09", 0},
+		{"2026-04-09", "2026-04-10", -1},
+		{"2026-05-01", "2026-04-30", 1},
+		{"2025-12-31", "2026-01-01", -1},
+	}
+	for _, tt := range tests {
+		a, _ := ParseDate(tt.a)
+		b, _ := ParseDate(tt.b)
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
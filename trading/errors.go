@@ -0,0 +1,75 @@
+// Package trading provides trading functionality for the Avanza API.
+package trading
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+)
+
+// OrderRejectedError is returned when Avanza answers an order or stop loss
+// request with a non-success status. The request reached Avanza but was not
+// accepted, e.g. because of insufficient buying power or a price outside the
+// allowed range. The response is still returned alongside the error.
+//
+//	var rejected *trading.OrderRejectedError
+//	if errors.As(err, &rejected) {
+//	    log.Printf("rejected: %s %v", rejected.Message, rejected.Parameters)
+//	}
+type OrderRejectedError struct {
+	// Op is the operation that was rejected, e.g. "place order".
+	Op string
+
+	// Status is the OrderRequestStatus or StopLossStatus from the response.
+	Status string
+
+	// Message is Avanza's explanation, or an error code such as
+	// "order.price.outside.limits". Empty for stop loss requests.
+	Message string
+
+	// Parameters are the values Avanza substitutes into Message.
+	Parameters []string
+
+	// OrderID is the order or stop loss order ID, if Avanza returned one.
+	OrderID string
+}
+
+func (e *OrderRejectedError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Status
+	}
+	if len(e.Parameters) > 0 {
+		msg += " (" + strings.Join(e.Parameters, ", ") + ")"
+	}
+	return fmt.Sprintf("%s rejected: %s", e.Op, msg)
+}
+
+// TickSizeError is returned by PlaceOrder and ModifyOrder, when configured
+// with WithTickSizeCheck, if the price is not a multiple of the orderbook's
+// tick size. The order is not sent. Below and Above are the nearest valid
+// prices, ready to retry with.
+//
+//	var tickErr *trading.TickSizeError
+//	if errors.As(err, &tickErr) {
+//	    req.Price = tickErr.Below
+//	}
+type TickSizeError struct {
+	OrderbookID string
+
+	// Price is the requested price.
+	Price decimal.Decimal
+
+	// Tick is the tick size of the price band Price falls in.
+	Tick decimal.Decimal
+
+	// Below and Above are the nearest valid prices under and over Price.
+	Below decimal.Decimal
+	Above decimal.Decimal
+}
+
+func (e *TickSizeError) Error() string {
+	return fmt.Sprintf("price %s is not a multiple of tick size %s for orderbook %s (nearest valid prices %s and %s)",
+		e.Price, e.Tick, e.OrderbookID, e.Below, e.Above)
+}
@@ -0,0 +1,125 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/vmorsell/avanza-sdk-go/accounts"
+)
+
+// buyingPowerCurrency is the currency Avanza reports buying power in.
+const buyingPowerCurrency = "SEK"
+
+// PreviewOrder runs what is worth knowing before placing req, without placing
+// it: ValidateOrder warnings, GetPreliminaryFee fees, whether the orderbook
+// supports the order's condition, and the account's buying power before and
+// after the order. The orderbook is read through the OrderbookSource, so a
+// cached one costs no request.
+//
+//	preview, err := c.Trading.PreviewOrder(ctx, req)
+//	if err == nil && len(preview.Warnings) == 0 && preview.ConditionAllowed {
+//	    _, err = c.Trading.PlaceOrder(ctx, req)
+//	}
+func (s *Service) PreviewOrder(ctx context.Context, req *PlaceOrderRequest) (*OrderPreview, error) {
+	if err := validatePlaceOrder(req); err != nil {
+		return nil, err
+	}
+
+	ob, err := s.orderbooks.GetOrderbook(ctx, req.OrderbookID)
+	if err != nil {
+		return nil, fmt.Errorf("preview order: get orderbook: %w", err)
+	}
+
+	validation, err := s.ValidateOrder(ctx, &ValidateOrderRequest{
+		IsDividendReinvestment: req.IsDividendReinvestment,
+		Price:                  req.Price,
+		Volume:                 req.Volume,
+		OpenVolume:             req.OpenVolume,
+		AccountID:              req.AccountID,
+		Side:                   req.Side,
+		OrderbookID:            req.OrderbookID,
+		ValidUntil:             req.ValidUntil,
+		Metadata:               &req.Metadata,
+		Condition:              req.Condition,
+		ISIN:                   ob.ISIN,
+		Currency:               ob.Currency,
+		MarketPlace:            ob.MarketPlace,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("preview order: validate: %w", err)
+	}
+
+	fee, err := s.GetPreliminaryFee(ctx, &PreliminaryFeeRequest{
+		AccountID:   req.AccountID,
+		OrderbookID: req.OrderbookID,
+		Price:       req.Price.String(),
+		Volume:      strconv.Itoa(req.Volume),
+		Side:        req.Side,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("preview order: preliminary fee: %w", err)
+	}
+
+	tradingAccounts, err := s.accounts.GetTradingAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("preview order: get trading accounts: %w", err)
+	}
+	var account *accounts.TradingAccount
+	for i := range tradingAccounts {
+		if tradingAccounts[i].AccountID == req.AccountID {
+			account = &tradingAccounts[i]
+			break
+		}
+	}
+	if account == nil {
+		return nil, fmt.Errorf("preview order: account %s is not a trading account", req.AccountID)
+	}
+
+	preview := &OrderPreview{
+		Warnings:             validation.Warnings(),
+		Currency:             fee.OrderbookCurrency,
+		Commission:           fee.Commission,
+		MarketFees:           fee.MarketFees,
+		TotalFees:            fee.TotalFees,
+		TotalSum:             fee.TotalSum,
+		TotalSumWithoutFees:  fee.TotalSumWithoutFees,
+		CurrencyExchangeRate: fee.CurrencyExchangeFee.Rate,
+		CurrencyExchangeCost: fee.CurrencyExchangeFee.Sum,
+		ConditionAllowed:     req.Condition != OrderConditionFillOrKill || ob.FeatureSupport.FillAndOrKill,
+		BuyingPower:          account.AvailableForPurchase,
+		Orderbook:            ob,
+	}
+
+	switch {
+	case req.Side == OrderSideSell:
+		after := preview.BuyingPower
+		preview.BuyingPowerAfter = &after
+	case preview.Currency == buyingPowerCurrency:
+		after := preview.BuyingPower.Sub(preview.TotalSum).Sub(preview.CurrencyExchangeCost)
+		preview.BuyingPowerAfter = &after
+	}
+
+	return preview, nil
+}
+
+// Warnings returns the checks that did not pass, in field order.
+func (r *ValidateOrderResponse) Warnings() []OrderWarning {
+	var out []OrderWarning
+	for _, c := range []struct {
+		result  ValidationResult
+		warning OrderWarning
+	}{
+		{r.CommissionWarning, OrderWarningCommission},
+		{r.EmployeeValidation, OrderWarningEmployee},
+		{r.LargeInScaleWarning, OrderWarningLargeInScale},
+		{r.OrderValueLimitWarning, OrderWarningOrderValueLimit},
+		{r.PriceRampingWarning, OrderWarningPriceRamping},
+		{r.CanadaOddLotWarning, OrderWarningCanadaOddLot},
+	} {
+		if !c.result.Valid {
+			out = append(out, c.warning)
+		}
+	}
+	return out
+}
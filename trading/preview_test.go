@@ -0,0 +1,142 @@
+package trading
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/market"
+)
+
+const testTradingAccounts = `[{"accountId": "1111111", "availableForPurchase": 97605.0}]`
+
+// newPreviewServer serves the validation, fee and account endpoints
+// PreviewOrder calls, answering with the given fixture and bodies.
+func newPreviewServer(t *testing.T, feeFixture, validation string) *httptest.Server {
+	t.Helper()
+	fee, err := os.ReadFile(filepath.Join("testdata", feeFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_api/trading-critical/rest/order/validation/validate":
+			_, _ = w.Write([]byte(validation))
+		case "/_api/trading/preliminary-fee/preliminaryfee":
+			_, _ = w.Write(fee)
+		case "/_api/trading-critical/rest/accounts":
+			_, _ = w.Write([]byte(testTradingAccounts))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func previewRequest(orderbookID string, side OrderSide, condition OrderCondition) *PlaceOrderRequest {
+	return &PlaceOrderRequest{
+		AccountID:   "1111111",
+		OrderbookID: orderbookID,
+		Price:       decimal.MustParse("240.00"),
+		Volume:      10,
+		Side:        side,
+		Condition:   condition,
+	}
+}
+
+func TestPreviewOrder(t *testing.T) {
+	validation, err := os.ReadFile(filepath.Join("testdata", "validate_order.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newPreviewServer(t, "preliminary_fee.json", string(validation))
+	orderbooks := fakeOrderbooks{"5247": {ID: "5247", ISIN: "SE0015811963", Currency: "SEK", MarketPlace: "XSTO"}}
+	svc := NewService(newTestClient(server.URL), WithOrderbookSource(orderbooks))
+
+	preview, err := svc.PreviewOrder(context.Background(), previewRequest("5247", OrderSideBuy, OrderConditionNormal))
+	if err != nil {
+		t.Fatalf("PreviewOrder: %v", err)
+	}
+	if len(preview.Warnings) != 0 {
+		t.Errorf("Warnings = %v, want none", preview.Warnings)
+	}
+	if preview.TotalFees.String() != "1.00" || preview.TotalSum.String() != "2401.00" || preview.Currency != "SEK" {
+		t.Errorf("fees = %s, total = %s %s, want 1.00 and 2401.00 SEK", preview.TotalFees, preview.TotalSum, preview.Currency)
+	}
+	if !preview.ConditionAllowed {
+		t.Error("ConditionAllowed = false for a normal order")
+	}
+	if preview.BuyingPower.String() != "97605.0" {
+		t.Errorf("BuyingPower = %s, want 97605.0", preview.BuyingPower)
+	}
+	if preview.BuyingPowerAfter == nil || preview.BuyingPowerAfter.String() != "95204.00" {
+		t.Errorf("BuyingPowerAfter = %v, want 95204.00", preview.BuyingPowerAfter)
+	}
+}
+
+func TestPreviewOrder_ForeignCurrencyAndFillOrKill(t *testing.T) {
+	validation := `{
+		"commissionWarning": {"valid": false},
+		"employeeValidation": {"valid": true},
+		"largeInScaleWarning": {"valid": true},
+		"orderValueLimitWarning": {"valid": true},
+		"priceRampingWarning": {"valid": false},
+		"canadaOddLotWarning": {"valid": true}
+	}`
+	server := newPreviewServer(t, "preliminary_fee_usd.json", validation)
+	orderbooks := fakeOrderbooks{"4478": {ID: "4478", ISIN: "US67066G1040", Currency: "USD", MarketPlace: "XNAS"}}
+	svc := NewService(newTestClient(server.URL), WithOrderbookSource(orderbooks))
+
+	preview, err := svc.PreviewOrder(context.Background(), previewRequest("4478", OrderSideBuy, OrderConditionFillOrKill))
+	if err != nil {
+		t.Fatalf("PreviewOrder: %v", err)
+	}
+	if want := []OrderWarning{OrderWarningCommission, OrderWarningPriceRamping}; !slices.Equal(preview.Warnings, want) {
+		t.Errorf("Warnings = %v, want %v", preview.Warnings, want)
+	}
+	if preview.CurrencyExchangeRate.String() != "0.25" || preview.CurrencyExchangeCost.String() != "4.50" {
+		t.Errorf("exchange fee = %s%% (%s), want 0.25%% (4.50)", preview.CurrencyExchangeRate, preview.CurrencyExchangeCost)
+	}
+	if preview.ConditionAllowed {
+		t.Error("ConditionAllowed = true, want false without FillAndOrKill support")
+	}
+	if preview.BuyingPowerAfter != nil {
+		t.Errorf("BuyingPowerAfter = %s, want nil for a USD order", preview.BuyingPowerAfter)
+	}
+
+	orderbooks["4478"].FeatureSupport = market.FeatureSupport{FillAndOrKill: true}
+	preview, err = svc.PreviewOrder(context.Background(), previewRequest("4478", OrderSideSell, OrderConditionFillOrKill))
+	if err != nil {
+		t.Fatalf("PreviewOrder: %v", err)
+	}
+	if !preview.ConditionAllowed {
+		t.Error("ConditionAllowed = false, want true with FillAndOrKill support")
+	}
+	if preview.BuyingPowerAfter == nil || !preview.BuyingPowerAfter.Equal(preview.BuyingPower) {
+		t.Errorf("BuyingPowerAfter = %v, want the unchanged buying power for a sell", preview.BuyingPowerAfter)
+	}
+}
+
+func TestPreviewOrder_Errors(t *testing.T) {
+	server := newPreviewServer(t, "preliminary_fee.json", `{}`)
+	svc := NewService(newTestClient(server.URL), WithOrderbookSource(fakeOrderbooks{"5247": {ID: "5247"}}))
+
+	if _, err := svc.PreviewOrder(context.Background(), nil); err == nil {
+		t.Error("nil request: want an error")
+	}
+	if _, err := svc.PreviewOrder(context.Background(), previewRequest("unknown", OrderSideBuy, OrderConditionNormal)); err == nil {
+		t.Error("unknown orderbook: want an error")
+	}
+	req := previewRequest("5247", OrderSideBuy, OrderConditionNormal)
+	req.AccountID = "2222222"
+	if _, err := svc.PreviewOrder(context.Background(), req); err == nil {
+		t.Error("unknown account: want an error")
+	}
+}
@@ -0,0 +1,42 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/vmorsell/avanza-sdk-go/internal/drifttest"
+)
+
+// driftFixtures maps each golden capture in testdata/ to the type it must
+// decode into. Refresh them with `go run ./scripts/capture-fixtures`, which
+// logs in with BankID, records the authenticated endpoints and the order and
+// stop loss streams, and writes redacted copies here. Responses to placing,
+// modifying and deleting are never captured by the tool; update those by hand
+// from a recorded session.
+var driftFixtures = map[string]func() any{
+	"orders.json":          func() any { return &GetOrdersResponse{} },
+	"order.json":           func() any { return &GetOrderResponse{} },
+	"validate_order.json":  func() any { return &ValidateOrderResponse{} },
+	"preliminary_fee.json": func() any { return &PreliminaryFeeResponse{} },
+
+	// An order in foreign currency adds the exchange fee.
+	"preliminary_fee_usd.json": func() any { return &PreliminaryFeeResponse{} },
+
+	"place_order.json":          func() any { return &PlaceOrderResponse{} },
+	"place_order_rejected.json": func() any { return &PlaceOrderResponse{} },
+	"modify_order.json":         func() any { return &ModifyOrderResponse{} },
+	"delete_order.json":         func() any { return &DeleteOrderResponse{} },
+
+	"place_stoploss.json":  func() any { return &PlaceStopLossResponse{} },
+	"stoploss_orders.json": func() any { return &[]StopLossOrder{} },
+	"stoploss_order.json":  func() any { return &StopLossOrder{} },
+
+	// Data of SSE events on /_push/trading/orders/ and /_push/trading/stoploss/.
+	"order_event_new.json":        func() any { return &OrderEventData{} },
+	"order_event_deleted.json":    func() any { return &OrderEventData{} },
+	"stoploss_event_updated.json": func() any { return &StopLossEventData{} },
+	"stoploss_event_deleted.json": func() any { return &StopLossEventData{} },
+}
+
+func TestSchemaDrift(t *testing.T) {
+	drifttest.Run(t, "testdata", driftFixtures)
+}
@@ -3,14 +3,15 @@ package trading
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 
+	"github.com/vmorsell/avanza-sdk-go/accounts"
 	"github.com/vmorsell/avanza-sdk-go/client"
 	"github.com/vmorsell/avanza-sdk-go/internal/sse"
+	"github.com/vmorsell/avanza-sdk-go/market"
 )
 
 // SubscribeToOrders subscribes to real-time order updates. Call Close() when done.
@@ -27,13 +28,14 @@ func (s *Service) SubscribeToOrders(ctx context.Context) (*OrdersSubscription, e
 		}
 	}
 
+	endpoint := "/_push/trading/orders/"
 	sub := sse.New(ctx, sse.Config{
 		Client:   s.client,
-		Endpoint: "/_push/trading/orders/",
+		Endpoint: endpoint,
 		Referer:  "https://www.avanza.se/min-ekonomi/ordrar.html",
 	})
 
-	return newOrdersSubscription(sub), nil
+	return newOrdersSubscription(sub, s.client, endpoint), nil
 }
 
 // SubscribeToStopLoss subscribes to real-time stop loss order updates. Call Close() when done.
@@ -50,50 +52,67 @@ func (s *Service) SubscribeToStopLoss(ctx context.Context) (*StopLossSubscriptio
 		}
 	}
 
+	endpoint := "/_push/trading/stoploss/"
 	sub := sse.New(ctx, sse.Config{
 		Client:   s.client,
-		Endpoint: "/_push/trading/stoploss/",
+		Endpoint: endpoint,
 		Referer:  "https://www.avanza.se/min-ekonomi/ordrar.html",
 	})
 
-	return newStopLossSubscription(sub), nil
+	return newStopLossSubscription(sub, s.client, endpoint), nil
 }
 
 // Service handles trading operations: orders, stop loss, validation, and fees.
 type Service struct {
-	client *client.Client
+	client        *client.Client
+	orderbooks    OrderbookSource
+	accounts      *accounts.Service
+	tickSizeCheck bool
+}
+
+// OrderbookSource returns an instrument's trading rules. *market.Service
+// implements it, and caches the orderbooks when configured with
+// market.WithCache.
+type OrderbookSource interface {
+	GetOrderbook(ctx context.Context, orderbookID string) (*market.Orderbook, error)
+}
+
+// Option is a functional option for configuring the Service.
+type Option func(*Service)
+
+// WithOrderbookSource sets where PreviewOrder and the tick size check read
+// orderbooks from, e.g. a *market.Service with a cache. Defaults to an
+// uncached market.Service on the same client.
+func WithOrderbookSource(orderbooks OrderbookSource) Option {
+	return func(s *Service) {
+		s.orderbooks = orderbooks
+	}
 }
 
 // NewService creates a new trading service.
-func NewService(client *client.Client) *Service {
-	return &Service{
-		client: client,
+func NewService(client *client.Client, opts ...Option) *Service {
+	s := &Service{
+		client:     client,
+		orderbooks: market.NewService(client),
+		accounts:   accounts.NewService(client),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // PlaceOrder places a new order. Consider validating first with ValidateOrder
-// and checking fees with GetPreliminaryFee.
+// and checking fees with GetPreliminaryFee. If Avanza rejects the order, the
+// response is returned together with an *OrderRejectedError. With
+// WithTickSizeCheck, an off-tick price fails with *TickSizeError before
+// anything is sent.
 func (s *Service) PlaceOrder(ctx context.Context, req *PlaceOrderRequest) (*PlaceOrderResponse, error) {
-	if req == nil {
-		return nil, fmt.Errorf("request is required")
-	}
-	if req.AccountID == "" {
-		return nil, fmt.Errorf("accountId is required")
-	}
-	if req.OrderbookID == "" {
-		return nil, fmt.Errorf("orderbookId is required")
-	}
-	if req.Price <= 0 {
-		return nil, fmt.Errorf("price must be greater than 0")
-	}
-	if req.Volume <= 0 {
-		return nil, fmt.Errorf("volume must be greater than 0")
-	}
-	if req.Side != OrderSideBuy && req.Side != OrderSideSell {
-		return nil, fmt.Errorf("side must be %s or %s", OrderSideBuy, OrderSideSell)
+	if err := validatePlaceOrder(req); err != nil {
+		return nil, err
 	}
-	if req.Condition != OrderConditionNormal && req.Condition != OrderConditionFillOrKill {
-		return nil, fmt.Errorf("condition must be %s or %s", OrderConditionNormal, OrderConditionFillOrKill)
+	if err := s.checkTickSize(ctx, req.OrderbookID, req.Price); err != nil {
+		return nil, err
 	}
 
 	httpResp, err := s.client.Post(ctx, "/_api/trading-critical/rest/order/new", req)
@@ -107,18 +126,50 @@ func (s *Service) PlaceOrder(ctx context.Context, req *PlaceOrderRequest) (*Plac
 	}
 
 	var resp PlaceOrderResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
 	if resp.OrderRequestStatus != OrderRequestStatusSuccess {
-		return &resp, fmt.Errorf("order request failed: %s", resp.Message)
+		return &resp, &OrderRejectedError{
+			Op:         "place order",
+			Status:     string(resp.OrderRequestStatus),
+			Message:    resp.Message,
+			Parameters: resp.Parameters,
+			OrderID:    resp.OrderID,
+		}
 	}
 
 	return &resp, nil
 }
 
-// DeleteOrder deletes an existing order.
+// validatePlaceOrder checks the fields PlaceOrder and PreviewOrder require.
+func validatePlaceOrder(req *PlaceOrderRequest) error {
+	if req == nil {
+		return fmt.Errorf("request is required")
+	}
+	if req.AccountID == "" {
+		return fmt.Errorf("accountId is required")
+	}
+	if req.OrderbookID == "" {
+		return fmt.Errorf("orderbookId is required")
+	}
+	if req.Price.Sign() <= 0 {
+		return fmt.Errorf("price must be greater than 0")
+	}
+	if req.Volume <= 0 {
+		return fmt.Errorf("volume must be greater than 0")
+	}
+	if req.Side != OrderSideBuy && req.Side != OrderSideSell {
+		return fmt.Errorf("side must be %s or %s", OrderSideBuy, OrderSideSell)
+	}
+	if req.Condition != OrderConditionNormal && req.Condition != OrderConditionFillOrKill {
+		return fmt.Errorf("condition must be %s or %s", OrderConditionNormal, OrderConditionFillOrKill)
+	}
+	return nil
+}
+
+// DeleteOrder deletes an existing order. A rejection is reported as *OrderRejectedError.
 func (s *Service) DeleteOrder(ctx context.Context, req *DeleteOrderRequest) (*DeleteOrderResponse, error) {
 	if req == nil {
 		return nil, fmt.Errorf("request is required")
@@ -141,18 +192,26 @@ func (s *Service) DeleteOrder(ctx context.Context, req *DeleteOrderRequest) (*De
 	}
 
 	var resp DeleteOrderResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
 	if resp.OrderRequestStatus != OrderRequestStatusSuccess {
-		return &resp, fmt.Errorf("delete order request failed: %s", resp.Message)
+		return &resp, &OrderRejectedError{
+			Op:         "delete order",
+			Status:     string(resp.OrderRequestStatus),
+			Message:    resp.Message,
+			Parameters: resp.Parameters,
+			OrderID:    resp.OrderID,
+		}
 	}
 
 	return &resp, nil
 }
 
-// ModifyOrder modifies an existing order.
+// ModifyOrder modifies an existing order. A rejection is reported as *OrderRejectedError.
+// With WithTickSizeCheck the order is looked up with GetOrder to find its
+// orderbook before the new price is checked.
 func (s *Service) ModifyOrder(ctx context.Context, req *ModifyOrderRequest) (*ModifyOrderResponse, error) {
 	if req == nil {
 		return nil, fmt.Errorf("request is required")
@@ -163,12 +222,21 @@ func (s *Service) ModifyOrder(ctx context.Context, req *ModifyOrderRequest) (*Mo
 	if req.AccountID == "" {
 		return nil, fmt.Errorf("accountId is required")
 	}
-	if req.Price <= 0 {
+	if req.Price.Sign() <= 0 {
 		return nil, fmt.Errorf("price must be greater than 0")
 	}
 	if req.Volume <= 0 {
 		return nil, fmt.Errorf("volume must be greater than 0")
 	}
+	if s.tickSizeCheck {
+		order, err := s.GetOrder(ctx, &GetOrderRequest{OrderID: req.OrderID, AccountID: req.AccountID})
+		if err != nil {
+			return nil, fmt.Errorf("get order for tick size check: %w", err)
+		}
+		if err := s.checkTickSize(ctx, order.OrderbookID, req.Price); err != nil {
+			return nil, err
+		}
+	}
 
 	httpResp, err := s.client.Post(ctx, "/_api/trading-critical/rest/order/modify", req)
 	if err != nil {
@@ -181,12 +249,18 @@ func (s *Service) ModifyOrder(ctx context.Context, req *ModifyOrderRequest) (*Mo
 	}
 
 	var resp ModifyOrderResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
 	if resp.OrderRequestStatus != OrderRequestStatusSuccess {
-		return &resp, fmt.Errorf("modify order request failed: %s", resp.Message)
+		return &resp, &OrderRejectedError{
+			Op:         "modify order",
+			Status:     string(resp.OrderRequestStatus),
+			Message:    resp.Message,
+			Parameters: resp.Parameters,
+			OrderID:    resp.OrderID,
+		}
 	}
 
 	return &resp, nil
@@ -220,7 +294,7 @@ func (s *Service) GetOrder(ctx context.Context, req *GetOrderRequest) (*GetOrder
 	}
 
 	var resp GetOrderResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -240,7 +314,7 @@ func (s *Service) GetOrders(ctx context.Context) (*GetOrdersResponse, error) {
 	}
 
 	var resp GetOrdersResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -258,7 +332,7 @@ func (s *Service) ValidateOrder(ctx context.Context, req *ValidateOrderRequest)
 	if req.OrderbookID == "" {
 		return nil, fmt.Errorf("orderbookId is required")
 	}
-	if req.Price <= 0 {
+	if req.Price.Sign() <= 0 {
 		return nil, fmt.Errorf("price must be greater than 0")
 	}
 	if req.Volume <= 0 {
@@ -291,7 +365,7 @@ func (s *Service) ValidateOrder(ctx context.Context, req *ValidateOrderRequest)
 	}
 
 	var resp ValidateOrderResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -344,14 +418,14 @@ func (s *Service) GetPreliminaryFee(ctx context.Context, req *PreliminaryFeeRequ
 	}
 
 	var resp PreliminaryFeeResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
 	return &resp, nil
 }
 
-// PlaceStopLoss places a new stop loss order.
+// PlaceStopLoss places a new stop loss order. A rejection is reported as *OrderRejectedError.
 func (s *Service) PlaceStopLoss(ctx context.Context, req *PlaceStopLossRequest) (*PlaceStopLossResponse, error) {
 	if req == nil {
 		return nil, fmt.Errorf("request is required")
@@ -400,12 +474,16 @@ func (s *Service) PlaceStopLoss(ctx context.Context, req *PlaceStopLossRequest)
 	}
 
 	var resp PlaceStopLossResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
 	if resp.Status != StopLossStatusSuccess {
-		return &resp, fmt.Errorf("stop loss order request failed: %s", resp.Status)
+		return &resp, &OrderRejectedError{
+			Op:      "place stop loss",
+			Status:  string(resp.Status),
+			OrderID: resp.StopLossOrderID,
+		}
 	}
 
 	return &resp, nil
@@ -424,7 +502,7 @@ func (s *Service) GetStopLossOrders(ctx context.Context) ([]StopLossOrder, error
 	}
 
 	var orders []StopLossOrder
-	if err := json.NewDecoder(httpResp.Body).Decode(&orders); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &orders); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -458,14 +536,14 @@ func (s *Service) GetStopLoss(ctx context.Context, req *GetStopLossRequest) (*St
 	}
 
 	var order StopLossOrder
-	if err := json.NewDecoder(httpResp.Body).Decode(&order); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &order); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
 	return &order, nil
 }
 
-// ModifyStopLoss modifies an existing stop loss order.
+// ModifyStopLoss modifies an existing stop loss order. A rejection is reported as *OrderRejectedError.
 func (s *Service) ModifyStopLoss(ctx context.Context, req *ModifyStopLossRequest) (*PlaceStopLossResponse, error) {
 	if req == nil {
 		return nil, fmt.Errorf("request is required")
@@ -515,12 +593,16 @@ func (s *Service) ModifyStopLoss(ctx context.Context, req *ModifyStopLossRequest
 	}
 
 	var resp PlaceStopLossResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+	if err := s.client.DecodeJSON(httpResp, &resp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
 	if resp.Status != StopLossStatusSuccess {
-		return &resp, fmt.Errorf("modify stop loss order request failed: %s", resp.Status)
+		return &resp, &OrderRejectedError{
+			Op:      "modify stop loss",
+			Status:  string(resp.Status),
+			OrderID: resp.StopLossOrderID,
+		}
 	}
 
 	return &resp, nil
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 )
 
 func newTestClient(baseURL string) *client.Client {
@@ -36,8 +37,8 @@ func TestPlaceOrder_Success(t *testing.T) {
 		if req.Side != OrderSideBuy {
 			t.Errorf("side = %q, want %q", req.Side, OrderSideBuy)
 		}
-		if req.Price != 100.0 {
-			t.Errorf("price = %f, want 100.0", req.Price)
+		if !req.Price.Equal(decimal.MustParse("100.0")) {
+			t.Errorf("price = %s, want 100.0", req.Price)
 		}
 		if req.Volume != 10 {
 			t.Errorf("volume = %d, want 10", req.Volume)
@@ -55,7 +56,7 @@ func TestPlaceOrder_Success(t *testing.T) {
 	resp, err := svc.PlaceOrder(context.Background(), &PlaceOrderRequest{
 		AccountID:   "12345",
 		OrderbookID: "5246",
-		Price:       100.0,
+		Price:       decimal.MustParse("100.0"),
 		Volume:      10,
 		Side:        OrderSideBuy,
 		Condition:   OrderConditionNormal,
@@ -75,12 +76,12 @@ func TestPlaceOrder_ValidationErrors(t *testing.T) {
 		name string
 		req  *PlaceOrderRequest
 	}{
-		{"empty accountId", &PlaceOrderRequest{OrderbookID: "1", Price: 1, Volume: 1, Side: OrderSideBuy, Condition: OrderConditionNormal}},
-		{"empty orderbookId", &PlaceOrderRequest{AccountID: "1", Price: 1, Volume: 1, Side: OrderSideBuy, Condition: OrderConditionNormal}},
-		{"zero price", &PlaceOrderRequest{AccountID: "1", OrderbookID: "1", Price: 0, Volume: 1, Side: OrderSideBuy, Condition: OrderConditionNormal}},
-		{"zero volume", &PlaceOrderRequest{AccountID: "1", OrderbookID: "1", Price: 1, Volume: 0, Side: OrderSideBuy, Condition: OrderConditionNormal}},
-		{"invalid side", &PlaceOrderRequest{AccountID: "1", OrderbookID: "1", Price: 1, Volume: 1, Side: "INVALID", Condition: OrderConditionNormal}},
-		{"invalid condition", &PlaceOrderRequest{AccountID: "1", OrderbookID: "1", Price: 1, Volume: 1, Side: OrderSideBuy, Condition: "INVALID"}},
+		{"empty accountId", &PlaceOrderRequest{OrderbookID: "1", Price: decimal.NewFromInt(1), Volume: 1, Side: OrderSideBuy, Condition: OrderConditionNormal}},
+		{"empty orderbookId", &PlaceOrderRequest{AccountID: "1", Price: decimal.NewFromInt(1), Volume: 1, Side: OrderSideBuy, Condition: OrderConditionNormal}},
+		{"zero price", &PlaceOrderRequest{AccountID: "1", OrderbookID: "1", Price: decimal.NewFromInt(0), Volume: 1, Side: OrderSideBuy, Condition: OrderConditionNormal}},
+		{"zero volume", &PlaceOrderRequest{AccountID: "1", OrderbookID: "1", Price: decimal.NewFromInt(1), Volume: 0, Side: OrderSideBuy, Condition: OrderConditionNormal}},
+		{"invalid side", &PlaceOrderRequest{AccountID: "1", OrderbookID: "1", Price: decimal.NewFromInt(1), Volume: 1, Side: "INVALID", Condition: OrderConditionNormal}},
+		{"invalid condition", &PlaceOrderRequest{AccountID: "1", OrderbookID: "1", Price: decimal.NewFromInt(1), Volume: 1, Side: OrderSideBuy, Condition: "INVALID"}},
 	}
 
 	for _, tt := range tests {
@@ -102,7 +103,7 @@ func TestPlaceOrder_HTTPError(t *testing.T) {
 
 	svc := NewService(newTestClient(server.URL))
 	_, err := svc.PlaceOrder(context.Background(), &PlaceOrderRequest{
-		AccountID: "1", OrderbookID: "1", Price: 1, Volume: 1, Side: OrderSideBuy, Condition: OrderConditionNormal,
+		AccountID: "1", OrderbookID: "1", Price: decimal.NewFromInt(1), Volume: 1, Side: OrderSideBuy, Condition: OrderConditionNormal,
 	})
 	if err == nil {
 		t.Fatal("expected error, got nil")
@@ -121,17 +122,44 @@ func TestPlaceOrder_ErrorStatus(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(PlaceOrderResponse{
 			OrderRequestStatus: OrderRequestStatusError,
-			Message:            "insufficient funds",
+			Message:            "order.price.outside.limits",
+			Parameters:         []string{"90.5", "110.5"},
+			OrderID:            "42",
 		})
 	}))
 	defer server.Close()
 
+	svc := NewService(newTestClient(server.URL))
+	resp, err := svc.PlaceOrder(context.Background(), &PlaceOrderRequest{
+		AccountID: "1", OrderbookID: "1", Price: decimal.NewFromInt(1), Volume: 1, Side: OrderSideBuy, Condition: OrderConditionNormal,
+	})
+	var rejected *OrderRejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("expected *OrderRejectedError, got %T: %v", err, err)
+	}
+	if rejected.Message != "order.price.outside.limits" || rejected.OrderID != "42" || len(rejected.Parameters) != 2 {
+		t.Errorf("OrderRejectedError = %+v", rejected)
+	}
+	if got, want := err.Error(), "place order rejected: order.price.outside.limits (90.5, 110.5)"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if resp == nil || resp.OrderRequestStatus != OrderRequestStatusError {
+		t.Error("expected response to be returned with the error")
+	}
+}
+
+func TestPlaceOrder_SessionExpired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
 	svc := NewService(newTestClient(server.URL))
 	_, err := svc.PlaceOrder(context.Background(), &PlaceOrderRequest{
-		AccountID: "1", OrderbookID: "1", Price: 1, Volume: 1, Side: OrderSideBuy, Condition: OrderConditionNormal,
+		AccountID: "1", OrderbookID: "1", Price: decimal.NewFromInt(1), Volume: 1, Side: OrderSideBuy, Condition: OrderConditionNormal,
 	})
-	if err == nil {
-		t.Fatal("expected error for ERROR status, got nil")
+	if !errors.Is(err, client.ErrSessionExpired) {
+		t.Errorf("err = %v, want client.ErrSessionExpired", err)
 	}
 }
 
@@ -153,8 +181,8 @@ func TestModifyOrder_Success(t *testing.T) {
 		if req.OrderID != "999" {
 			t.Errorf("orderId = %q, want %q", req.OrderID, "999")
 		}
-		if req.Price != 335.0 {
-			t.Errorf("price = %f, want 335.0", req.Price)
+		if !req.Price.Equal(decimal.MustParse("335.0")) {
+			t.Errorf("price = %s, want 335.0", req.Price)
 		}
 
 		w.WriteHeader(http.StatusOK)
@@ -169,7 +197,7 @@ func TestModifyOrder_Success(t *testing.T) {
 	resp, err := svc.ModifyOrder(context.Background(), &ModifyOrderRequest{
 		OrderID:   "999",
 		AccountID: "12345",
-		Price:     335.0,
+		Price:     decimal.MustParse("335.0"),
 		Volume:    10,
 	})
 	if err != nil {
@@ -187,10 +215,10 @@ func TestModifyOrder_ValidationErrors(t *testing.T) {
 		name string
 		req  *ModifyOrderRequest
 	}{
-		{"empty orderId", &ModifyOrderRequest{AccountID: "1", Price: 1, Volume: 1}},
-		{"empty accountId", &ModifyOrderRequest{OrderID: "1", Price: 1, Volume: 1}},
-		{"zero price", &ModifyOrderRequest{OrderID: "1", AccountID: "1", Price: 0, Volume: 1}},
-		{"zero volume", &ModifyOrderRequest{OrderID: "1", AccountID: "1", Price: 1, Volume: 0}},
+		{"empty orderId", &ModifyOrderRequest{AccountID: "1", Price: decimal.NewFromInt(1), Volume: 1}},
+		{"empty accountId", &ModifyOrderRequest{OrderID: "1", Price: decimal.NewFromInt(1), Volume: 1}},
+		{"zero price", &ModifyOrderRequest{OrderID: "1", AccountID: "1", Price: decimal.NewFromInt(0), Volume: 1}},
+		{"zero volume", &ModifyOrderRequest{OrderID: "1", AccountID: "1", Price: decimal.NewFromInt(1), Volume: 0}},
 	}
 
 	for _, tt := range tests {
@@ -215,10 +243,11 @@ func TestModifyOrder_ErrorStatus(t *testing.T) {
 
 	svc := NewService(newTestClient(server.URL))
 	_, err := svc.ModifyOrder(context.Background(), &ModifyOrderRequest{
-		OrderID: "1", AccountID: "1", Price: 1, Volume: 1,
+		OrderID: "1", AccountID: "1", Price: decimal.NewFromInt(1), Volume: 1,
 	})
-	if err == nil {
-		t.Fatal("expected error for ERROR status, got nil")
+	var rejected *OrderRejectedError
+	if !errors.As(err, &rejected) || rejected.Op != "modify order" {
+		t.Fatalf("expected modify order *OrderRejectedError, got %v", err)
 	}
 }
 
@@ -300,8 +329,9 @@ func TestDeleteOrder_ErrorStatus(t *testing.T) {
 	_, err := svc.DeleteOrder(context.Background(), &DeleteOrderRequest{
 		AccountID: "1", OrderID: "1",
 	})
-	if err == nil {
-		t.Fatal("expected error for ERROR status, got nil")
+	var rejected *OrderRejectedError
+	if !errors.As(err, &rejected) || rejected.Message != "order already deleted" {
+		t.Fatalf("expected *OrderRejectedError, got %v", err)
 	}
 }
 
@@ -329,7 +359,7 @@ func TestGetOrder_Success(t *testing.T) {
 			Side:            OrderSideBuy,
 			State:           "ACTIVE",
 			MarketReference: "1009",
-			Price:           330.0,
+			Price:           decimal.MustParse("330.0"),
 			Volume:          10,
 			OriginalVolume:  10,
 			AccountID:       "84039",
@@ -358,8 +388,8 @@ func TestGetOrder_Success(t *testing.T) {
 	if resp.State != "ACTIVE" {
 		t.Errorf("State = %q, want %q", resp.State, "ACTIVE")
 	}
-	if resp.Price != 330.0 {
-		t.Errorf("Price = %f, want 330.0", resp.Price)
+	if !resp.Price.Equal(decimal.MustParse("330.0")) {
+		t.Errorf("Price = %s, want 330.0", resp.Price)
 	}
 	if !resp.Modifiable {
 		t.Error("Modifiable = false, want true")
@@ -424,8 +454,8 @@ func TestGetOrders_Success(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(GetOrdersResponse{
 			Orders: []Order{
-				{OrderID: "111", Side: OrderSideBuy, Price: 100.0},
-				{OrderID: "222", Side: OrderSideSell, Price: 200.0},
+				{OrderID: "111", Side: OrderSideBuy, Price: decimal.MustParse("100.0")},
+				{OrderID: "222", Side: OrderSideSell, Price: decimal.MustParse("200.0")},
 			},
 		})
 	}))
@@ -471,6 +501,13 @@ func TestValidateOrder_Success(t *testing.T) {
 		if r.URL.Path != "/_api/trading-critical/rest/order/validation/validate" {
 			t.Errorf("path = %s, want /_api/trading-critical/rest/order/validation/validate", r.URL.Path)
 		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		if v, ok := body["orderRequestParameters"]; !ok || v != nil {
+			t.Errorf("orderRequestParameters = %v (sent %v), want null", v, ok)
+		}
 
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(ValidateOrderResponse{
@@ -484,7 +521,7 @@ func TestValidateOrder_Success(t *testing.T) {
 	resp, err := svc.ValidateOrder(context.Background(), &ValidateOrderRequest{
 		AccountID:   "1",
 		OrderbookID: "5246",
-		Price:       100.0,
+		Price:       decimal.MustParse("100.0"),
 		Volume:      10,
 		Side:        OrderSideBuy,
 		Condition:   OrderConditionNormal,
@@ -503,7 +540,7 @@ func TestValidateOrder_Success(t *testing.T) {
 func TestValidateOrder_ValidationErrors(t *testing.T) {
 	svc := NewService(newTestClient("http://localhost"))
 	base := ValidateOrderRequest{
-		AccountID: "1", OrderbookID: "1", Price: 1, Volume: 1,
+		AccountID: "1", OrderbookID: "1", Price: decimal.NewFromInt(1), Volume: 1,
 		Side: OrderSideBuy, Condition: OrderConditionNormal,
 		ISIN: "X", Currency: "SEK", MarketPlace: "XSTO",
 	}
@@ -514,7 +551,7 @@ func TestValidateOrder_ValidationErrors(t *testing.T) {
 	}{
 		{"empty accountId", func(r *ValidateOrderRequest) { r.AccountID = "" }},
 		{"empty orderbookId", func(r *ValidateOrderRequest) { r.OrderbookID = "" }},
-		{"zero price", func(r *ValidateOrderRequest) { r.Price = 0 }},
+		{"zero price", func(r *ValidateOrderRequest) { r.Price = decimal.NewFromInt(0) }},
 		{"zero volume", func(r *ValidateOrderRequest) { r.Volume = 0 }},
 		{"invalid side", func(r *ValidateOrderRequest) { r.Side = "INVALID" }},
 		{"invalid condition", func(r *ValidateOrderRequest) { r.Condition = "INVALID" }},
@@ -548,9 +585,9 @@ func TestGetPreliminaryFee_Success(t *testing.T) {
 
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(PreliminaryFeeResponse{
-			Commission: "39",
-			TotalFees:  "39",
-			TotalSum:   "3039",
+			Commission: decimal.MustParse("39"),
+			TotalFees:  decimal.MustParse("39"),
+			TotalSum:   decimal.MustParse("3039"),
 		})
 	}))
 	defer server.Close()
@@ -566,7 +603,7 @@ func TestGetPreliminaryFee_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetPreliminaryFee failed: %v", err)
 	}
-	if resp.TotalFees != "39" {
+	if !resp.TotalFees.Equal(decimal.MustParse("39")) {
 		t.Errorf("TotalFees = %q, want %q", resp.TotalFees, "39")
 	}
 }
@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/vmorsell/avanza-sdk-go/client"
 	"github.com/vmorsell/avanza-sdk-go/internal/sse"
 )
 
 // OrdersSubscription represents an active orders subscription.
 type OrdersSubscription struct {
 	sub       *sse.Subscription
+	client    *client.Client
+	endpoint  string
 	events    chan OrderEvent
 	errors    chan error
 	done      chan struct{}
@@ -29,6 +32,12 @@ func (s *OrdersSubscription) Errors() <-chan error {
 	return s.errors
 }
 
+// Connected returns a channel that is closed once the subscription has
+// connected, after which no event is missed.
+func (s *OrdersSubscription) Connected() <-chan struct{} {
+	return s.sub.Connected()
+}
+
 // Close stops the subscription and cleans up resources.
 // Always call Close() when done with the subscription to prevent resource leaks.
 func (s *OrdersSubscription) Close() {
@@ -37,12 +46,14 @@ func (s *OrdersSubscription) Close() {
 	s.wg.Wait()
 }
 
-func newOrdersSubscription(sub *sse.Subscription) *OrdersSubscription {
+func newOrdersSubscription(sub *sse.Subscription, c *client.Client, endpoint string) *OrdersSubscription {
 	s := &OrdersSubscription{
-		sub:    sub,
-		events: make(chan OrderEvent, 100),
-		errors: make(chan error, 10),
-		done:   make(chan struct{}),
+		sub:      sub,
+		client:   c,
+		endpoint: endpoint,
+		events:   make(chan OrderEvent, 100),
+		errors:   make(chan error, 10),
+		done:     make(chan struct{}),
 	}
 	s.wg.Add(1)
 	go s.run()
@@ -92,6 +103,7 @@ func (s *OrdersSubscription) forwardEvent(raw sse.RawEvent) {
 		}
 		return
 	}
+	s.client.CheckDrift(s.endpoint, raw.Data, &data)
 
 	s.trySendEvent(OrderEvent{
 		Event: raw.Event,
@@ -113,6 +125,8 @@ func (s *OrdersSubscription) trySendEvent(event OrderEvent) {
 // StopLossSubscription represents an active stop loss subscription.
 type StopLossSubscription struct {
 	sub       *sse.Subscription
+	client    *client.Client
+	endpoint  string
 	events    chan StopLossEvent
 	errors    chan error
 	done      chan struct{}
@@ -130,6 +144,12 @@ func (s *StopLossSubscription) Errors() <-chan error {
 	return s.errors
 }
 
+// Connected returns a channel that is closed once the subscription has
+// connected, after which no event is missed.
+func (s *StopLossSubscription) Connected() <-chan struct{} {
+	return s.sub.Connected()
+}
+
 // Close stops the subscription and cleans up resources.
 // Always call Close() when done with the subscription to prevent resource leaks.
 func (s *StopLossSubscription) Close() {
@@ -138,12 +158,14 @@ func (s *StopLossSubscription) Close() {
 	s.wg.Wait()
 }
 
-func newStopLossSubscription(sub *sse.Subscription) *StopLossSubscription {
+func newStopLossSubscription(sub *sse.Subscription, c *client.Client, endpoint string) *StopLossSubscription {
 	s := &StopLossSubscription{
-		sub:    sub,
-		events: make(chan StopLossEvent, 100),
-		errors: make(chan error, 10),
-		done:   make(chan struct{}),
+		sub:      sub,
+		client:   c,
+		endpoint: endpoint,
+		events:   make(chan StopLossEvent, 100),
+		errors:   make(chan error, 10),
+		done:     make(chan struct{}),
 	}
 	s.wg.Add(1)
 	go s.run()
@@ -193,6 +215,7 @@ func (s *StopLossSubscription) forwardEvent(raw sse.RawEvent) {
 		}
 		return
 	}
+	s.client.CheckDrift(s.endpoint, raw.Data, &data)
 
 	s.trySendEvent(StopLossEvent{
 		Event: raw.Event,
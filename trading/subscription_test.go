@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 )
 
 func writeSSEEvent(w http.ResponseWriter, id, event, data string) {
@@ -76,8 +77,8 @@ func TestOrdersSubscription_ReceivesEvents(t *testing.T) {
 		if e.Data.Type != OrderSideBuy {
 			t.Errorf("type = %q, want BUY", e.Data.Type)
 		}
-		if e.Data.Price != 90 {
-			t.Errorf("price = %f, want 90", e.Data.Price)
+		if !e.Data.Price.Equal(decimal.MustParse("90")) {
+			t.Errorf("price = %s, want 90", e.Data.Price)
 		}
 	case <-time.After(5 * time.Second):
 		t.Fatal("timed out waiting for event")
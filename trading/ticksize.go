@@ -0,0 +1,57 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/market"
+)
+
+// WithTickSizeCheck makes PlaceOrder and ModifyOrder check the price against
+// the orderbook's tick size table before sending the order. An off-tick price
+// fails with *TickSizeError instead of being rejected by Avanza. Orderbooks
+// without a tick size table are not checked.
+//
+//	svc := trading.NewService(c, trading.WithTickSizeCheck(marketSvc))
+func WithTickSizeCheck(orderbooks OrderbookSource) Option {
+	return func(s *Service) {
+		s.orderbooks = orderbooks
+		s.tickSizeCheck = true
+	}
+}
+
+// checkTickSize returns a *TickSizeError if price is not a valid tick of
+// orderbookID. It does nothing unless WithTickSizeCheck is set.
+func (s *Service) checkTickSize(ctx context.Context, orderbookID string, price decimal.Decimal) error {
+	if !s.tickSizeCheck {
+		return nil
+	}
+	ob, err := s.orderbooks.GetOrderbook(ctx, orderbookID)
+	if err != nil {
+		return fmt.Errorf("get orderbook for tick size check: %w", err)
+	}
+	return tickSizeError(orderbookID, ob.TickSizeList, price)
+}
+
+// tickSizeError returns a *TickSizeError if price is not a valid tick in
+// ticks. An empty table accepts every price.
+func tickSizeError(orderbookID string, ticks market.TickSizeList, price decimal.Decimal) error {
+	if len(ticks.TickSizeEntries) == 0 || ticks.IsValid(price) {
+		return nil
+	}
+
+	tick, err := ticks.Tick(price)
+	if err != nil {
+		return fmt.Errorf("check tick size: %w", err)
+	}
+	below, err := ticks.RoundDown(price)
+	if err != nil {
+		return fmt.Errorf("check tick size: %w", err)
+	}
+	above, err := ticks.RoundUp(price)
+	if err != nil {
+		return fmt.Errorf("check tick size: %w", err)
+	}
+	return &TickSizeError{OrderbookID: orderbookID, Price: price, Tick: tick, Below: below, Above: above}
+}
@@ -0,0 +1,112 @@
+package trading
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/market"
+)
+
+type fakeOrderbooks map[string]*market.Orderbook
+
+func (f fakeOrderbooks) GetOrderbook(_ context.Context, orderbookID string) (*market.Orderbook, error) {
+	ob, ok := f[orderbookID]
+	if !ok {
+		return nil, errors.New("unknown orderbook")
+	}
+	return ob, nil
+}
+
+var testOrderbooks = fakeOrderbooks{
+	"5247": {ID: "5247", TickSizeList: market.TickSizeList{TickSizeEntries: []market.TickSizeEntry{
+		{Min: decimal.MustParse("0"), Max: decimal.MustParse("99.99"), Tick: decimal.MustParse("0.01")},
+		{Min: decimal.MustParse("100"), Max: decimal.MustParse("499.95"), Tick: decimal.MustParse("0.05")},
+	}}},
+	"fund": {ID: "fund"},
+}
+
+func TestPlaceOrder_TickSizeCheck(t *testing.T) {
+	var placed int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		placed++
+		_ = json.NewEncoder(w).Encode(PlaceOrderResponse{OrderRequestStatus: OrderRequestStatusSuccess, OrderID: "1"})
+	}))
+	defer server.Close()
+
+	svc := NewService(newTestClient(server.URL), WithTickSizeCheck(testOrderbooks))
+	req := func(orderbookID, price string) *PlaceOrderRequest {
+		return &PlaceOrderRequest{
+			AccountID:   "12345",
+			OrderbookID: orderbookID,
+			Price:       decimal.MustParse(price),
+			Volume:      10,
+			Side:        OrderSideBuy,
+			Condition:   OrderConditionNormal,
+		}
+	}
+
+	_, err := svc.PlaceOrder(context.Background(), req("5247", "245.52"))
+	var tickErr *TickSizeError
+	if !errors.As(err, &tickErr) {
+		t.Fatalf("err = %v, want *TickSizeError", err)
+	}
+	if tickErr.Tick.String() != "0.05" || tickErr.Below.String() != "245.50" || tickErr.Above.String() != "245.55" {
+		t.Errorf("TickSizeError = %+v, want tick 0.05 between 245.50 and 245.55", tickErr)
+	}
+	if placed != 0 {
+		t.Errorf("orders sent = %d, want 0", placed)
+	}
+
+	for _, r := range []*PlaceOrderRequest{req("5247", "245.55"), req("fund", "101.234")} {
+		if _, err := svc.PlaceOrder(context.Background(), r); err != nil {
+			t.Errorf("PlaceOrder(%s @ %s): %v", r.OrderbookID, r.Price, err)
+		}
+	}
+	if placed != 2 {
+		t.Errorf("orders sent = %d, want 2", placed)
+	}
+
+	if _, err := svc.PlaceOrder(context.Background(), req("unknown", "10")); err == nil {
+		t.Error("unknown orderbook: want an error")
+	}
+}
+
+func TestModifyOrder_TickSizeCheck(t *testing.T) {
+	var modified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_api/trading-critical/rest/order/find":
+			_ = json.NewEncoder(w).Encode(GetOrderResponse{OrderID: "999", OrderbookID: "5247"})
+		case "/_api/trading-critical/rest/order/modify":
+			modified++
+			_ = json.NewEncoder(w).Encode(ModifyOrderResponse{OrderRequestStatus: OrderRequestStatusSuccess, OrderID: "999"})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	svc := NewService(newTestClient(server.URL), WithTickSizeCheck(testOrderbooks))
+	req := &ModifyOrderRequest{OrderID: "999", AccountID: "12345", Price: decimal.MustParse("99.995"), Volume: 10}
+
+	var tickErr *TickSizeError
+	if _, err := svc.ModifyOrder(context.Background(), req); !errors.As(err, &tickErr) {
+		t.Fatalf("err = %v, want *TickSizeError", err)
+	}
+	if tickErr.Below.String() != "99.99" || tickErr.Above.String() != "100" {
+		t.Errorf("nearest = %s and %s, want 99.99 and 100", tickErr.Below, tickErr.Above)
+	}
+
+	req.Price = tickErr.Above
+	if _, err := svc.ModifyOrder(context.Background(), req); err != nil {
+		t.Fatalf("ModifyOrder: %v", err)
+	}
+	if modified != 1 {
+		t.Errorf("modifications sent = %d, want 1", modified)
+	}
+}
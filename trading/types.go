@@ -1,6 +1,14 @@
 // Package trading provides trading functionality for the Avanza API.
 package trading
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+	"github.com/vmorsell/avanza-sdk-go/market"
+)
+
 // OrderSide indicates whether to buy or sell.
 type OrderSide string
 
@@ -33,26 +41,45 @@ const (
 	StopLossStatusError   StopLossStatus = "ERROR"   // Stop loss order placement failed
 )
 
+// OrderEntryMode identifies the order form an order was entered through.
+type OrderEntryMode string
+
+const (
+	OrderEntryModeAdvanced OrderEntryMode = "ADVANCED" // The full order form, as sent by the web app
+)
+
 // OrderMetadata contains order entry details.
 type OrderMetadata struct {
-	OrderEntryMode  string `json:"orderEntryMode"`
-	HasTouchedPrice string `json:"hasTouchedPrice"`
+	OrderEntryMode  OrderEntryMode `json:"orderEntryMode"`
+	HasTouchedPrice bool           `json:"hasTouchedPrice,string"`
 }
 
 // PlaceOrderRequest contains all parameters needed to place an order.
+// NewLimitOrder builds one with the request ID and metadata filled in.
+// A nil OpenVolume shows the whole volume and a nil ValidUntil makes a day
+// order.
 type PlaceOrderRequest struct {
-	IsDividendReinvestment bool           `json:"isDividendReinvestment"`
-	RequestID              string         `json:"requestId"`
-	OrderRequestParameters any            `json:"orderRequestParameters"`
-	Price                  float64        `json:"price"`
-	Volume                 int            `json:"volume"`
-	OpenVolume             any            `json:"openVolume"`
-	AccountID              string         `json:"accountId"`
-	Side                   OrderSide      `json:"side"`
-	OrderbookID            string         `json:"orderbookId"`
-	ValidUntil             any            `json:"validUntil"`
-	Metadata               OrderMetadata  `json:"metadata"`
-	Condition              OrderCondition `json:"condition"`
+	IsDividendReinvestment bool            `json:"isDividendReinvestment"`
+	RequestID              string          `json:"requestId"`
+	Price                  decimal.Decimal `json:"price"`
+	Volume                 int             `json:"volume"`
+	OpenVolume             *int            `json:"openVolume"`
+	AccountID              string          `json:"accountId"`
+	Side                   OrderSide       `json:"side"`
+	OrderbookID            string          `json:"orderbookId"`
+	ValidUntil             *Date           `json:"validUntil"`
+	Metadata               OrderMetadata   `json:"metadata"`
+	Condition              OrderCondition  `json:"condition"`
+}
+
+// MarshalJSON encodes r with orderRequestParameters, the extra parameters of
+// special order types, set to null as for the limit orders this SDK places.
+func (r PlaceOrderRequest) MarshalJSON() ([]byte, error) {
+	type request PlaceOrderRequest
+	return json.Marshal(struct {
+		request
+		OrderRequestParameters *struct{} `json:"orderRequestParameters"`
+	}{request: request(r)})
 }
 
 // PlaceOrderResponse contains the result of placing an order.
@@ -80,13 +107,13 @@ type DeleteOrderResponse struct {
 
 // ModifyOrderRequest contains parameters needed to modify an existing order.
 type ModifyOrderRequest struct {
-	OrderID    string  `json:"orderId"`
-	Price      float64 `json:"price"`
-	Volume     int     `json:"volume"`
-	OpenVolume any     `json:"openVolume"`
-	AccountID  string  `json:"accountId"`
-	ValidUntil any     `json:"validUntil"`
-	Metadata   any     `json:"metadata"`
+	OrderID    string          `json:"orderId"`
+	Price      decimal.Decimal `json:"price"`
+	Volume     int             `json:"volume"`
+	OpenVolume *int            `json:"openVolume"`
+	AccountID  string          `json:"accountId"`
+	ValidUntil *Date           `json:"validUntil"`
+	Metadata   *OrderMetadata  `json:"metadata"`
 }
 
 // ModifyOrderResponse contains the result of modifying an order.
@@ -123,25 +150,25 @@ type OrderOrderbook struct {
 
 // Order represents an active or completed order.
 type Order struct {
-	Account              OrderAccount   `json:"account"`
-	OrderID              string         `json:"orderId"`
-	Volume               int            `json:"volume"`
-	OriginalVolume       int            `json:"originalVolume"`
-	Price                float64        `json:"price"`
-	Amount               float64        `json:"amount"`
-	OrderbookID          string         `json:"orderbookId"`
-	Side                 OrderSide      `json:"side"`
-	ValidUntil           string         `json:"validUntil"`
-	Created              string         `json:"created"`
-	Deletable            bool           `json:"deletable"`
-	Modifiable           bool           `json:"modifiable"`
-	Message              string         `json:"message"`
-	State                string         `json:"state"`
-	StateText            string         `json:"stateText"`
-	StateMessage         string         `json:"stateMessage"`
-	Orderbook            OrderOrderbook `json:"orderbook"`
-	AdditionalParameters map[string]any `json:"additionalParameters"`
-	Condition            OrderCondition `json:"condition"`
+	Account              OrderAccount    `json:"account"`
+	OrderID              string          `json:"orderId"`
+	Volume               int             `json:"volume"`
+	OriginalVolume       int             `json:"originalVolume"`
+	Price                decimal.Decimal `json:"price"`
+	Amount               decimal.Decimal `json:"amount"`
+	OrderbookID          string          `json:"orderbookId"`
+	Side                 OrderSide       `json:"side"`
+	ValidUntil           string          `json:"validUntil"`
+	Created              string          `json:"created"`
+	Deletable            bool            `json:"deletable"`
+	Modifiable           bool            `json:"modifiable"`
+	Message              string          `json:"message"`
+	State                string          `json:"state"`
+	StateText            string          `json:"stateText"`
+	StateMessage         string          `json:"stateMessage"`
+	Orderbook            OrderOrderbook  `json:"orderbook"`
+	AdditionalParameters map[string]any  `json:"additionalParameters"`
+	Condition            OrderCondition  `json:"condition"`
 }
 
 // GetOrderRequest contains parameters needed to get a single order.
@@ -152,20 +179,20 @@ type GetOrderRequest struct {
 
 // GetOrderResponse contains a single order returned by the find endpoint.
 type GetOrderResponse struct {
-	OrderID         string         `json:"orderId"`
-	OrderbookID     string         `json:"orderbookId"`
-	Side            OrderSide      `json:"side"`
-	State           string         `json:"state"`
-	MarketReference string         `json:"marketReference"`
-	Price           float64        `json:"price"`
-	Message         string         `json:"message"`
-	Volume          int            `json:"volume"`
-	OriginalVolume  int            `json:"originalVolume"`
-	AccountID       string         `json:"accountId"`
-	Condition       OrderCondition `json:"condition"`
-	ValidUntil      string         `json:"validUntil"`
-	Modifiable      bool           `json:"modifiable"`
-	Deletable       bool           `json:"deletable"`
+	OrderID         string          `json:"orderId"`
+	OrderbookID     string          `json:"orderbookId"`
+	Side            OrderSide       `json:"side"`
+	State           string          `json:"state"`
+	MarketReference string          `json:"marketReference"`
+	Price           decimal.Decimal `json:"price"`
+	Message         string          `json:"message"`
+	Volume          int             `json:"volume"`
+	OriginalVolume  int             `json:"originalVolume"`
+	AccountID       string          `json:"accountId"`
+	Condition       OrderCondition  `json:"condition"`
+	ValidUntil      string          `json:"validUntil"`
+	Modifiable      bool            `json:"modifiable"`
+	Deletable       bool            `json:"deletable"`
 }
 
 // GetOrdersResponse contains all orders for the authenticated user.
@@ -177,21 +204,30 @@ type GetOrdersResponse struct {
 
 // ValidateOrderRequest contains order parameters to validate before placing.
 type ValidateOrderRequest struct {
-	IsDividendReinvestment bool           `json:"isDividendReinvestment"`
-	RequestID              *string        `json:"requestId"`
-	OrderRequestParameters any            `json:"orderRequestParameters"`
-	Price                  float64        `json:"price"`
-	Volume                 int            `json:"volume"`
-	OpenVolume             any            `json:"openVolume"`
-	AccountID              string         `json:"accountId"`
-	Side                   OrderSide      `json:"side"`
-	OrderbookID            string         `json:"orderbookId"`
-	ValidUntil             any            `json:"validUntil"`
-	Metadata               any            `json:"metadata"`
-	Condition              OrderCondition `json:"condition"`
-	ISIN                   string         `json:"isin"`
-	Currency               string         `json:"currency"`
-	MarketPlace            string         `json:"marketPlace"`
+	IsDividendReinvestment bool            `json:"isDividendReinvestment"`
+	RequestID              *string         `json:"requestId"`
+	Price                  decimal.Decimal `json:"price"`
+	Volume                 int             `json:"volume"`
+	OpenVolume             *int            `json:"openVolume"`
+	AccountID              string          `json:"accountId"`
+	Side                   OrderSide       `json:"side"`
+	OrderbookID            string          `json:"orderbookId"`
+	ValidUntil             *Date           `json:"validUntil"`
+	Metadata               *OrderMetadata  `json:"metadata"`
+	Condition              OrderCondition  `json:"condition"`
+	ISIN                   string          `json:"isin"`
+	Currency               string          `json:"currency"`
+	MarketPlace            string          `json:"marketPlace"`
+}
+
+// MarshalJSON encodes r with orderRequestParameters set to null, as
+// PlaceOrderRequest does.
+func (r ValidateOrderRequest) MarshalJSON() ([]byte, error) {
+	type request ValidateOrderRequest
+	return json.Marshal(struct {
+		request
+		OrderRequestParameters *struct{} `json:"orderRequestParameters"`
+	}{request: request(r)})
 }
 
 // ValidateOrderResponse contains validation results for various checks.
@@ -210,6 +246,56 @@ type ValidationResult struct {
 	Valid bool `json:"valid"`
 }
 
+// OrderWarning names a ValidateOrderResponse check that did not pass. The
+// values are the check's JSON field names.
+type OrderWarning string
+
+const (
+	OrderWarningCommission      OrderWarning = "commissionWarning"      // Commission is high relative to the order value
+	OrderWarningEmployee        OrderWarning = "employeeValidation"     // Order conflicts with employee trading rules
+	OrderWarningLargeInScale    OrderWarning = "largeInScaleWarning"    // Order is large relative to the market
+	OrderWarningOrderValueLimit OrderWarning = "orderValueLimitWarning" // Order value exceeds the account's limit
+	OrderWarningPriceRamping    OrderWarning = "priceRampingWarning"    // Price is far from the market price
+	OrderWarningCanadaOddLot    OrderWarning = "canadaOddLotWarning"    // Volume is not a board lot on a Canadian market
+)
+
+// OrderPreview combines validation, fees, instrument capabilities and buying
+// power for an order that has not been placed yet.
+type OrderPreview struct {
+	// Warnings are the validation checks that did not pass, empty if none.
+	Warnings []OrderWarning
+
+	// Currency is the orderbook currency, which the fees and sums are in.
+	Currency            string
+	Commission          decimal.Decimal
+	MarketFees          decimal.Decimal
+	TotalFees           decimal.Decimal
+	TotalSum            decimal.Decimal
+	TotalSumWithoutFees decimal.Decimal
+
+	// CurrencyExchangeRate is the currency exchange fee in percent, and
+	// CurrencyExchangeCost what it amounts to in Currency. Both are 0 for
+	// orders in SEK.
+	CurrencyExchangeRate decimal.Decimal
+	CurrencyExchangeCost decimal.Decimal
+
+	// ConditionAllowed reports whether the orderbook supports the order's
+	// condition. Fill-or-kill needs FeatureSupport.FillAndOrKill.
+	ConditionAllowed bool
+
+	// BuyingPower is the account's buying power in SEK before the order.
+	BuyingPower decimal.Decimal
+
+	// BuyingPowerAfter is BuyingPower less the cost of a buy order, fees and
+	// currency exchange included. A sell order leaves it unchanged. It is nil
+	// for buy orders in other currencies than SEK, whose cost in SEK is not
+	// known before the order executes.
+	BuyingPowerAfter *decimal.Decimal
+
+	// Orderbook holds the instrument's trading rules.
+	Orderbook *market.Orderbook
+}
+
 // PreliminaryFeeRequest contains order parameters to calculate fees.
 type PreliminaryFeeRequest struct {
 	AccountID   string    `json:"accountId"`
@@ -220,23 +306,24 @@ type PreliminaryFeeRequest struct {
 }
 
 // PreliminaryFeeResponse contains fee calculations for an order.
-// All monetary values are strings in the orderbook currency.
+// The API sends monetary values as strings in the orderbook currency; they are
+// parsed into exact decimals.
 type PreliminaryFeeResponse struct {
-	Commission          string              `json:"commission"`
-	MarketFees          string              `json:"marketFees"`
-	TotalFees           string              `json:"totalFees"`
-	TotalSum            string              `json:"totalSum"`
-	TotalSumWithoutFees string              `json:"totalSumWithoutFees"`
+	Commission          decimal.Decimal     `json:"commission"`
+	MarketFees          decimal.Decimal     `json:"marketFees"`
+	TotalFees           decimal.Decimal     `json:"totalFees"`
+	TotalSum            decimal.Decimal     `json:"totalSum"`
+	TotalSumWithoutFees decimal.Decimal     `json:"totalSumWithoutFees"`
 	OrderbookCurrency   string              `json:"orderbookCurrency"`
-	TransactionTax      *string             `json:"transactionTax"`
+	TransactionTax      *decimal.Decimal    `json:"transactionTax"`
 	CurrencyExchangeFee CurrencyExchangeFee `json:"currencyExchangeFee"`
 	Campaign            *string             `json:"campaign"`
 }
 
 // CurrencyExchangeFee contains exchange rate and fee for currency conversion.
 type CurrencyExchangeFee struct {
-	Rate string `json:"rate"`
-	Sum  string `json:"sum"`
+	Rate decimal.Decimal `json:"rate"`
+	Sum  decimal.Decimal `json:"sum"`
 }
 
 // StopLossTriggerType determines when the stop loss triggers.
@@ -490,14 +577,14 @@ type OrderEventData struct {
 	CurrentVolume        float64             `json:"currentVolume"`
 	OriginalVolume       float64             `json:"originalVolume"`
 	OpenVolume           *float64            `json:"openVolume"`
-	Price                float64             `json:"price"`
+	Price                decimal.Decimal     `json:"price"`
 	ValidDate            *string             `json:"validDate"`
 	Type                 OrderSide           `json:"type"`
 	State                OrderEventState     `json:"state"`
 	Action               OrderAction         `json:"action"`
 	Modifiable           bool                `json:"modifiable"`
 	Deletable            bool                `json:"deletable"`
-	Sum                  float64             `json:"sum"`
+	Sum                  decimal.Decimal     `json:"sum"`
 	VisibleDate          *string             `json:"visibleDate"`
 	OrderDateTime        int64               `json:"orderDateTime"`
 	EventTimeStamp       int64               `json:"eventTimeStamp"`
@@ -514,3 +601,45 @@ type OrderEvent struct {
 	ID    string         `json:"id"`
 	Retry int            `json:"retry"`
 }
+
+// OrderOutcomeState is where an order stands when PlaceOrderAndWait returns.
+type OrderOutcomeState string
+
+const (
+	OrderOutcomeFilled          OrderOutcomeState = "FILLED"           // The whole volume filled
+	OrderOutcomePartiallyFilled OrderOutcomeState = "PARTIALLY_FILLED" // Some volume filled and the rest is still open
+	OrderOutcomeDeleted         OrderOutcomeState = "DELETED"          // Deleted, killed or expired; FilledVolume may be above 0
+	OrderOutcomeOpen            OrderOutcomeState = "OPEN"             // Still open with nothing filled
+)
+
+// WaitOptions configures PlaceOrderAndWait. The zero value waits until the
+// order is filled or deleted.
+type WaitOptions struct {
+	// ReturnOnFill returns as soon as any volume has filled instead of
+	// waiting for the rest of the order.
+	ReturnOnFill bool
+
+	// PollInterval is how often GetOrder is polled when the orders stream
+	// did not connect or has stopped. Defaults to 2 seconds.
+	PollInterval time.Duration
+
+	// ConnectTimeout is how long to wait for the orders stream to connect
+	// before placing the order and polling instead. Defaults to 10 seconds.
+	ConnectTimeout time.Duration
+}
+
+// OrderOutcome is the result of PlaceOrderAndWait.
+type OrderOutcome struct {
+	OrderID         string
+	State           OrderOutcomeState
+	FilledVolume    int
+	RemainingVolume int // Unfilled volume; for a deleted order, the volume that never filled
+
+	// FilledValue is the sum of volume times price over the order's trades,
+	// and AveragePrice is FilledValue / FilledVolume with the decimals of the
+	// prices. Both come from the account's transactions and are zero when
+	// nothing filled, the trades are not booked yet, or trades of another
+	// order on the same orderbook and side cannot be told apart.
+	FilledValue  decimal.Decimal
+	AveragePrice decimal.Decimal
+}
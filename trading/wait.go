@@ -0,0 +1,255 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/accounts"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+)
+
+const (
+	// defaultPollInterval is how often PlaceOrderAndWait polls GetOrder when
+	// it cannot rely on the orders stream.
+	defaultPollInterval = 2 * time.Second
+
+	// defaultConnectTimeout is how long PlaceOrderAndWait waits for the
+	// orders stream to connect before placing the order anyway.
+	defaultConnectTimeout = 10 * time.Second
+)
+
+// PlaceOrderAndWait places req and blocks until the order is filled or
+// deleted, following it on the orders stream. It places the order only once
+// the stream has connected, so no event of the order is missed. If the
+// stream does not connect within the connect timeout, or stops later, it
+// polls GetOrder instead. Once the order has filled, the average price is
+// worked out from the trades booked in the account's transactions since it
+// was placed. opts may be nil.
+//
+// A rejected order fails with *OrderRejectedError as from PlaceOrder, and a
+// failed GetOrder poll or transactions lookup ends the wait with its error.
+// In both that case and when ctx ends first, the outcome so far is returned
+// together with the error; the order is left as is.
+//
+//	outcome, err := c.Trading.PlaceOrderAndWait(ctx, req, nil)
+//	if err == nil && outcome.State == trading.OrderOutcomeFilled {
+//	    fmt.Printf("bought %d at %s\n", outcome.FilledVolume, outcome.AveragePrice)
+//	}
+func (s *Service) PlaceOrderAndWait(ctx context.Context, req *PlaceOrderRequest, opts *WaitOptions) (*OrderOutcome, error) {
+	if err := validatePlaceOrder(req); err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &WaitOptions{}
+	}
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	connectTimeout := opts.ConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = defaultConnectTimeout
+	}
+
+	sub, err := s.SubscribeToOrders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("place order and wait: %w", err)
+	}
+	defer sub.Close()
+
+	connected, err := waitConnected(ctx, sub, connectTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("place order and wait: %w", err)
+	}
+
+	// Trades booked before the order was placed are not its fills.
+	placedOn := time.Now().Format(time.DateOnly)
+	before, err := s.trades(ctx, req, placedOn)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(before))
+	for _, t := range before {
+		seen[t.ID] = true
+	}
+
+	resp, err := s.PlaceOrder(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &orderWatch{outcome: OrderOutcome{OrderID: resp.OrderID, State: OrderOutcomeOpen, RemainingVolume: req.Volume}}
+	getReq := &GetOrderRequest{OrderID: resp.OrderID, AccountID: req.AccountID}
+	check := func() error {
+		o, err := s.GetOrder(ctx, getReq)
+		if err != nil {
+			return fmt.Errorf("place order and wait: get order: %w", err)
+		}
+		w.update(o.OriginalVolume, o.Volume, o.State == string(OrderStateDeleted))
+		return nil
+	}
+
+	// poll ticks while the stream cannot be relied on.
+	var poll <-chan time.Time
+	var ticker *time.Ticker
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+	startPolling := func() {
+		if ticker == nil {
+			ticker = time.NewTicker(interval)
+			poll = ticker.C
+		}
+	}
+	if !connected {
+		// The order may have filled before a late connect; look it up now.
+		if err := check(); err != nil {
+			return w.result(), err
+		}
+		startPolling()
+	}
+
+	events, errs := sub.Events(), sub.Errors()
+	for !w.done(opts.ReturnOnFill) {
+		select {
+		case <-ctx.Done():
+			return w.result(), fmt.Errorf("place order and wait: %w", ctx.Err())
+		case e, ok := <-events:
+			if !ok {
+				events = nil
+				startPolling()
+				continue
+			}
+			if e.Event == "ORDER" && e.Data.ID == resp.OrderID {
+				d := e.Data
+				w.update(int(d.OriginalVolume), int(d.CurrentVolume),
+					d.Action == OrderActionDeleted || d.State.Name == OrderStateDeleted)
+			}
+		case _, ok := <-errs:
+			if !ok {
+				errs = nil
+			}
+			startPolling()
+		case <-poll:
+			if err := check(); err != nil {
+				return w.result(), err
+			}
+		}
+	}
+
+	out := w.result()
+	if out.FilledVolume > 0 {
+		after, err := s.trades(ctx, req, placedOn)
+		if err != nil {
+			return out, err
+		}
+		priceFills(out, after, seen)
+	}
+	return out, nil
+}
+
+// trades returns the trades on req's account, orderbook and side booked
+// from the date from until today.
+func (s *Service) trades(ctx context.Context, req *PlaceOrderRequest, from string) ([]accounts.Transaction, error) {
+	resp, err := s.accounts.GetTransactions(ctx, &accounts.TransactionsRequest{
+		From: from,
+		To:   time.Now().Format(time.DateOnly),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("place order and wait: get transactions: %w", err)
+	}
+	var out []accounts.Transaction
+	for _, t := range resp.Transactions {
+		if t.Account.ID == req.AccountID && t.Orderbook != nil && t.Orderbook.ID == req.OrderbookID &&
+			t.Type == string(req.Side) && !t.Cancelled && t.Volume != nil && t.PriceInTradedCurrency != nil {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// priceFills sets out's FilledValue and AveragePrice from the trades not in
+// seen. They are left zero unless the trades add up to the filled volume,
+// as when Avanza has not booked every fill yet or another order filled too.
+func priceFills(out *OrderOutcome, trades []accounts.Transaction, seen map[string]bool) {
+	var volume, value decimal.Decimal
+	var places int32
+	for _, t := range trades {
+		if seen[t.ID] {
+			continue
+		}
+		v, price := t.Volume.Value.Abs(), t.PriceInTradedCurrency.Value
+		volume = volume.Add(v)
+		value = value.Add(price.Mul(v))
+		places = max(places, price.Scale())
+	}
+	if !volume.Equal(decimal.NewFromInt(int64(out.FilledVolume))) {
+		return
+	}
+	out.FilledValue = value
+	out.AveragePrice = value.Div(volume, places)
+}
+
+// waitConnected waits up to timeout for sub to connect. It reports false if
+// the stream failed or did not connect in time.
+func waitConnected(ctx context.Context, sub *OrdersSubscription, timeout time.Duration) (bool, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-sub.Connected():
+		return true, nil
+	case <-sub.Errors():
+		return false, nil
+	case <-timer.C:
+		return false, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// orderWatch folds order events and GetOrder responses, which may arrive out
+// of order, into an OrderOutcome. Filled volume only grows and a filled or
+// deleted order stays so.
+type orderWatch struct {
+	outcome OrderOutcome
+}
+
+func (w *orderWatch) update(original, remaining int, deleted bool) {
+	o := &w.outcome
+	if o.State == OrderOutcomeFilled || o.State == OrderOutcomeDeleted {
+		return
+	}
+	if filled := original - remaining; filled > o.FilledVolume {
+		o.FilledVolume = filled
+	}
+	if original > 0 {
+		o.RemainingVolume = original - o.FilledVolume
+	}
+
+	switch {
+	case o.FilledVolume > 0 && o.RemainingVolume == 0:
+		o.State = OrderOutcomeFilled
+	case deleted:
+		o.State = OrderOutcomeDeleted
+	case o.FilledVolume > 0:
+		o.State = OrderOutcomePartiallyFilled
+	}
+}
+
+func (w *orderWatch) done(returnOnFill bool) bool {
+	switch w.outcome.State {
+	case OrderOutcomeFilled, OrderOutcomeDeleted:
+		return true
+	case OrderOutcomePartiallyFilled:
+		return returnOnFill
+	}
+	return false
+}
+
+func (w *orderWatch) result() *OrderOutcome {
+	out := w.outcome
+	return &out
+}
@@ -0,0 +1,372 @@
+package trading
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vmorsell/avanza-sdk-go/accounts"
+	"github.com/vmorsell/avanza-sdk-go/client"
+	"github.com/vmorsell/avanza-sdk-go/decimal"
+)
+
+// waitServer fakes the endpoints PlaceOrderAndWait uses. placeStatus is the
+// place order status, stream serves the orders stream, and find answers the
+// n:th GetOrder call, counting from 1, or fails it if nil. before and after
+// are the account's transactions before and after the order is placed, and
+// failTrades fails the lookups after it. placed is closed once an order is
+// placed.
+type waitServer struct {
+	placeStatus OrderRequestStatus
+	stream      func(w http.ResponseWriter, r *http.Request)
+	find        func(n int) GetOrderResponse
+	finds       atomic.Int32
+	before      []accounts.Transaction
+	after       []accounts.Transaction
+	failTrades  bool
+	placed      chan struct{}
+}
+
+func newWaitService(t *testing.T, ws *waitServer) *Service {
+	t.Helper()
+	if ws.placeStatus == "" {
+		ws.placeStatus = OrderRequestStatusSuccess
+	}
+	ws.placed = make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_push/trading/orders/":
+			ws.stream(w, r)
+		case "/_api/trading-critical/rest/order/new":
+			_ = json.NewEncoder(w).Encode(PlaceOrderResponse{OrderRequestStatus: ws.placeStatus, OrderID: "1", Parameters: []string{}})
+			if ws.placeStatus == OrderRequestStatusSuccess {
+				close(ws.placed)
+			}
+		case "/_api/trading-critical/rest/order/find":
+			n := int(ws.finds.Add(1))
+			if ws.find == nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(ws.find(n))
+		case "/_api/transactions/list":
+			trades := ws.before
+			select {
+			case <-ws.placed:
+				if ws.failTrades {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				trades = ws.after
+			default:
+			}
+			_ = json.NewEncoder(w).Encode(accounts.TransactionsResponse{Transactions: trades})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c := client.NewClient(client.WithBaseURL(server.URL))
+	c.SetMockCookies(map[string]string{"csid": "a", "cstoken": "b", "AZACSRF": "c"})
+	return NewService(c)
+}
+
+// openStream answers the stream request so the subscription connects.
+func openStream(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+}
+
+func writeOrderEvent(t *testing.T, w http.ResponseWriter, data OrderEventData) {
+	t.Helper()
+	b, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeSSEEvent(w, data.ID+"_"+string(data.Action), "ORDER", string(b))
+}
+
+func activeOrder(volume int) GetOrderResponse {
+	return GetOrderResponse{OrderID: "1", State: "ACTIVE", Price: decimal.MustParse("240.00"), Volume: volume, OriginalVolume: 10}
+}
+
+// trade is a transaction for a fill on account 1111111.
+func trade(id, orderbookID string, side OrderSide, volume int64, price string) accounts.Transaction {
+	return accounts.Transaction{
+		ID:                    id,
+		Account:               accounts.TransactionAccount{ID: "1111111"},
+		Orderbook:             &accounts.TransactionOrderbook{ID: orderbookID},
+		Type:                  string(side),
+		Volume:                &accounts.Money{Value: decimal.NewFromInt(volume)},
+		PriceInTradedCurrency: &accounts.Money{Value: decimal.MustParse(price)},
+	}
+}
+
+func waitRequest() *PlaceOrderRequest {
+	return &PlaceOrderRequest{
+		AccountID:   "1111111",
+		OrderbookID: "5247",
+		Price:       decimal.MustParse("240.00"),
+		Volume:      10,
+		Side:        OrderSideBuy,
+		Condition:   OrderConditionNormal,
+	}
+}
+
+func TestPlaceOrderAndWait_FollowsStream(t *testing.T) {
+	older := trade("1", "5247", OrderSideBuy, 10, "230.00")
+	var ws *waitServer
+	ws = &waitServer{
+		find:   func(int) GetOrderResponse { return activeOrder(10) },
+		before: []accounts.Transaction{older},
+		after: []accounts.Transaction{
+			older,
+			trade("2", "5247", OrderSideBuy, 4, "239.50"),
+			trade("3", "5247", OrderSideBuy, 6, "239.00"),
+			trade("4", "5240", OrderSideBuy, 5, "90.00"),
+			trade("5", "5247", OrderSideSell, 2, "240.00"),
+		},
+		stream: func(w http.ResponseWriter, r *http.Request) {
+			openStream(w)
+			<-ws.placed
+			writeOrderEvent(t, w, OrderEventData{ID: "999", CurrentVolume: 0, OriginalVolume: 5, Action: OrderActionDeleted})
+			writeOrderEvent(t, w, OrderEventData{ID: "1", CurrentVolume: 4, OriginalVolume: 10, Action: OrderActionNew})
+			writeOrderEvent(t, w, OrderEventData{ID: "1", CurrentVolume: 0, OriginalVolume: 10, Action: OrderActionDeleted})
+			<-r.Context().Done()
+		},
+	}
+	svc := newWaitService(t, ws)
+
+	outcome, err := svc.PlaceOrderAndWait(context.Background(), waitRequest(), nil)
+	if err != nil {
+		t.Fatalf("PlaceOrderAndWait: %v", err)
+	}
+	if outcome.OrderID != "1" || outcome.State != OrderOutcomeFilled || outcome.FilledVolume != 10 || outcome.RemainingVolume != 0 {
+		t.Errorf("outcome = %+v, want order 1 filled with 10", outcome)
+	}
+	if got := ws.finds.Load(); got != 0 {
+		t.Errorf("GetOrder calls = %d, want none with a connected stream", got)
+	}
+	if outcome.FilledValue.String() != "2392.00" || outcome.AveragePrice.String() != "239.20" {
+		t.Errorf("FilledValue, AveragePrice = %s, %s, want 2392.00, 239.20", outcome.FilledValue, outcome.AveragePrice)
+	}
+}
+
+func TestPlaceOrderAndWait_FillsNotBooked(t *testing.T) {
+	var ws *waitServer
+	ws = &waitServer{
+		find:  func(int) GetOrderResponse { return activeOrder(10) },
+		after: []accounts.Transaction{trade("2", "5247", OrderSideBuy, 4, "239.50")},
+		stream: func(w http.ResponseWriter, r *http.Request) {
+			openStream(w)
+			<-ws.placed
+			writeOrderEvent(t, w, OrderEventData{ID: "1", CurrentVolume: 0, OriginalVolume: 10, Action: OrderActionDeleted})
+			<-r.Context().Done()
+		},
+	}
+	svc := newWaitService(t, ws)
+
+	outcome, err := svc.PlaceOrderAndWait(context.Background(), waitRequest(), nil)
+	if err != nil {
+		t.Fatalf("PlaceOrderAndWait: %v", err)
+	}
+	if outcome.State != OrderOutcomeFilled || !outcome.AveragePrice.IsZero() || !outcome.FilledValue.IsZero() {
+		t.Errorf("outcome = %+v, want filled with no price", outcome)
+	}
+}
+
+func TestPlaceOrderAndWait_TransactionsError(t *testing.T) {
+	var ws *waitServer
+	ws = &waitServer{
+		find:       func(int) GetOrderResponse { return activeOrder(10) },
+		failTrades: true,
+		stream: func(w http.ResponseWriter, r *http.Request) {
+			openStream(w)
+			<-ws.placed
+			writeOrderEvent(t, w, OrderEventData{ID: "1", CurrentVolume: 0, OriginalVolume: 10, Action: OrderActionDeleted})
+			<-r.Context().Done()
+		},
+	}
+	svc := newWaitService(t, ws)
+
+	outcome, err := svc.PlaceOrderAndWait(context.Background(), waitRequest(), nil)
+	var httpErr *client.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("err = %v, want the transactions *client.HTTPError", err)
+	}
+	if outcome == nil || outcome.State != OrderOutcomeFilled || outcome.FilledVolume != 10 {
+		t.Errorf("outcome = %+v, want filled with 10", outcome)
+	}
+}
+
+func TestPlaceOrderAndWait_PlacesAfterStreamConnects(t *testing.T) {
+	connected := make(chan struct{})
+	var ws *waitServer
+	ws = &waitServer{
+		find: func(int) GetOrderResponse { return activeOrder(10) },
+		stream: func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+			select {
+			case <-ws.placed:
+				t.Error("order placed before the stream connected")
+			default:
+			}
+			close(connected)
+			openStream(w)
+			<-ws.placed
+			writeOrderEvent(t, w, OrderEventData{ID: "1", CurrentVolume: 0, OriginalVolume: 10, Action: OrderActionDeleted})
+			<-r.Context().Done()
+		},
+	}
+	svc := newWaitService(t, ws)
+
+	outcome, err := svc.PlaceOrderAndWait(context.Background(), waitRequest(), nil)
+	if err != nil {
+		t.Fatalf("PlaceOrderAndWait: %v", err)
+	}
+	<-connected
+	if outcome.State != OrderOutcomeFilled {
+		t.Errorf("outcome = %+v, want filled", outcome)
+	}
+}
+
+func TestPlaceOrderAndWait_FillsBeforeLateStreamConnects(t *testing.T) {
+	// The stream never connects in time, and the order fills after the
+	// first GetOrder: polling has to find it.
+	svc := newWaitService(t, &waitServer{
+		find: func(n int) GetOrderResponse {
+			if n == 1 {
+				return activeOrder(10)
+			}
+			o := activeOrder(0)
+			o.State = string(OrderStateDeleted)
+			return o
+		},
+		stream: func(w http.ResponseWriter, r *http.Request) { <-r.Context().Done() },
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	outcome, err := svc.PlaceOrderAndWait(ctx, waitRequest(), &WaitOptions{ConnectTimeout: 20 * time.Millisecond, PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("PlaceOrderAndWait: %v", err)
+	}
+	if outcome.State != OrderOutcomeFilled || outcome.FilledVolume != 10 {
+		t.Errorf("outcome = %+v, want filled with 10", outcome)
+	}
+}
+
+func TestPlaceOrderAndWait_PollsWhenStreamFails(t *testing.T) {
+	ws := &waitServer{
+		find: func(n int) GetOrderResponse {
+			if n < 3 {
+				return activeOrder(10)
+			}
+			o := activeOrder(7)
+			o.State = string(OrderStateDeleted)
+			return o
+		},
+		stream: func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusUnauthorized) },
+	}
+	svc := newWaitService(t, ws)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	outcome, err := svc.PlaceOrderAndWait(ctx, waitRequest(), &WaitOptions{PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("PlaceOrderAndWait: %v", err)
+	}
+	if outcome.State != OrderOutcomeDeleted || outcome.FilledVolume != 3 || outcome.RemainingVolume != 7 {
+		t.Errorf("outcome = %+v, want deleted with 3 filled and 7 left", outcome)
+	}
+	if got := ws.finds.Load(); got < 3 {
+		t.Errorf("GetOrder calls = %d, want at least 3", got)
+	}
+}
+
+func TestPlaceOrderAndWait_GetOrderError(t *testing.T) {
+	svc := newWaitService(t, &waitServer{
+		stream: func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusUnauthorized) },
+	})
+
+	outcome, err := svc.PlaceOrderAndWait(context.Background(), waitRequest(), &WaitOptions{PollInterval: 10 * time.Millisecond})
+	var httpErr *client.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("err = %v, want the GetOrder *client.HTTPError", err)
+	}
+	if outcome == nil || outcome.OrderID != "1" || outcome.State != OrderOutcomeOpen {
+		t.Errorf("outcome = %+v, want order 1 still open", outcome)
+	}
+}
+
+func TestPlaceOrderAndWait_ReturnOnFill(t *testing.T) {
+	var ws *waitServer
+	ws = &waitServer{
+		find: func(int) GetOrderResponse { return activeOrder(10) },
+		stream: func(w http.ResponseWriter, r *http.Request) {
+			openStream(w)
+			<-ws.placed
+			writeOrderEvent(t, w, OrderEventData{ID: "1", CurrentVolume: 6, OriginalVolume: 10, Action: OrderActionNew})
+			<-r.Context().Done()
+		},
+	}
+	svc := newWaitService(t, ws)
+
+	outcome, err := svc.PlaceOrderAndWait(context.Background(), waitRequest(), &WaitOptions{ReturnOnFill: true})
+	if err != nil {
+		t.Fatalf("PlaceOrderAndWait: %v", err)
+	}
+	if outcome.State != OrderOutcomePartiallyFilled || outcome.FilledVolume != 4 || outcome.RemainingVolume != 6 {
+		t.Errorf("outcome = %+v, want partially filled with 4", outcome)
+	}
+}
+
+func TestPlaceOrderAndWait_ContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ws *waitServer
+	ws = &waitServer{
+		find: func(int) GetOrderResponse { return activeOrder(10) },
+		stream: func(w http.ResponseWriter, r *http.Request) {
+			openStream(w)
+			<-ws.placed
+			// Leave time for the place order response to arrive.
+			time.AfterFunc(50*time.Millisecond, cancel)
+			<-r.Context().Done()
+		},
+	}
+	svc := newWaitService(t, ws)
+
+	outcome, err := svc.PlaceOrderAndWait(ctx, waitRequest(), nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if outcome == nil || outcome.State != OrderOutcomeOpen || outcome.RemainingVolume != 10 {
+		t.Errorf("outcome = %+v, want open with 10 left", outcome)
+	}
+}
+
+func TestPlaceOrderAndWait_Rejected(t *testing.T) {
+	svc := newWaitService(t, &waitServer{
+		placeStatus: OrderRequestStatusError,
+		find:        func(int) GetOrderResponse { return activeOrder(10) },
+		stream: func(w http.ResponseWriter, r *http.Request) {
+			openStream(w)
+			<-r.Context().Done()
+		},
+	})
+
+	_, err := svc.PlaceOrderAndWait(context.Background(), waitRequest(), nil)
+	var rejected *OrderRejectedError
+	if !errors.As(err, &rejected) {
+		t.Errorf("err = %v, want *OrderRejectedError", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/vmorsell/avanza-sdk-go/decimal"
 	"github.com/vmorsell/avanza-sdk-go/trading"
 )
 
@@ -41,7 +42,7 @@ func TestValidateOrder_Success(t *testing.T) {
 			t.Errorf("req.OrderbookID = %v, want %v", got, want)
 		}
 
-		if got, want := req.Price, testPrice; got != want {
+		if got, want := req.Price, decimal.NewFromFloat(testPrice); !got.Equal(want) {
 			t.Errorf("req.Price = %v, want %v", got, want)
 		}
 
@@ -82,8 +83,7 @@ func TestValidateOrder_Success(t *testing.T) {
 	req := &trading.ValidateOrderRequest{
 		IsDividendReinvestment: false,
 		RequestID:              nil,
-		OrderRequestParameters: nil,
-		Price:                  testPrice,
+		Price:                  decimal.NewFromFloat(testPrice),
 		Volume:                 testVolume,
 		OpenVolume:             nil,
 		AccountID:              testAccountID,
@@ -144,7 +144,7 @@ func TestValidateOrder_HTTPError(t *testing.T) {
 	avanza := New(WithBaseURL(server.URL))
 
 	req := &trading.ValidateOrderRequest{
-		Price:       testPrice,
+		Price:       decimal.NewFromFloat(testPrice),
 		Volume:      testVolume,
 		AccountID:   testAccountID,
 		Side:        trading.OrderSideBuy,
@@ -181,7 +181,7 @@ func TestValidateOrder_ContextCancellation(t *testing.T) {
 	cancel() // Cancel immediately
 
 	req := &trading.ValidateOrderRequest{
-		Price:       testPrice,
+		Price:       decimal.NewFromFloat(testPrice),
 		Volume:      testVolume,
 		AccountID:   testAccountID,
 		Side:        trading.OrderSideBuy,